@@ -0,0 +1,235 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"anomaly_detector/models"
+)
+
+const (
+	// MaxBodySize bounds the memory used by the body of a single captured request
+	MaxBodySize = 10 * 1024 * 1024
+
+	cContentTypeJSON      = "application/json"
+	cContentTypeForm      = "application/x-www-form-urlencoded"
+	cContentTypeMultipart = "multipart/form-data"
+
+	cHeaderCookie = "Cookie"
+	cHeaderHost   = "Host"
+)
+
+// ParseRaw reads an HTTP/1.1 request as sent on the wire: the request line, the headers and the body,
+// whose length is given by its Content-Length or chunked Transfer-Encoding
+func ParseRaw(reader io.Reader) (*models.Request, error) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP request: %w", err)
+	}
+
+	return FromHTTPRequest(req)
+}
+
+// FromHTTPRequest converts an HTTP request into the request format of the validator, consuming its body.
+// Query params and form fields keep their order and repetitions, headers are sorted by name, cookies are
+// taken out of the Cookie header, and the body is decoded according to its Content-Type.
+func FromHTTPRequest(r *http.Request) (*models.Request, error) {
+	query, err := parsePairs(r.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string: %w", err)
+	}
+
+	body, err := parseBody(r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies := []*models.RequestParam{}
+	for _, cookie := range r.Cookies() {
+		cookies = append(cookies, &models.RequestParam{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return &models.Request{
+		Path:        r.URL.Path,
+		Method:      r.Method,
+		QueryParams: query,
+		Headers:     headerParams(r),
+		Cookies:     cookies,
+		Body:        body,
+	}, nil
+}
+
+// headerParams lists every header value, except the cookies which are a section of their own.
+// The Host header is restored, as Go moves it out of the headers.
+func headerParams(r *http.Request) []*models.RequestParam {
+	names := make([]string, 0, len(r.Header)+1)
+	for name := range r.Header {
+		if name != cHeaderCookie {
+			names = append(names, name)
+		}
+	}
+
+	if _, exists := r.Header[cHeaderHost]; !exists && r.Host != "" {
+		names = append(names, cHeaderHost)
+	}
+
+	slices.Sort(names)
+
+	headers := make([]*models.RequestParam, 0, len(names))
+
+	for _, name := range names {
+		if values, exists := r.Header[name]; exists {
+			for _, value := range values {
+				headers = append(headers, &models.RequestParam{Name: name, Value: value})
+			}
+
+			continue
+		}
+
+		headers = append(headers, &models.RequestParam{Name: name, Value: r.Host})
+	}
+
+	return headers
+}
+
+// parseBody decodes the fields of a JSON object, form-urlencoded or multipart body. Values of forms are
+// strings, file parts are represented by their file name.
+func parseBody(contentType string, body io.Reader) ([]*models.RequestParam, error) {
+	if body == nil || body == http.NoBody {
+		return []*models.RequestParam{}, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if len(data) > MaxBodySize {
+		return nil, fmt.Errorf("body exceeds %d bytes", MaxBodySize)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []*models.RequestParam{}, nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type %q of a request with a body", contentType)
+	}
+
+	switch {
+	case mediaType == cContentTypeJSON || strings.HasSuffix(mediaType, "+json"):
+		return parseJSON(data)
+	case mediaType == cContentTypeForm:
+		fields, err := parsePairs(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid form body: %w", err)
+		}
+
+		return fields, nil
+	case mediaType == cContentTypeMultipart:
+		return parseMultipart(data, params["boundary"])
+	default:
+		return nil, fmt.Errorf("unsupported Content-Type %q", mediaType)
+	}
+}
+
+// parseJSON decodes the fields of a JSON object body, sorted by name. Numbers are kept as json.Number.
+func parseJSON(data []byte) ([]*models.RequestParam, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil || object == nil || decoder.More() {
+		return nil, errors.New("invalid JSON body: expected an object")
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	fields := make([]*models.RequestParam, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, &models.RequestParam{Name: name, Value: object[name]})
+	}
+
+	return fields, nil
+}
+
+// parseMultipart decodes the parts of a multipart/form-data body in order
+func parseMultipart(data []byte, boundary string) ([]*models.RequestParam, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart body without a boundary")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(data), boundary)
+	fields := []*models.RequestParam{}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return fields, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		value := part.FileName()
+		if value == "" {
+			content, err := io.ReadAll(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart body: %w", err)
+			}
+
+			value = string(content)
+		}
+
+		fields = append(fields, &models.RequestParam{Name: name, Value: value})
+	}
+}
+
+// parsePairs decodes a query string or form-urlencoded body, keeping the order and the repetitions of names
+func parsePairs(raw string) ([]*models.RequestParam, error) {
+	pairs := []*models.RequestParam{}
+
+	for pair := range strings.SplitSeq(raw, "&") {
+		if pair == "" {
+			continue
+		}
+
+		rawName, rawValue, _ := strings.Cut(pair, "=")
+
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, &models.RequestParam{Name: name, Value: value})
+	}
+
+	return pairs, nil
+}
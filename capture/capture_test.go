@@ -0,0 +1,156 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRaw(t *testing.T) {
+	t.Run("query, headers and cookies", func(t *testing.T) {
+		raw := "GET /users/42?tag=a&limit=10&tag=b%20c HTTP/1.1\r\n" +
+			"Host: api.example.com\r\n" +
+			"x-request-id: 1\r\n" +
+			"Accept: a\r\n" +
+			"Accept: b\r\n" +
+			"Cookie: session=abc; theme=dark\r\n" +
+			"\r\n"
+
+		req, err := ParseRaw(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, &models.Request{
+			Path:   "/users/42",
+			Method: http.MethodGet,
+			QueryParams: []*models.RequestParam{
+				{Name: "tag", Value: "a"},
+				{Name: "limit", Value: "10"},
+				{Name: "tag", Value: "b c"},
+			},
+			Headers: []*models.RequestParam{
+				{Name: "Accept", Value: "a"},
+				{Name: "Accept", Value: "b"},
+				{Name: "Host", Value: "api.example.com"},
+				{Name: "X-Request-Id", Value: "1"},
+			},
+			Cookies: []*models.RequestParam{
+				{Name: "session", Value: "abc"},
+				{Name: "theme", Value: "dark"},
+			},
+			Body: []*models.RequestParam{},
+		}, req)
+	})
+
+	t.Run("JSON body", func(t *testing.T) {
+		body := `{"name": "John", "age": 30, "tags": ["a"]}`
+		raw := "POST /users HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/json; charset=utf-8\r\n" +
+			"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+
+		req, err := ParseRaw(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{
+			{Name: "age", Value: json.Number("30")},
+			{Name: "name", Value: "John"},
+			{Name: "tags", Value: []any{"a"}},
+		}, req.Body)
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		body := "name=John+Doe&role=admin&role=user"
+		raw := "POST /users HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/x-www-form-urlencoded\r\n" +
+			"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+
+		req, err := ParseRaw(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{
+			{Name: "name", Value: "John Doe"},
+			{Name: "role", Value: "admin"},
+			{Name: "role", Value: "user"},
+		}, req.Body)
+	})
+
+	t.Run("multipart body", func(t *testing.T) {
+		body := "--XYZ\r\n" +
+			"Content-Disposition: form-data; name=\"title\"\r\n\r\n" +
+			"Report\r\n" +
+			"--XYZ\r\n" +
+			"Content-Disposition: form-data; name=\"file\"; filename=\"report.pdf\"\r\n" +
+			"Content-Type: application/pdf\r\n\r\n" +
+			"%PDF-1.4\r\n" +
+			"--XYZ--\r\n"
+		raw := "POST /upload HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: multipart/form-data; boundary=XYZ\r\n" +
+			"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+
+		req, err := ParseRaw(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{
+			{Name: "title", Value: "Report"},
+			{Name: "file", Value: "report.pdf"},
+		}, req.Body)
+	})
+
+	t.Run("chunked body", func(t *testing.T) {
+		raw := "POST /users HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/json\r\n" +
+			"Transfer-Encoding: chunked\r\n\r\n" +
+			"7\r\n{\"a\":1}\r\n0\r\n\r\n"
+
+		req, err := ParseRaw(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{{Name: "a", Value: json.Number("1")}}, req.Body)
+	})
+
+	errorTests := []struct {
+		name string
+		raw  string
+		err  string
+	}{
+		{
+			name: "not an HTTP request",
+			raw:  "hello",
+			err:  "invalid HTTP request",
+		},
+		{
+			name: "invalid query string",
+			raw:  "GET /users?name=%zz HTTP/1.1\r\nHost: a\r\n\r\n",
+			err:  "invalid query string",
+		},
+		{
+			name: "JSON body that is not an object",
+			raw:  "POST /users HTTP/1.1\r\nHost: a\r\nContent-Type: application/json\r\nContent-Length: 2\r\n\r\n[]",
+			err:  "expected an object",
+		},
+		{
+			name: "body without a Content-Type",
+			raw:  "POST /users HTTP/1.1\r\nHost: a\r\nContent-Length: 2\r\n\r\n{}",
+			err:  "invalid Content-Type",
+		},
+		{
+			name: "unsupported Content-Type",
+			raw:  "POST /users HTTP/1.1\r\nHost: a\r\nContent-Type: text/plain\r\nContent-Length: 2\r\n\r\nhi",
+			err:  `unsupported Content-Type "text/plain"`,
+		},
+		{
+			name: "multipart body without a boundary",
+			raw:  "POST /users HTTP/1.1\r\nHost: a\r\nContent-Type: multipart/form-data\r\nContent-Length: 2\r\n\r\nhi",
+			err:  "without a boundary",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRaw(strings.NewReader(tt.raw))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
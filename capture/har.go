@@ -0,0 +1,115 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"anomaly_detector/models"
+)
+
+// HAREntry is a request recorded in an HTTP Archive (HAR 1.2), as exported by browsers and proxies
+type HAREntry struct {
+	Request *harRequest `json:"request"`
+}
+
+type harRequest struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Headers  []*harNameValue `json:"headers"`
+	Cookies  []*harNameValue `json:"cookies"`
+	PostData *harPostData    `json:"postData"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text"`
+	Params   []*harNameValue `json:"params"`
+}
+
+// ParseHAR returns the entries of a HAR document, either a whole log or a single entry. The entries are
+// converted one at a time with ToRequest, so that a malformed entry does not fail the others.
+func ParseHAR(data []byte) ([]*HAREntry, error) {
+	var document struct {
+		Log *struct {
+			Entries []*HAREntry `json:"entries"`
+		} `json:"log"`
+		Request *harRequest `json:"request"`
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid HAR document: %w", err)
+	}
+
+	switch {
+	case document.Log != nil:
+		return document.Log.Entries, nil
+	case document.Request != nil:
+		return []*HAREntry{{Request: document.Request}}, nil
+	default:
+		return nil, errors.New("invalid HAR document: expected a log or an entry")
+	}
+}
+
+// ToRequest converts a HAR entry into the request format of the validator, parsing it as FromHTTPRequest does.
+// HTTP/2 pseudo-headers such as :authority are skipped, and cookies are only taken from the cookies list
+// when no Cookie header was recorded.
+func (e *HAREntry) ToRequest() (*models.Request, error) {
+	if e == nil || e.Request == nil {
+		return nil, errors.New("HAR entry without a request")
+	}
+
+	harReq := e.Request
+
+	var (
+		body        string
+		contentType string
+	)
+
+	if harReq.PostData != nil {
+		body, contentType = harReq.PostData.Text, harReq.PostData.MimeType
+
+		// Form bodies may only be recorded as params
+		if body == "" && len(harReq.PostData.Params) > 0 {
+			pairs := make([]string, 0, len(harReq.PostData.Params))
+			for _, param := range harReq.PostData.Params {
+				pairs = append(pairs, url.QueryEscape(param.Name)+"="+url.QueryEscape(param.Value))
+			}
+
+			body, contentType = strings.Join(pairs, "&"), cContentTypeForm
+		}
+	}
+
+	req, err := http.NewRequest(harReq.Method, harReq.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HAR request: %w", err)
+	}
+
+	for _, header := range harReq.Headers {
+		if header != nil && !strings.HasPrefix(header.Name, ":") {
+			req.Header.Add(header.Name, header.Value)
+		}
+	}
+
+	if req.Header.Get(cHeaderCookie) == "" {
+		for _, cookie := range harReq.Cookies {
+			if cookie != nil {
+				req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+			}
+		}
+	}
+
+	if req.Header.Get("Content-Type") == "" && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return FromHTTPRequest(req)
+}
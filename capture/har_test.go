@@ -0,0 +1,116 @@
+package capture
+
+import (
+	"net/http"
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHAR(t *testing.T) {
+	t.Run("log", func(t *testing.T) {
+		entries, err := ParseHAR([]byte(`{"log": {"version": "1.2", "entries": [
+			{"request": {"method": "GET", "url": "https://api.example.com/a"}},
+			{"request": {"method": "POST", "url": "https://api.example.com/b"}}
+		]}}`))
+		require.NoError(t, err)
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "https://api.example.com/a", entries[0].Request.URL)
+		assert.Equal(t, http.MethodPost, entries[1].Request.Method)
+	})
+
+	t.Run("single entry", func(t *testing.T) {
+		entries, err := ParseHAR([]byte(`{"request": {"method": "GET", "url": "https://api.example.com/a"}}`))
+		require.NoError(t, err)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, http.MethodGet, entries[0].Request.Method)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := ParseHAR([]byte(`{"log":`))
+		assert.ErrorContains(t, err, "invalid HAR document")
+	})
+
+	t.Run("neither a log nor an entry", func(t *testing.T) {
+		_, err := ParseHAR([]byte(`{"entries": []}`))
+		assert.ErrorContains(t, err, "expected a log or an entry")
+	})
+}
+
+func TestHAREntryToRequest(t *testing.T) {
+	t.Run("headers, cookies and JSON body", func(t *testing.T) {
+		entry := &HAREntry{Request: &harRequest{
+			Method: http.MethodPost,
+			URL:    "https://api.example.com/users?role=admin",
+			Headers: []*harNameValue{
+				{Name: ":authority", Value: "api.example.com"},
+				{Name: "content-type", Value: "application/json"},
+			},
+			Cookies:  []*harNameValue{{Name: "session", Value: "abc"}},
+			PostData: &harPostData{MimeType: "application/json", Text: `{"name": "John"}`},
+		}}
+
+		req, err := entry.ToRequest()
+		require.NoError(t, err)
+
+		assert.Equal(t, &models.Request{
+			Path:        "/users",
+			Method:      http.MethodPost,
+			QueryParams: []*models.RequestParam{{Name: "role", Value: "admin"}},
+			Headers: []*models.RequestParam{
+				{Name: "Content-Type", Value: "application/json"},
+				{Name: "Host", Value: "api.example.com"},
+			},
+			Cookies: []*models.RequestParam{{Name: "session", Value: "abc"}},
+			Body:    []*models.RequestParam{{Name: "name", Value: "John"}},
+		}, req)
+	})
+
+	t.Run("the Cookie header takes precedence over the cookies list", func(t *testing.T) {
+		entry := &HAREntry{Request: &harRequest{
+			Method:  http.MethodGet,
+			URL:     "https://api.example.com/users",
+			Headers: []*harNameValue{{Name: "Cookie", Value: "session=abc"}},
+			Cookies: []*harNameValue{{Name: "session", Value: "abc"}},
+		}}
+
+		req, err := entry.ToRequest()
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{{Name: "session", Value: "abc"}}, req.Cookies)
+	})
+
+	t.Run("form recorded as params", func(t *testing.T) {
+		entry := &HAREntry{Request: &harRequest{
+			Method: http.MethodPost,
+			URL:    "https://api.example.com/login",
+			PostData: &harPostData{
+				MimeType: "application/x-www-form-urlencoded",
+				Params:   []*harNameValue{{Name: "user", Value: "john doe"}, {Name: "remember", Value: "on"}},
+			},
+		}}
+
+		req, err := entry.ToRequest()
+		require.NoError(t, err)
+
+		assert.Equal(t, []*models.RequestParam{
+			{Name: "user", Value: "john doe"},
+			{Name: "remember", Value: "on"},
+		}, req.Body)
+	})
+
+	t.Run("entry without a request", func(t *testing.T) {
+		_, err := (&HAREntry{}).ToRequest()
+		assert.ErrorContains(t, err, "without a request")
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		_, err := (&HAREntry{Request: &harRequest{Method: http.MethodGet, URL: "::"}}).ToRequest()
+		assert.ErrorContains(t, err, "invalid HAR request")
+	})
+}
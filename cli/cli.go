@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Exit codes of the command line interface
+const (
+	ExitOK        = 0
+	ExitAnomalies = 1
+	ExitUsage     = 2
+	ExitFailure   = 3
+)
+
+const cUsage = `Usage: anomaly-detector <command> [flags]
+
+Without a command the HTTP servers are started.
+
+Commands:
+  validate          Validate a request capture against a model file
+  import-openapi    Translate an OpenAPI 3 document into a model file
+  learn             Infer models from a request capture
+`
+
+// command runs a subcommand with its arguments and returns the process exit code
+type command func(args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"validate":       runValidate,
+	"import-openapi": runImportOpenAPI,
+	"learn":          runLearn,
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, cUsage)
+		return ExitUsage
+	}
+
+	cmd, exists := commands[args[0]]
+	if !exists {
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], cUsage)
+		return ExitUsage
+	}
+
+	// Keep stdout for the command output and only surface warnings from the reused components
+	slog.SetDefault(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	return cmd(args[1:], stdout, stderr)
+}
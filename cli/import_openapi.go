@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"anomaly_detector/openapi"
+	"anomaly_detector/store"
+)
+
+// runImportOpenAPI translates an OpenAPI 3 document into a model file usable by POST /models and validate --models.
+// Untranslated constructs are listed on stderr.
+func runImportOpenAPI(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("import-openapi", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	specPath := flags.String("spec", "openapi.yaml", "path to an OpenAPI 3 document in JSON or YAML")
+	outputPath := flags.String("output", "", "path of the model file to write, stdout when empty")
+
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	data, err := os.ReadFile(filepath.Clean(*specPath))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to read OpenAPI document: %v\n", err)
+		return ExitFailure
+	}
+
+	doc, err := openapi.Parse(data)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	result := openapi.Convert(doc)
+
+	for _, warning := range result.Warnings {
+		_, _ = fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+
+	// Run the same checks as POST /models, so that the written file is known to be accepted
+	if _, err := store.NewModelStore().StoreAll(context.Background(), result.Models); err != nil {
+		_, _ = fmt.Fprintf(stderr, "imported models are invalid: %v\n", err)
+		return ExitFailure
+	}
+
+	return writeJSONOutput(stdout, stderr, *outputPath, result.Models)
+}
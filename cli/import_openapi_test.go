@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tSpecYAML = `
+openapi: 3.0.3
+paths:
+  /users/{user_id}:
+    get:
+      parameters:
+        - name: user_id
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: verbose
+          in: query
+          required: true
+          schema:
+            type: boolean
+        - name: session
+          in: cookie
+          schema:
+            type: string
+            format: password
+`
+
+func TestRunImportOpenAPI(t *testing.T) {
+	specPath := writeTempFile(t, "openapi.yaml", tSpecYAML)
+
+	t.Run("written models are usable by validate", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		modelsPath := filepath.Join(t.TempDir(), "models.json")
+
+		code := Run([]string{"import-openapi", "--spec", specPath, "--output", modelsPath}, &stdout, &stderr)
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stderr.String(), `warning: GET /users/{user_id} cookie session: format "password"`)
+
+		requestsPath := writeTempFile(t, "requests.jsonl",
+			`{"path": "/users/1", "method": "GET", "query_params": [{"name": "verbose", "value": true}]}`+"\n")
+
+		stdout.Reset()
+
+		code = Run([]string{"validate", "--models", modelsPath, "--requests", requestsPath}, &stdout, &stderr)
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stdout.String(), "#0 GET /users/1: valid")
+	})
+
+	t.Run("models are written to stdout by default", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"import-openapi", "--spec", specPath}, &stdout, &stderr)
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stdout.String(), `"path": "/users/{user_id}"`)
+	})
+
+	t.Run("fail on invalid document", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		badPath := filepath.Join(t.TempDir(), "openapi.json")
+		require.NoError(t, os.WriteFile(badPath, []byte(`{"swagger": "2.0"}`), 0o600))
+
+		code := Run([]string{"import-openapi", "--spec", badPath}, &stdout, &stderr)
+		assert.Equal(t, ExitFailure, code)
+	})
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"anomaly_detector/config"
+	"anomaly_detector/learner"
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/validator"
+)
+
+// runLearn infers models from a request capture, like POST /learn followed by GET /learn/proposals.
+// It writes the models of the endpoints seen at least --min-samples times, or their full proposals with --proposals.
+func runLearn(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("learn", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	requestsPath := flags.String("requests", "requests.jsonl", "path to a JSON array or NDJSON file of requests")
+	minSamples := flags.Int("min-samples", 20, "number of requests an endpoint needs before a model is proposed")
+	outputPath := flags.String("output", "", "path of the file to write, stdout when empty")
+	withInsights := flags.Bool("proposals", false, "write the proposals with their field insights instead of models")
+
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if *minSamples < 1 {
+		_, _ = fmt.Fprintln(stderr, "min-samples must be a positive integer")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	// A capture is bounded, so every endpoint is tracked, and without models every path is its own endpoint
+	trafficLearner := learner.NewLearner(&config.InitConfig{}, nil)
+
+	observed, invalid, err := learnCapture(ctx, trafficLearner, *requestsPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	proposals := trafficLearner.Proposals(ctx, *minSamples)
+	apiModels := make([]*models.APIModel, 0, len(proposals))
+
+	for _, proposal := range proposals {
+		apiModels = append(apiModels, proposal.Model)
+	}
+
+	_, _ = fmt.Fprintf(stderr, "%d requests learned, %d invalid, %d models proposed\n", observed, invalid, len(apiModels))
+
+	// Run the same checks as POST /models, so that the written models are known to be accepted
+	if _, err := store.NewModelStore().StoreAll(ctx, apiModels); err != nil {
+		_, _ = fmt.Fprintf(stderr, "learned models are invalid: %v\n", err)
+		return ExitFailure
+	}
+
+	var output any = apiModels
+	if *withInsights {
+		output = proposals
+	}
+
+	return writeJSONOutput(stdout, stderr, *outputPath, output)
+}
+
+// learnCapture feeds every request of a capture to the learner and counts the observed and invalid ones
+func learnCapture(ctx context.Context, trafficLearner learner.ILearner, path string) (int, int, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read requests: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var observed, invalid int
+
+	err = validator.ScanRequests(file, func(item []byte) {
+		var req models.Request
+		if err := json.Unmarshal(item, &req); err != nil || req.Path == "" || req.Method == "" {
+			invalid++
+			return
+		}
+
+		trafficLearner.Observe(ctx, &req)
+		observed++
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read requests: %w", err)
+	}
+
+	return observed, invalid, nil
+}
+
+// writeJSONOutput writes indented JSON to path, or to stdout when path is empty
+func writeJSONOutput(stdout, stderr io.Writer, path string, value any) int {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to encode output: %v\n", err)
+		return ExitFailure
+	}
+
+	encoded = append(encoded, '\n')
+
+	if path == "" {
+		_, err = stdout.Write(encoded)
+	} else {
+		err = os.WriteFile(filepath.Clean(path), encoded, 0o600)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return ExitFailure
+	}
+
+	return ExitOK
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tLearnRequestsJSONL = `{"path": "/users", "method": "GET", "query_params": [{"name": "id", "value": 1}]}
+{"path": "/users", "method": "GET", "query_params": [{"name": "id", "value": 2}]}
+{"path": "/orders", "method": "GET"}
+not json
+`
+
+func TestRunLearn(t *testing.T) {
+	requestsPath := writeTempFile(t, "requests.jsonl", tLearnRequestsJSONL)
+
+	t.Run("write models of endpoints over the threshold", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"learn", "--requests", requestsPath, "--min-samples", "2"}, &stdout, &stderr)
+		assert.Equal(t, ExitOK, code)
+		assert.Equal(t, "3 requests learned, 1 invalid, 1 models proposed\n", stderr.String())
+
+		var apiModels []*models.APIModel
+
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &apiModels))
+		require.Len(t, apiModels, 1)
+		assert.Equal(t, "/users", apiModels[0].Path)
+		assert.Equal(t, []models.ParamType{models.TypeInt}, apiModels[0].QueryParams[0].Types)
+	})
+
+	t.Run("write proposals with insights", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"learn", "--requests", requestsPath, "--min-samples", "1", "--proposals"}, &stdout, &stderr)
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stdout.String(), `"confidence": 1`)
+	})
+
+	t.Run("fail on invalid threshold", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"learn", "--requests", requestsPath, "--min-samples", "0"}, &stdout, &stderr)
+		assert.Equal(t, ExitUsage, code)
+	})
+}
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/validator"
+)
+
+const (
+	cFormatText = "text"
+	cFormatJSON = "json"
+
+	cReasonNoModel     = "no model found"
+	cReasonInvalidJSON = "invalid JSON provided"
+	cFieldRequest      = "request"
+
+	// Codes of the summary rows of requests that could not be validated, next to the anomaly codes
+	cCodeNoModel     = "NO_MODEL"
+	cCodeInvalidJSON = "INVALID_JSON"
+)
+
+// requestResult is the outcome of validating one captured request
+type requestResult struct {
+	Index  int    `json:"index"`
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Model is the path template of the model the request matched, e.g. /users/{id}
+	Model  string                   `json:"model,omitempty"`
+	Result *models.ValidationResult `json:"result,omitempty"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+// endpoint names the method and path of the request, or a dash when the request could not be decoded
+func (r *requestResult) endpoint() string {
+	if r.Method == "" && r.Path == "" {
+		return "-"
+	}
+
+	return r.Method + " " + r.Path
+}
+
+// summaryEndpoint names the method and the model path of the request, so that the requests of a path template
+// are summarized together, or its endpoint when it matched no model
+func (r *requestResult) summaryEndpoint() string {
+	if r.Model == "" {
+		return r.endpoint()
+	}
+
+	return r.Method + " " + r.Model
+}
+
+// summaryRow counts the anomalies of one endpoint, field and code
+type summaryRow struct {
+	Endpoint string `json:"endpoint"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Count    int    `json:"count"`
+}
+
+type validateSummary struct {
+	Requests  int           `json:"requests"`
+	Valid     int           `json:"valid"`
+	Invalid   int           `json:"invalid"`
+	Errors    int           `json:"errors"`
+	Anomalies int           `json:"anomalies"`
+	Rows      []*summaryRow `json:"by_endpoint"`
+}
+
+type validateReport struct {
+	Results []*requestResult `json:"results"`
+	Summary *validateSummary `json:"summary"`
+}
+
+// runValidate validates every request of a capture against a model file, without starting the servers.
+// It exits with ExitAnomalies when the number of anomalies (including requests that could not be validated)
+// exceeds --max-anomalies.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	modelsPath := flags.String("models", "models.json", "path to a JSON array of API models")
+	requestsPath := flags.String("requests", "requests.jsonl", "path to a JSON array or NDJSON file of requests")
+	format := flags.String("format", cFormatText, "output format: text or json")
+	maxAnomalies := flags.Int("max-anomalies", 0, "number of anomalies tolerated before exiting with a failure")
+
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if *format != cFormatText && *format != cFormatJSON {
+		_, _ = fmt.Fprintf(stderr, "invalid format %q, expected text or json\n", *format)
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+
+	modelStore, err := loadModels(ctx, *modelsPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	report, err := validateCapture(ctx, modelStore, validator.NewRequestValidator(), *requestsPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	if err := writeReport(stdout, report, *format); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	if report.Summary.Anomalies+report.Summary.Errors > *maxAnomalies {
+		return ExitAnomalies
+	}
+
+	return ExitOK
+}
+
+// loadModels reads a model file into a new in-memory store, with the same checks as POST /models
+func loadModels(ctx context.Context, path string) (store.IModelStore, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models: %w", err)
+	}
+
+	var apiModels []*models.APIModel
+	if err := json.Unmarshal(data, &apiModels); err != nil {
+		return nil, fmt.Errorf("invalid models file %s: %w", path, err)
+	}
+
+	modelStore := store.NewModelStore()
+	if _, err := modelStore.StoreAll(ctx, apiModels); err != nil {
+		return nil, fmt.Errorf("invalid models file %s: %w", path, err)
+	}
+
+	return modelStore, nil
+}
+
+func validateCapture(
+	ctx context.Context, modelStore store.IModelStore, requestValidator validator.IRequestValidator, path string,
+) (*validateReport, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read requests: %w", err)
+	}
+	defer file.Close()
+
+	report := &validateReport{Results: []*requestResult{}}
+	counts := make(map[summaryRow]int)
+
+	scanErr := validator.ScanRequests(file, func(item []byte) {
+		result := validateItem(ctx, modelStore, requestValidator, len(report.Results), item)
+		report.Results = append(report.Results, result)
+
+		countResult(result, counts)
+	})
+	if scanErr != nil {
+		return nil, fmt.Errorf("failed to read requests %s: %w", path, scanErr)
+	}
+
+	report.Summary = summarize(report.Results, counts)
+
+	return report, nil
+}
+
+func validateItem(
+	ctx context.Context, modelStore store.IModelStore, requestValidator validator.IRequestValidator,
+	index int, item []byte,
+) *requestResult {
+	var req models.Request
+	if err := json.Unmarshal(item, &req); err != nil {
+		return &requestResult{Index: index, Error: cReasonInvalidJSON}
+	}
+
+	result := &requestResult{Index: index, Method: req.Method, Path: req.Path}
+
+	model, err := modelStore.Match(ctx, req.Path, req.Method)
+	if err != nil {
+		result.Error = cReasonNoModel
+		return result
+	}
+
+	result.Model = model.Path
+	result.Result = requestValidator.Validate(ctx, &req, model)
+
+	return result
+}
+
+// countResult adds the anomalies of a result to the per endpoint and code counts. Codes rather than reasons
+// are counted, as reasons may hold the offending value.
+func countResult(result *requestResult, counts map[summaryRow]int) {
+	endpoint := result.summaryEndpoint()
+
+	if result.Error != "" {
+		code := cCodeNoModel
+		if result.Error == cReasonInvalidJSON {
+			code = cCodeInvalidJSON
+		}
+
+		counts[summaryRow{Endpoint: endpoint, Field: cFieldRequest, Code: code}]++
+
+		return
+	}
+
+	for _, anomaly := range result.Result.Anomalies {
+		counts[summaryRow{Endpoint: endpoint, Field: anomaly.Field, Code: string(anomaly.Code)}]++
+	}
+}
+
+func summarize(results []*requestResult, counts map[summaryRow]int) *validateSummary {
+	summary := &validateSummary{Requests: len(results), Rows: make([]*summaryRow, 0, len(counts))}
+
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			summary.Errors++
+		case result.Result.Valid:
+			summary.Valid++
+		default:
+			summary.Invalid++
+		}
+
+		if result.Result != nil {
+			summary.Anomalies += len(result.Result.Anomalies)
+		}
+	}
+
+	for row, count := range counts {
+		summary.Rows = append(summary.Rows, &summaryRow{
+			Endpoint: row.Endpoint, Field: row.Field, Code: row.Code, Count: count,
+		})
+	}
+
+	// Most frequent anomalies first, then a stable order for equal counts
+	sort.Slice(summary.Rows, func(i, j int) bool {
+		a, b := summary.Rows[i], summary.Rows[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+
+		return a.Code < b.Code
+	})
+
+	return summary
+}
+
+func writeReport(w io.Writer, report *validateReport, format string) error {
+	if format == cFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	}
+
+	return writeTextReport(w, report)
+}
+
+func writeTextReport(w io.Writer, report *validateReport) error {
+	for _, result := range report.Results {
+		switch {
+		case result.Error != "":
+			_, _ = fmt.Fprintf(w, "#%d %s: error: %s\n", result.Index, result.endpoint(), result.Error)
+		case result.Result.Valid:
+			_, _ = fmt.Fprintf(w, "#%d %s: valid\n", result.Index, result.endpoint())
+		default:
+			_, _ = fmt.Fprintf(w, "#%d %s: %d anomalies\n",
+				result.Index, result.endpoint(), len(result.Result.Anomalies))
+		}
+
+		if result.Result != nil {
+			for _, anomaly := range result.Result.Anomalies {
+				_, _ = fmt.Fprintf(w, "    %s.%s: %s\n", anomaly.Field, anomaly.ParameterName, anomaly.Reason)
+			}
+		}
+	}
+
+	summary := report.Summary
+
+	_, _ = fmt.Fprintf(w, "\n%d requests: %d valid, %d invalid, %d errors, %d anomalies\n",
+		summary.Requests, summary.Valid, summary.Invalid, summary.Errors, summary.Anomalies)
+
+	if len(summary.Rows) == 0 {
+		return nil
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "\nCOUNT\tENDPOINT\tFIELD\tCODE")
+
+	for _, row := range summary.Rows {
+		_, _ = fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", row.Count, row.Endpoint, row.Field, row.Code)
+	}
+
+	return table.Flush()
+}
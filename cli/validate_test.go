@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tModelsJSON = `[{
+		"path": "/users/{user_id}",
+		"method": "GET",
+		"path_params": [{"name": "user_id", "types": ["Int"], "required": true}],
+		"query_params": [{"name": "verbose", "types": ["Boolean"], "required": true}]
+	}]`
+
+	tRequestsJSONL = `{"path": "/users/1", "method": "GET", "query_params": [{"name": "verbose", "value": true}]}
+{"path": "/users/abc", "method": "GET"}
+not json
+{"path": "/orders", "method": "GET"}
+{"path": "/users/xyz", "method": "GET"}
+`
+)
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestRunValidate(t *testing.T) {
+	modelsPath := writeTempFile(t, "models.json", tModelsJSON)
+	requestsPath := writeTempFile(t, "requests.jsonl", tRequestsJSONL)
+
+	t.Run("text report and anomalies exit code", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"validate", "--models", modelsPath, "--requests", requestsPath}, &stdout, &stderr)
+
+		assert.Equal(t, ExitAnomalies, code)
+		assert.Contains(t, stdout.String(), "#0 GET /users/1: valid")
+		assert.Contains(t, stdout.String(), "#1 GET /users/abc: 2 anomalies")
+		assert.Contains(t, stdout.String(), "#2 -: error: invalid JSON provided")
+		assert.Contains(t, stdout.String(), "#3 GET /orders: error: no model found")
+		assert.Contains(t, stdout.String(), "5 requests: 1 valid, 2 invalid, 2 errors, 4 anomalies")
+		assert.Contains(t, stdout.String(), "COUNT  ENDPOINT")
+		assert.Contains(t, stdout.String(), "2      GET /users/{user_id}  path_params   TYPE_MISMATCH")
+	})
+
+	t.Run("json report", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{
+			"validate", "--models", modelsPath, "--requests", requestsPath, "--format", "json", "--max-anomalies", "6",
+		}, &stdout, &stderr)
+
+		assert.Equal(t, ExitOK, code)
+
+		var report validateReport
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+
+		assert.Len(t, report.Results, 5)
+		assert.Equal(t, "/users/{user_id}", report.Results[1].Model)
+		assert.Equal(t, &validateSummary{
+			Requests:  5,
+			Valid:     1,
+			Invalid:   2,
+			Errors:    2,
+			Anomalies: 4,
+			Rows: []*summaryRow{
+				{Endpoint: "GET /users/{user_id}", Field: "path_params", Code: "TYPE_MISMATCH", Count: 2},
+				{Endpoint: "GET /users/{user_id}", Field: "query_params", Code: "MISSING_REQUIRED", Count: 2},
+				{Endpoint: "-", Field: "request", Code: "INVALID_JSON", Count: 1},
+				{Endpoint: "GET /orders", Field: "request", Code: "NO_MODEL", Count: 1},
+			},
+		}, report.Summary)
+	})
+
+	t.Run("json array capture", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		arrayPath := writeTempFile(t, "requests.json",
+			`[{"path": "/users/1", "method": "GET", "query_params": [{"name": "verbose", "value": false}]}]`)
+
+		code := Run([]string{"validate", "--models", modelsPath, "--requests", arrayPath}, &stdout, &stderr)
+
+		assert.Equal(t, ExitOK, code)
+		assert.Contains(t, stdout.String(), "1 requests: 1 valid, 0 invalid, 0 errors, 0 anomalies")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"validate", "--format", "xml"}, &stdout, &stderr)
+
+		assert.Equal(t, ExitUsage, code)
+	})
+
+	t.Run("missing models file", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := Run([]string{"validate", "--models", "does-not-exist.json", "--requests", requestsPath}, &stdout, &stderr)
+
+		assert.Equal(t, ExitFailure, code)
+		assert.Contains(t, stderr.String(), "failed to read models")
+	})
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, ExitUsage, Run([]string{"serve"}, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Run(nil, &stdout, &stderr))
+}
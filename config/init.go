@@ -15,6 +15,32 @@ type InitConfig struct {
 
 	// Healthcheck configuration
 	HealthcheckPort int `env:"HEALTHCHECK_PORT" env-default:"2802"`
+
+	// Model store configuration
+	StoreType          string `env:"STORE_TYPE" env-default:"memory"`
+	StoreDataDir       string `env:"STORE_DATA_DIR" env-default:"data"`
+	StoreSnapshotEvery int    `env:"STORE_SNAPSHOT_EVERY" env-default:"100"`
+
+	// Traffic learning configuration
+	LearnMinSamples   int `env:"LEARN_MIN_SAMPLES" env-default:"20"`
+	LearnMaxEndpoints int `env:"LEARN_MAX_ENDPOINTS" env-default:"1000"`
+
+	// Risk scoring configuration. Weights are comma separated key:weight pairs overriding the defaults.
+	RiskCodeWeights    map[string]float64 `env:"RISK_CODE_WEIGHTS"`
+	RiskSectionWeights map[string]float64 `env:"RISK_SECTION_WEIGHTS"`
+	RiskThreshold      float64            `env:"RISK_THRESHOLD" env-default:"0"`
+
+	// JWT signature verification keys, from a JWKS file and from PEM encoded public key files
+	JWTJWKSFile string   `env:"JWT_JWKS_FILE"`
+	JWTKeyFiles []string `env:"JWT_KEY_FILES" env-separator:","`
+
+	// Proxy configuration. Setting an upstream turns the main server into a validating reverse proxy,
+	// serving the API under the API prefix and forwarding every other request.
+	ProxyUpstream      string `env:"PROXY_UPSTREAM"`
+	ProxyAPIPrefix     string `env:"PROXY_API_PREFIX" env-default:"/_detector"`
+	ProxyTenant        string `env:"PROXY_TENANT" env-default:"default"`
+	ProxyDefaultAction string `env:"PROXY_DEFAULT_ACTION" env-default:"pass"`
+	ProxyBlockStatus   int    `env:"PROXY_BLOCK_STATUS" env-default:"403"`
 }
 
 func LoadInit() *InitConfig {
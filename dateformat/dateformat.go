@@ -0,0 +1,146 @@
+package dateformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Named presets of date and time formats. Any other format is a Go layout such as 2006/01/02 15:04.
+const (
+	// DDMMYYYY is the historical format of the Date type, e.g. 31-12-2024
+	DDMMYYYY = "dd-mm-yyyy"
+	// ISO8601 is an ISO 8601 calendar date, e.g. 2024-12-31, the OpenAPI date format
+	ISO8601 = "iso8601"
+	// RFC3339 is an RFC 3339 timestamp with an optional fraction, e.g. 2024-12-31T23:59:59.5Z
+	RFC3339 = "rfc3339"
+	// RFC1123 is the HTTP date format, e.g. Tue, 31 Dec 2024 23:59:59 GMT
+	RFC1123 = "rfc1123"
+	// Unix is an integer number of seconds since the epoch, as a number or a string of digits
+	Unix = "unix"
+	// UnixMilli is an integer number of milliseconds since the epoch, as a number or a string of digits
+	UnixMilli = "unix_ms"
+)
+
+var presetLayouts = map[string]string{
+	DDMMYYYY: "02-01-2006",
+	ISO8601:  time.DateOnly,
+	RFC3339:  time.RFC3339Nano,
+	RFC1123:  time.RFC1123,
+}
+
+// layoutProbe is formatted with custom layouts to check them. None of its fields equals the Go reference time,
+// so a layout without elements formats to itself.
+var layoutProbe = time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+// Presets returns the names of the preset formats, sorted
+func Presets() []string {
+	presets := []string{Unix, UnixMilli}
+	for name := range presetLayouts {
+		presets = append(presets, name)
+	}
+
+	slices.Sort(presets)
+
+	return presets
+}
+
+// Check rejects a format that is neither a preset nor a Go layout holding at least a year
+func Check(format string) error {
+	if isEpoch(format) {
+		return nil
+	}
+
+	if _, exists := presetLayouts[format]; exists {
+		return nil
+	}
+
+	formatted := layoutProbe.Format(format)
+	if formatted == format {
+		return fmt.Errorf("%q is neither a preset (%v) nor a Go layout", format, Presets())
+	}
+
+	parsed, err := time.Parse(format, formatted)
+	if err != nil {
+		return fmt.Errorf("layout %q cannot parse its own output: %w", format, err)
+	}
+
+	if parsed.Year() != layoutProbe.Year() {
+		return fmt.Errorf("layout %q has no year", format)
+	}
+
+	return nil
+}
+
+// Parse returns the time held by a value in the first of the formats it matches. Dates are parsed on the
+// calendar, so 31-02-2024 matches no format. Values without a zone are in UTC.
+func Parse(value any, formats []string) (time.Time, bool) {
+	for _, format := range formats {
+		if parsed, ok := parse(value, format); ok {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parse(value any, format string) (time.Time, bool) {
+	if isEpoch(format) {
+		epoch, ok := epochValue(value)
+		if !ok {
+			return time.Time{}, false
+		}
+
+		if format == UnixMilli {
+			return time.UnixMilli(epoch).UTC(), true
+		}
+
+		return time.Unix(epoch, 0).UTC(), true
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	layout, exists := presetLayouts[format]
+	if !exists {
+		layout = format
+	}
+
+	parsed, err := time.Parse(layout, str)
+
+	return parsed, err == nil
+}
+
+func isEpoch(format string) bool {
+	return format == Unix || format == UnixMilli
+}
+
+// epochValue reads an integer epoch from a JSON number or from the string of a query, header or path segment
+func epochValue(value any) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		// float64(math.MaxInt64) rounds up to 2^63, which int64 cannot hold
+		if v != math.Trunc(v) || math.Abs(v) >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case json.Number:
+		epoch, err := v.Int64()
+		return epoch, err == nil
+	case string:
+		epoch, err := strconv.ParseInt(v, 10, 64)
+		return epoch, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,87 @@
+package dateformat
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		format  string
+		isValid bool
+	}{
+		{format: DDMMYYYY, isValid: true},
+		{format: ISO8601, isValid: true},
+		{format: RFC3339, isValid: true},
+		{format: RFC1123, isValid: true},
+		{format: Unix, isValid: true},
+		{format: UnixMilli, isValid: true},
+		{format: "2006/01/02", isValid: true},
+		{format: "02 Jan 06 15:04", isValid: true},
+		{format: "yyyy-mm-dd", isValid: false},
+		{format: "iso", isValid: false},
+		{format: "15:04:05", isValid: false},
+		{format: "", isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			assert.Equal(t, tc.isValid, Check(tc.format) == nil)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tEndOf2024 := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		value    any
+		formats  []string
+		expected time.Time
+		isValid  bool
+	}{
+		{name: "dd-mm-yyyy", value: "31-12-2024", formats: []string{DDMMYYYY}, expected: tEndOf2024, isValid: true},
+		{name: "impossible calendar date", value: "31-02-2024", formats: []string{DDMMYYYY}, isValid: false},
+		{name: "leap day", value: "2024-02-29", formats: []string{ISO8601}, isValid: true,
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day of a common year", value: "2023-02-29", formats: []string{ISO8601}, isValid: false},
+		{name: "iso8601", value: "2024-12-31", formats: []string{ISO8601}, expected: tEndOf2024, isValid: true},
+		{name: "rfc3339 with zone", value: "2024-12-31T02:00:00+02:00", formats: []string{RFC3339}, isValid: true,
+			expected: tEndOf2024},
+		{name: "rfc3339 with fraction", value: "2024-12-31T00:00:00.5Z", formats: []string{RFC3339}, isValid: true,
+			expected: tEndOf2024.Add(500 * time.Millisecond)},
+		{name: "rfc1123", value: "Tue, 31 Dec 2024 00:00:00 UTC", formats: []string{RFC1123}, isValid: true,
+			expected: tEndOf2024},
+		{name: "unix number", value: float64(tEndOf2024.Unix()), formats: []string{Unix}, expected: tEndOf2024,
+			isValid: true},
+		{name: "unix JSON number", value: json.Number("1735603200"), formats: []string{Unix}, expected: tEndOf2024,
+			isValid: true},
+		{name: "unix string", value: "1735603200", formats: []string{Unix}, expected: tEndOf2024, isValid: true},
+		{name: "unix fraction", value: 1735603200.5, formats: []string{Unix}, isValid: false},
+		{name: "unix beyond int64", value: 9.223372036854775808e18, formats: []string{Unix}, isValid: false},
+		{name: "unix milliseconds", value: float64(tEndOf2024.UnixMilli()), formats: []string{UnixMilli},
+			expected: tEndOf2024, isValid: true},
+		{name: "custom layout", value: "2024/12/31", formats: []string{"2006/01/02"}, expected: tEndOf2024,
+			isValid: true},
+		{name: "second format matches", value: "2024-12-31", formats: []string{DDMMYYYY, ISO8601},
+			expected: tEndOf2024, isValid: true},
+		{name: "no format matches", value: "12/31/2024", formats: []string{DDMMYYYY, ISO8601}, isValid: false},
+		{name: "number for a layout", value: 20241231.0, formats: []string{ISO8601}, isValid: false},
+		{name: "no formats", value: "2024-12-31", formats: nil, isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, ok := Parse(tc.value, tc.formats)
+			assert.Equal(t, tc.isValid, ok)
+
+			if tc.isValid {
+				assert.True(t, tc.expected.Equal(parsed), "expected %v, got %v", tc.expected, parsed)
+			}
+		})
+	}
+}
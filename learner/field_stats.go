@@ -0,0 +1,274 @@
+package learner
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+
+	"anomaly_detector/models"
+	"anomaly_detector/validator"
+)
+
+// fieldStats aggregates the values observed for one parameter, or for the properties and items nested in it
+type fieldStats struct {
+	// observed counts the values seen, i.e. the samples (or parent objects) the field appeared in
+	observed int
+	// matches counts, per type, the values that are valid for it
+	matches map[models.ParamType]int
+	// preferred counts, per type, the values for which it is the most specific matching type
+	preferred map[models.ParamType]int
+
+	// minimum and maximum hold the extreme numbers as observed, compared exactly so that 64-bit IDs stay apart
+	minimum, maximum     any
+	minLength, maxLength *int
+
+	// nulls counts the null values, which make the field nullable rather than adding the Null type to others
+	nulls int
+
+	// repeated is set once the field was sent more than once in a single request
+	repeated bool
+
+	// objects counts the Object values, the denominator deciding whether a property is required
+	objects    int
+	properties map[string]*fieldStats
+	items      *fieldStats
+}
+
+func newFieldStats() *fieldStats {
+	return &fieldStats{
+		matches:   map[models.ParamType]int{},
+		preferred: map[models.ParamType]int{},
+	}
+}
+
+func (f *fieldStats) observe(value any) {
+	f.observed++
+
+	if value == nil {
+		f.nulls++
+	}
+
+	matching := validator.MatchingTypes(value)
+	for _, paramType := range matching {
+		f.matches[paramType]++
+	}
+
+	if len(matching) > 0 {
+		f.preferred[matching[0]]++
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		// A number out of the float64 range could not be proposed as a bound
+		if _, err := v.Float64(); err == nil {
+			f.observeNumber(v)
+		}
+	case float64, int:
+		f.observeNumber(v)
+	case string:
+		f.observeLength(len([]rune(v)))
+	case map[string]any:
+		f.objects++
+
+		for name, property := range v {
+			child(&f.properties, name).observe(property)
+		}
+	case []any:
+		f.observeLength(len(v))
+
+		if f.items == nil {
+			f.items = newFieldStats()
+		}
+
+		for _, item := range v {
+			f.items.observe(item)
+		}
+	}
+}
+
+func (f *fieldStats) observeNumber(value any) {
+	if f.minimum == nil || compareNumbers(value, f.minimum) < 0 {
+		f.minimum = value
+	}
+
+	if f.maximum == nil || compareNumbers(value, f.maximum) > 0 {
+		f.maximum = value
+	}
+}
+
+func compareNumbers(a, b any) int {
+	comparison, _ := validator.CompareNumbers(a, b)
+	return comparison
+}
+
+// numberBound converts an observed extreme to a float64 bound, rounded away from the observed values when
+// float64 cannot hold it exactly, so that the bound still accepts them: a maximum of 2^53+1 gives 2^53+2.
+func numberBound(value any, away float64) *float64 {
+	var bound float64
+
+	switch v := value.(type) {
+	case json.Number:
+		bound, _ = v.Float64()
+	case float64:
+		bound = v
+	case int:
+		bound = float64(v)
+	default:
+		return nil
+	}
+
+	if comparison := compareNumbers(bound, value); comparison != 0 && (comparison < 0) == (away > 0) {
+		bound = math.Nextafter(bound, away)
+	}
+
+	return &bound
+}
+
+func (f *fieldStats) observeLength(length int) {
+	if f.minLength == nil || length < *f.minLength {
+		f.minLength = &length
+	}
+
+	if f.maxLength == nil || length > *f.maxLength {
+		f.maxLength = &length
+	}
+}
+
+// inferTypes returns the inferred types of the field and the share of values explained by the first one.
+// The most specific type every value matched wins. Otherwise each value contributes its most specific type,
+// with String absorbing the other string types. Null values are left out, unless no other value was observed.
+func (f *fieldStats) inferTypes() ([]models.ParamType, float64) {
+	if f.observed == 0 {
+		return []models.ParamType{models.TypeString}, 0
+	}
+
+	observed := f.observed
+	if f.isNullable() {
+		observed -= f.nulls
+	}
+
+	for _, paramType := range validator.InferableTypes() {
+		if f.matches[paramType] == observed {
+			return []models.ParamType{paramType}, 1
+		}
+	}
+
+	var types []models.ParamType
+
+	for _, paramType := range validator.InferableTypes() {
+		if f.preferred[paramType] == 0 || (f.preferred[models.TypeString] > 0 && isStringType(paramType)) ||
+			(paramType == models.TypeNull && f.isNullable()) {
+			continue
+		}
+
+		types = append(types, paramType)
+	}
+
+	// No type matched any value, e.g. only values of unknown Go types were observed
+	if len(types) == 0 {
+		return []models.ParamType{models.TypeString}, 0
+	}
+
+	// The dominant type comes first
+	sort.SliceStable(types, func(i, j int) bool {
+		return f.matches[types[i]] > f.matches[types[j]]
+	})
+
+	return types, float64(f.matches[types[0]]) / float64(observed)
+}
+
+// isNullable tells whether the field was observed both null and with other values
+func (f *fieldStats) isNullable() bool {
+	return f.nulls > 0 && f.nulls < f.observed
+}
+
+func isStringType(paramType models.ParamType) bool {
+	switch paramType {
+	case models.TypeDate, models.TypeEmail, models.TypeUUID, models.TypeAuthToken, models.TypeJWT,
+		models.TypeTimestamp, models.TypeIP, models.TypeCIDR, models.TypePhone, models.TypeURL:
+		return true
+	default:
+		return false
+	}
+}
+
+// child returns the stats of a named field, creating them on first use
+func child(fields *map[string]*fieldStats, name string) *fieldStats {
+	if *fields == nil {
+		*fields = map[string]*fieldStats{}
+	}
+
+	stats, exists := (*fields)[name]
+	if !exists {
+		stats = newFieldStats()
+		(*fields)[name] = stats
+	}
+
+	return stats
+}
+
+// infer turns the stats of a field into a parameter and appends the insights backing it. path is the dotted
+// name of the field within its section, total the number of samples (or parent objects) it could appear in.
+func (f *fieldStats) infer(
+	field, name, path string, total, minSamples int, insights *[]*FieldInsight,
+) *models.Parameter {
+	types, consistency := f.inferTypes()
+	param := &models.Parameter{
+		Name: name, Types: types, Required: total > 0 && f.observed == total, Repeated: f.repeated,
+		Nullable: f.isNullable(),
+	}
+
+	*insights = append(*insights, &FieldInsight{
+		Field:      field,
+		Name:       path,
+		Types:      types,
+		Required:   param.Required,
+		Observed:   f.observed,
+		Confidence: confidence(consistency, f.observed, minSamples),
+		Minimum:    numberBound(f.minimum, math.Inf(-1)),
+		Maximum:    numberBound(f.maximum, math.Inf(1)),
+		MinLength:  f.minLength,
+		MaxLength:  f.maxLength,
+	})
+
+	if len(f.properties) > 0 && slices.Contains(types, models.TypeObject) {
+		param.Properties = inferFields(f.properties, field, path+".", f.objects, minSamples, insights)
+	}
+
+	if f.items != nil && f.items.observed > 0 && slices.Contains(types, models.TypeList) {
+		param.Items = f.items.infer(field, "", path+"[]", 0, minSamples, insights)
+	}
+
+	return param
+}
+
+// inferFields infers the parameters of a group of fields, sorted by name
+func inferFields(
+	fields map[string]*fieldStats, field, prefix string, total, minSamples int, insights *[]*FieldInsight,
+) []*models.Parameter {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	params := make([]*models.Parameter, 0, len(names))
+
+	for _, name := range names {
+		params = append(params, fields[name].infer(field, name, prefix+name, total, minSamples, insights))
+	}
+
+	return params
+}
+
+// confidence weighs the type consistency of a field by how close its number of observations is to minSamples
+func confidence(consistency float64, observed, minSamples int) float64 {
+	coverage := 1.0
+	if minSamples > 0 && observed < minSamples {
+		coverage = float64(observed) / float64(minSamples)
+	}
+
+	return math.Round(consistency*coverage*1000) / 1000
+}
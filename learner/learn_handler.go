@@ -0,0 +1,170 @@
+package learner
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"anomaly_detector/api"
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/validator"
+)
+
+const cQueryMinSamples = "min_samples"
+
+type ILearnHandler interface {
+	api.IHandler
+	HandleProposals(w http.ResponseWriter, r *http.Request)
+	HandleCommit(w http.ResponseWriter, r *http.Request)
+	HandleReset(w http.ResponseWriter, r *http.Request)
+}
+
+type learnHandler struct {
+	store      store.IModelStore
+	learner    ILearner
+	minSamples int
+}
+
+func NewLearnHandler(cfg *config.InitConfig, store store.IModelStore, learner ILearner) ILearnHandler {
+	return &learnHandler{
+		store:      store,
+		learner:    learner,
+		minSamples: cfg.LearnMinSamples,
+	}
+}
+
+// Handle observes the requests of the body, a single request, a JSON array or NDJSON.
+// Items that are not valid requests are counted as errors instead of failing the whole body.
+func (h *learnHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var observed, dropped, invalid int
+
+	err := validator.ScanRequests(r.Body, func(item []byte) {
+		var req models.Request
+		if err := json.Unmarshal(item, &req); err != nil || req.Path == "" || req.Method == "" {
+			invalid++
+			return
+		}
+
+		if !h.learner.Observe(ctx, &req) {
+			dropped++
+			return
+		}
+
+		observed++
+	})
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := map[string]any{
+		"observed": observed,
+		"dropped":  dropped,
+		"invalid":  invalid,
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleProposals returns the models inferred for the endpoints observed at least min_samples times
+func (h *learnHandler) HandleProposals(w http.ResponseWriter, r *http.Request) {
+	minSamples, err := h.minSamplesFromQuery(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	proposals := h.learner.Proposals(r.Context(), minSamples)
+	if proposals == nil {
+		proposals = []*Proposal{}
+	}
+
+	api.RespondJSON(w, http.StatusOK, proposals)
+}
+
+// HandleCommit stores the proposals of endpoints observed at least min_samples times with a single StoreAll.
+// Endpoints that already match a model, e.g. /users/42 once /users/{id} is stored, are skipped.
+// Committed endpoints are forgotten by the learner.
+func (h *learnHandler) HandleCommit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	minSamples, err := h.minSamplesFromQuery(r)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var (
+		apiModels []*models.APIModel
+		skipped   []string
+	)
+
+	for _, proposal := range h.learner.Proposals(ctx, minSamples) {
+		if _, err := h.store.Match(ctx, proposal.Model.Path, proposal.Model.Method); err == nil {
+			skipped = append(skipped, proposal.Model.Method+" "+proposal.Model.Path)
+			continue
+		}
+
+		apiModels = append(apiModels, proposal.Model)
+	}
+
+	if len(apiModels) == 0 {
+		api.RespondError(w, http.StatusBadRequest, "no new endpoint was observed at least min_samples times")
+		return
+	}
+
+	ok, err := h.store.StoreAll(ctx, apiModels)
+	if err != nil {
+		if !ok {
+			slog.ErrorContext(ctx, "error storing learned models", "error", err)
+			api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+			return
+		}
+
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	for _, model := range apiModels {
+		h.learner.Forget(ctx, model.Path, model.Method)
+	}
+
+	response := map[string]any{
+		"message":   "learned models stored successfully",
+		"committed": len(apiModels),
+		"skipped":   skipped,
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleReset drops everything learned so far
+func (h *learnHandler) HandleReset(w http.ResponseWriter, r *http.Request) {
+	h.learner.Reset(r.Context())
+
+	response := map[string]any{
+		"message": "learned traffic cleared",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// minSamplesFromQuery reads the min_samples query parameter, falling back to the configured threshold
+func (h *learnHandler) minSamplesFromQuery(r *http.Request) (int, error) {
+	value := r.URL.Query().Get(cQueryMinSamples)
+	if value == "" {
+		return h.minSamples, nil
+	}
+
+	minSamples, err := strconv.Atoi(value)
+	if err != nil || minSamples < 1 {
+		return 0, errors.New("min_samples must be a positive integer")
+	}
+
+	return minSamples, nil
+}
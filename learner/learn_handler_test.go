@@ -0,0 +1,144 @@
+package learner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	tLearnPath     = "/learn"
+	tProposalsPath = "/learn/proposals"
+	tCommitPath    = "/learn/commit"
+)
+
+func TestLearnHandler(t *testing.T) {
+	tStoreMock := store.NewMockIModelStore(t)
+	tLearnerMock := NewMockILearner(t)
+
+	tHandler := &learnHandler{
+		store:      tStoreMock,
+		learner:    tLearnerMock,
+		minSamples: 20,
+	}
+
+	tProposals := []*Proposal{
+		{Model: &models.APIModel{Path: "/users", Method: "GET"}, Samples: 20},
+		{Model: &models.APIModel{Path: "/orders", Method: "GET"}, Samples: 30},
+	}
+
+	t.Run("observe requests and count invalid items", func(t *testing.T) {
+		body := `{"path": "/users", "method": "GET"}
+{"path": "/orders", "method": "GET"}
+not json
+{"method": "GET"}
+`
+		httpRequest := httptest.NewRequest(http.MethodPost, tLearnPath, strings.NewReader(body))
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Observe(mock.Anything, mock.Anything).Return(true).Once()
+		tLearnerMock.EXPECT().Observe(mock.Anything, mock.Anything).Return(false).Once()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response map[string]any
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"observed": 1.0, "dropped": 1.0, "invalid": 2.0}, response)
+	})
+
+	t.Run("proposals use the configured threshold by default", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tProposalsPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Proposals(mock.Anything, 20).Return(tProposals).Once()
+
+		tHandler.HandleProposals(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response []*Proposal
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, tProposals, response)
+	})
+
+	t.Run("error with invalid min_samples", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tProposalsPath+"?min_samples=0", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleProposals(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("commit new endpoints and forget them", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tCommitPath+"?min_samples=5", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Proposals(mock.Anything, 5).Return(tProposals).Once()
+		tStoreMock.EXPECT().Match(mock.Anything, "/users", "GET").Return(tProposals[0].Model, nil).Once()
+		tStoreMock.EXPECT().Match(mock.Anything, "/orders", "GET").Return(nil, store.ErrModelNotFound).Once()
+		tStoreMock.EXPECT().StoreAll(mock.Anything, []*models.APIModel{tProposals[1].Model}).Return(true, nil).Once()
+		tLearnerMock.EXPECT().Forget(mock.Anything, "/orders", "GET").Once()
+
+		tHandler.HandleCommit(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response map[string]any
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, response["committed"])
+		assert.Equal(t, []any{"GET /users"}, response["skipped"])
+	})
+
+	t.Run("error when nothing can be committed", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tCommitPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Proposals(mock.Anything, 20).Return(nil).Once()
+
+		tHandler.HandleCommit(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("internal error storing learned models", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tCommitPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Proposals(mock.Anything, 20).Return(tProposals[1:]).Once()
+		tStoreMock.EXPECT().Match(mock.Anything, "/orders", "GET").Return(nil, store.ErrModelNotFound).Once()
+		tStoreMock.EXPECT().StoreAll(mock.Anything, mock.Anything).Return(false, errors.New("disk full")).Once()
+
+		tHandler.HandleCommit(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusInternalServerError, tRecorder.Code)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodDelete, tLearnPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tLearnerMock.EXPECT().Reset(mock.Anything).Once()
+
+		tHandler.HandleReset(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+}
@@ -0,0 +1,236 @@
+package learner
+
+import (
+	"context"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/tenant"
+	"anomaly_detector/validator"
+)
+
+const (
+	cFieldQueryParams = "query_params"
+	cFieldHeaders     = "headers"
+	cFieldCookies     = "cookies"
+	cFieldBody        = "body"
+)
+
+type ILearner interface {
+	// Observe aggregates a request into the statistics of its endpoint. It returns false when the request was
+	// dropped because the maximum number of endpoints is already tracked, across all tenants.
+	// Like the other methods, it is scoped to the tenant carried by the context.
+	Observe(ctx context.Context, req *models.Request) bool
+	// Proposals infers a model for every endpoint observed at least minSamples times, sorted by path and method
+	Proposals(ctx context.Context, minSamples int) []*Proposal
+	// Forget drops the statistics of an endpoint, e.g. once its proposal was committed
+	Forget(ctx context.Context, path, method string)
+	// Reset drops all statistics of the tenant
+	Reset(ctx context.Context)
+}
+
+// Proposal is a model inferred from observed traffic, to be reviewed before it is stored
+type Proposal struct {
+	Model   *models.APIModel `json:"model"`
+	Samples int              `json:"samples"`
+	Fields  []*FieldInsight  `json:"fields"`
+}
+
+// FieldInsight backs an inferred parameter with what was observed.
+// Confidence is the share of values matching the first type, scaled down while the field was observed
+// fewer than min_samples times.
+type FieldInsight struct {
+	Field      string             `json:"field"`
+	Name       string             `json:"name"`
+	Types      []models.ParamType `json:"types"`
+	Required   bool               `json:"required"`
+	Observed   int                `json:"observed"`
+	Confidence float64            `json:"confidence"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+	MinLength  *int               `json:"min_length,omitempty"`
+	MaxLength  *int               `json:"max_length,omitempty"`
+}
+
+// endpointStats aggregates the samples of one path and method of a tenant. The path is the template of the
+// model the requests matched, or their literal path when none did.
+type endpointStats struct {
+	tenant       string
+	path, method string
+	samples      int
+	query        map[string]*fieldStats
+	headers      map[string]*fieldStats
+	cookies      map[string]*fieldStats
+	body         map[string]*fieldStats
+}
+
+type learner struct {
+	mu           sync.Mutex
+	endpoints    map[string]*endpointStats
+	maxEndpoints int
+
+	// store resolves request paths to the templates of the stored models, it may be nil
+	store store.IModelStore
+}
+
+// NewLearner creates an in-memory learner tracking at most LearnMaxEndpoints endpoints, unbounded when not positive.
+// Requests matching a model of modelStore are aggregated under its path template, e.g. /users/{id}.
+func NewLearner(cfg *config.InitConfig, modelStore store.IModelStore) ILearner {
+	return newLearner(modelStore, cfg.LearnMaxEndpoints)
+}
+
+func newLearner(modelStore store.IModelStore, maxEndpoints int) *learner {
+	return &learner{
+		endpoints:    map[string]*endpointStats{},
+		maxEndpoints: maxEndpoints,
+		store:        modelStore,
+	}
+}
+
+func (l *learner) Observe(ctx context.Context, req *models.Request) bool {
+	method := strings.ToUpper(req.Method)
+	path := l.endpointPath(ctx, req.Path, method)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := tenant.FromContext(ctx)
+	key := getKey(name, path, method)
+
+	endpoint, exists := l.endpoints[key]
+	if !exists {
+		if l.maxEndpoints > 0 && len(l.endpoints) >= l.maxEndpoints {
+			return false
+		}
+
+		endpoint = &endpointStats{tenant: name, path: path, method: method}
+		l.endpoints[key] = endpoint
+	}
+
+	endpoint.samples++
+
+	observeParams(&endpoint.query, req.QueryParams, false)
+	observeParams(&endpoint.headers, req.Headers, true)
+	observeParams(&endpoint.cookies, req.Cookies, false)
+	observeParams(&endpoint.body, req.Body, false)
+
+	return true
+}
+
+// endpointPath returns the path template of the model a request matches, so that /users/1 and /users/2 are
+// one endpoint once /users/{id} is modeled. Without a matching model the literal path is the endpoint.
+func (l *learner) endpointPath(ctx context.Context, path, method string) string {
+	if l.store == nil {
+		return path
+	}
+
+	model, err := l.store.Match(ctx, path, method)
+	if err != nil {
+		return path
+	}
+
+	return model.Path
+}
+
+// observeParams aggregates the parameters of one section. Standard headers are skipped, the validator
+// never reports them as unexpected so they do not need to be modeled. Header names are canonicalized,
+// as the validator matches them regardless of their case.
+func observeParams(fields *map[string]*fieldStats, params []*models.RequestParam, headers bool) {
+	seen := map[string]bool{}
+
+	for _, param := range params {
+		if param == nil || param.Name == "" || (headers && validator.IsStandardHeader(param.Name)) {
+			continue
+		}
+
+		name := param.Name
+		if headers {
+			name = textproto.CanonicalMIMEHeaderKey(name)
+		}
+
+		// A repeated parameter is counted once per sample so that it cannot inflate the required detection
+		if seen[name] {
+			child(fields, name).repeated = true
+			continue
+		}
+
+		seen[name] = true
+
+		child(fields, name).observe(param.Value)
+	}
+}
+
+func (l *learner) Proposals(ctx context.Context, minSamples int) []*Proposal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := tenant.FromContext(ctx)
+
+	var proposals []*Proposal
+
+	for _, endpoint := range l.endpoints {
+		if endpoint.tenant != name || endpoint.samples < minSamples || endpoint.samples == 0 {
+			continue
+		}
+
+		proposals = append(proposals, endpoint.propose(minSamples))
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Model.Path != proposals[j].Model.Path {
+			return proposals[i].Model.Path < proposals[j].Model.Path
+		}
+
+		return proposals[i].Model.Method < proposals[j].Model.Method
+	})
+
+	return proposals
+}
+
+func (e *endpointStats) propose(minSamples int) *Proposal {
+	var insights []*FieldInsight
+
+	model := &models.APIModel{
+		Path:        e.path,
+		Method:      e.method,
+		QueryParams: inferFields(e.query, cFieldQueryParams, "", e.samples, minSamples, &insights),
+		Headers:     inferFields(e.headers, cFieldHeaders, "", e.samples, minSamples, &insights),
+		Body:        inferFields(e.body, cFieldBody, "", e.samples, minSamples, &insights),
+	}
+
+	// Cookies are only sent by raw captures, models of the other requests keep omitting the section
+	if len(e.cookies) > 0 {
+		model.Cookies = inferFields(e.cookies, cFieldCookies, "", e.samples, minSamples, &insights)
+	}
+
+	return &Proposal{Model: model, Samples: e.samples, Fields: insights}
+}
+
+func (l *learner) Forget(ctx context.Context, path, method string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.endpoints, getKey(tenant.FromContext(ctx), path, strings.ToUpper(method)))
+}
+
+func (l *learner) Reset(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := tenant.FromContext(ctx)
+
+	for key, endpoint := range l.endpoints {
+		if endpoint.tenant == name {
+			delete(l.endpoints, key)
+		}
+	}
+}
+
+func getKey(tenantName, path, method string) string {
+	return tenantName + " " + method + " " + path
+}
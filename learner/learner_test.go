@@ -0,0 +1,255 @@
+package learner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func param(name string, value any) *models.RequestParam {
+	return &models.RequestParam{Name: name, Value: value}
+}
+
+func TestProposals(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("infer types, required flags and ranges", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for i := 1; i <= 4; i++ {
+			req := &models.Request{
+				Path:   "/users",
+				Method: "post",
+				QueryParams: []*models.RequestParam{
+					param("id", fmt.Sprintf("%08d-0000-0000-0000-000000000000", i)),
+				},
+				Headers: []*models.RequestParam{
+					param("Authorization", "Bearer abc"),
+					param("User-Agent", "curl"),
+				},
+				Body: []*models.RequestParam{
+					param("age", float64(20+i)),
+					param("address", map[string]any{"city": "Tel Aviv", "zip": float64(i)}),
+					param("tags", []any{"a", "bb"}),
+				},
+			}
+
+			if i%2 == 0 {
+				req.Body = append(req.Body, param("nickname", "nick"))
+				req.Body[1].Value = map[string]any{"city": "Haifa"}
+			}
+
+			require.True(t, tLearner.Observe(ctx, req))
+		}
+
+		proposals := tLearner.Proposals(ctx, 4)
+		require.Len(t, proposals, 1)
+
+		assert.Equal(t, 4, proposals[0].Samples)
+		assert.Equal(t, &models.APIModel{
+			Path:   "/users",
+			Method: "POST",
+			QueryParams: []*models.Parameter{
+				{Name: "id", Types: []models.ParamType{models.TypeUUID}, Required: true},
+			},
+			Headers: []*models.Parameter{
+				{Name: "Authorization", Types: []models.ParamType{models.TypeAuthToken}, Required: true},
+			},
+			Body: []*models.Parameter{
+				{
+					Name: "address", Types: []models.ParamType{models.TypeObject}, Required: true,
+					Properties: []*models.Parameter{
+						{Name: "city", Types: []models.ParamType{models.TypeString}, Required: true},
+						{Name: "zip", Types: []models.ParamType{models.TypeInt}},
+					},
+				},
+				{Name: "age", Types: []models.ParamType{models.TypeInt}, Required: true},
+				{Name: "nickname", Types: []models.ParamType{models.TypeString}},
+				{
+					Name: "tags", Types: []models.ParamType{models.TypeList}, Required: true,
+					Items: &models.Parameter{Types: []models.ParamType{models.TypeString}},
+				},
+			},
+		}, proposals[0].Model)
+
+		insights := map[string]*FieldInsight{}
+		for _, insight := range proposals[0].Fields {
+			insights[insight.Field+" "+insight.Name] = insight
+		}
+
+		assert.Equal(t, 21.0, *insights["body age"].Minimum)
+		assert.Equal(t, 24.0, *insights["body age"].Maximum)
+		assert.Equal(t, 2, insights["body address.zip"].Observed)
+		assert.Equal(t, 1, *insights["body tags[]"].MinLength)
+		assert.Equal(t, 2, *insights["body tags[]"].MaxLength)
+		assert.Equal(t, 1.0, insights["body age"].Confidence)
+		assert.Equal(t, 0.5, insights["body nickname"].Confidence)
+	})
+
+	t.Run("canonicalize header names and detect repetition", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for i := range 2 {
+			req := &models.Request{
+				Path:        "/search",
+				Method:      "GET",
+				QueryParams: []*models.RequestParam{param("tag", "a"), param("q", "x")},
+				Headers:     []*models.RequestParam{param("x-trace-id", "abc")},
+			}
+
+			if i == 1 {
+				req.QueryParams = append(req.QueryParams, param("tag", "b"))
+				req.Headers[0].Name = "X-TRACE-ID"
+			}
+
+			require.True(t, tLearner.Observe(ctx, req))
+		}
+
+		proposals := tLearner.Proposals(ctx, 2)
+		require.Len(t, proposals, 1)
+
+		assert.Equal(t, []*models.Parameter{
+			{Name: "q", Types: []models.ParamType{models.TypeString}, Required: true},
+			{Name: "tag", Types: []models.ParamType{models.TypeString}, Required: true, Repeated: true},
+		}, proposals[0].Model.QueryParams)
+		assert.Equal(t, []*models.Parameter{
+			{Name: "X-Trace-Id", Types: []models.ParamType{models.TypeString}, Required: true},
+		}, proposals[0].Model.Headers)
+	})
+
+	t.Run("mixed values become a union led by the dominant type", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for _, value := range []any{float64(1), float64(2), float64(3), "abc"} {
+			tLearner.Observe(ctx, &models.Request{
+				Path: "/items", Method: "GET", QueryParams: []*models.RequestParam{param("id", value)},
+			})
+		}
+
+		proposals := tLearner.Proposals(ctx, 1)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, []models.ParamType{models.TypeInt, models.TypeString}, proposals[0].Model.QueryParams[0].Types)
+		assert.Equal(t, 0.75, proposals[0].Fields[0].Confidence)
+	})
+
+	t.Run("decimal values are numbers", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for _, value := range []any{json.Number("0.5"), json.Number("2")} {
+			tLearner.Observe(ctx, &models.Request{
+				Path: "/items", Method: "GET", QueryParams: []*models.RequestParam{param("ratio", value)},
+			})
+		}
+
+		proposals := tLearner.Proposals(ctx, 1)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, []models.ParamType{models.TypeNumber}, proposals[0].Model.QueryParams[0].Types)
+		assert.Equal(t, 0.5, *proposals[0].Fields[0].Minimum)
+		assert.Equal(t, 2.0, *proposals[0].Fields[0].Maximum)
+	})
+
+	t.Run("bounds of 64-bit integers accept every observed value", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for _, value := range []any{json.Number("-9007199254740993"), json.Number("9007199254740993")} {
+			tLearner.Observe(ctx, &models.Request{
+				Path: "/items", Method: "GET", QueryParams: []*models.RequestParam{param("id", value)},
+			})
+		}
+
+		proposals := tLearner.Proposals(ctx, 1)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, -9007199254740994.0, *proposals[0].Fields[0].Minimum)
+		assert.Equal(t, 9007199254740994.0, *proposals[0].Fields[0].Maximum)
+	})
+
+	t.Run("null values make a field nullable", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+
+		for _, value := range []any{nil, json.Number("1"), nil, json.Number("2")} {
+			tLearner.Observe(ctx, &models.Request{
+				Path: "/items", Method: "POST",
+				Body: []*models.RequestParam{param("parent_id", value), param("deleted_at", nil)},
+			})
+		}
+
+		proposals := tLearner.Proposals(ctx, 1)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, []*models.Parameter{
+			{Name: "deleted_at", Types: []models.ParamType{models.TypeNull}, Required: true},
+			{Name: "parent_id", Types: []models.ParamType{models.TypeInt}, Required: true, Nullable: true},
+		}, proposals[0].Model.Body)
+		assert.Equal(t, 1.0, proposals[0].Fields[1].Confidence)
+	})
+
+	t.Run("group requests by the template of the model they match", func(t *testing.T) {
+		modelStore := store.NewModelStore()
+		_, err := modelStore.StoreAll(ctx, []*models.APIModel{{Path: "/users/{id}", Method: "GET"}})
+		require.NoError(t, err)
+
+		tLearner := newLearner(modelStore, 2)
+
+		for _, path := range []string{"/users/1", "/users/2", "/users/3", "/orders/1", "/orders/2"} {
+			tLearner.Observe(ctx, &models.Request{Path: path, Method: "get"})
+		}
+
+		proposals := tLearner.Proposals(ctx, 1)
+		require.Len(t, proposals, 2)
+		assert.Equal(t, "/orders/1", proposals[0].Model.Path)
+		assert.Equal(t, "/users/{id}", proposals[1].Model.Path)
+		assert.Equal(t, 3, proposals[1].Samples)
+	})
+
+	t.Run("skip endpoints below the sample threshold", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+		tLearner.Observe(ctx, &models.Request{Path: "/a", Method: "GET"})
+		tLearner.Observe(ctx, &models.Request{Path: "/b", Method: "GET"})
+		tLearner.Observe(ctx, &models.Request{Path: "/b", Method: "GET"})
+
+		proposals := tLearner.Proposals(ctx, 2)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, "/b", proposals[0].Model.Path)
+	})
+
+	t.Run("drop new endpoints over the limit", func(t *testing.T) {
+		tLearner := newLearner(nil, 1)
+		assert.True(t, tLearner.Observe(ctx, &models.Request{Path: "/a", Method: "GET"}))
+		assert.False(t, tLearner.Observe(ctx, &models.Request{Path: "/b", Method: "GET"}))
+		assert.True(t, tLearner.Observe(ctx, &models.Request{Path: "/a", Method: "GET"}))
+	})
+
+	t.Run("forget and reset", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+		tLearner.Observe(ctx, &models.Request{Path: "/a", Method: "GET"})
+		tLearner.Observe(ctx, &models.Request{Path: "/b", Method: "GET"})
+
+		tLearner.Forget(ctx, "/a", "get")
+		assert.Len(t, tLearner.Proposals(ctx, 1), 1)
+
+		tLearner.Reset(ctx)
+		assert.Empty(t, tLearner.Proposals(ctx, 1))
+	})
+	t.Run("tenants learn separately", func(t *testing.T) {
+		tLearner := newLearner(nil, 0)
+		tAcme := tenant.WithTenant(ctx, "acme")
+
+		tLearner.Observe(tAcme, &models.Request{Path: "/a", Method: "GET"})
+		tLearner.Observe(ctx, &models.Request{Path: "/b", Method: "GET"})
+
+		proposals := tLearner.Proposals(tAcme, 1)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, "/a", proposals[0].Model.Path)
+
+		tLearner.Reset(tAcme)
+		assert.Empty(t, tLearner.Proposals(tAcme, 1))
+		assert.Len(t, tLearner.Proposals(ctx, 1), 1)
+	})
+}
@@ -0,0 +1,201 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package learner
+
+import (
+	models "anomaly_detector/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockILearner is an autogenerated mock type for the ILearner type
+type MockILearner struct {
+	mock.Mock
+}
+
+type MockILearner_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockILearner) EXPECT() *MockILearner_Expecter {
+	return &MockILearner_Expecter{mock: &_m.Mock}
+}
+
+// Forget provides a mock function with given fields: ctx, path, method
+func (_m *MockILearner) Forget(ctx context.Context, path string, method string) {
+	_m.Called(ctx, path, method)
+}
+
+// MockILearner_Forget_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Forget'
+type MockILearner_Forget_Call struct {
+	*mock.Call
+}
+
+// Forget is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+func (_e *MockILearner_Expecter) Forget(ctx interface{}, path interface{}, method interface{}) *MockILearner_Forget_Call {
+	return &MockILearner_Forget_Call{Call: _e.mock.On("Forget", ctx, path, method)}
+}
+
+func (_c *MockILearner_Forget_Call) Run(run func(ctx context.Context, path string, method string)) *MockILearner_Forget_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockILearner_Forget_Call) Return() *MockILearner_Forget_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockILearner_Forget_Call) RunAndReturn(run func(context.Context, string, string)) *MockILearner_Forget_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Observe provides a mock function with given fields: ctx, req
+func (_m *MockILearner) Observe(ctx context.Context, req *models.Request) bool {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Observe")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Request) bool); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockILearner_Observe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Observe'
+type MockILearner_Observe_Call struct {
+	*mock.Call
+}
+
+// Observe is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *models.Request
+func (_e *MockILearner_Expecter) Observe(ctx interface{}, req interface{}) *MockILearner_Observe_Call {
+	return &MockILearner_Observe_Call{Call: _e.mock.On("Observe", ctx, req)}
+}
+
+func (_c *MockILearner_Observe_Call) Run(run func(ctx context.Context, req *models.Request)) *MockILearner_Observe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.Request))
+	})
+	return _c
+}
+
+func (_c *MockILearner_Observe_Call) Return(_a0 bool) *MockILearner_Observe_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockILearner_Observe_Call) RunAndReturn(run func(context.Context, *models.Request) bool) *MockILearner_Observe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Proposals provides a mock function with given fields: ctx, minSamples
+func (_m *MockILearner) Proposals(ctx context.Context, minSamples int) []*Proposal {
+	ret := _m.Called(ctx, minSamples)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Proposals")
+	}
+
+	var r0 []*Proposal
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*Proposal); ok {
+		r0 = rf(ctx, minSamples)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Proposal)
+		}
+	}
+
+	return r0
+}
+
+// MockILearner_Proposals_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Proposals'
+type MockILearner_Proposals_Call struct {
+	*mock.Call
+}
+
+// Proposals is a helper method to define mock.On call
+//   - ctx context.Context
+//   - minSamples int
+func (_e *MockILearner_Expecter) Proposals(ctx interface{}, minSamples interface{}) *MockILearner_Proposals_Call {
+	return &MockILearner_Proposals_Call{Call: _e.mock.On("Proposals", ctx, minSamples)}
+}
+
+func (_c *MockILearner_Proposals_Call) Run(run func(ctx context.Context, minSamples int)) *MockILearner_Proposals_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockILearner_Proposals_Call) Return(_a0 []*Proposal) *MockILearner_Proposals_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockILearner_Proposals_Call) RunAndReturn(run func(context.Context, int) []*Proposal) *MockILearner_Proposals_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reset provides a mock function with given fields: ctx
+func (_m *MockILearner) Reset(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// MockILearner_Reset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reset'
+type MockILearner_Reset_Call struct {
+	*mock.Call
+}
+
+// Reset is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockILearner_Expecter) Reset(ctx interface{}) *MockILearner_Reset_Call {
+	return &MockILearner_Reset_Call{Call: _e.mock.On("Reset", ctx)}
+}
+
+func (_c *MockILearner_Reset_Call) Run(run func(ctx context.Context)) *MockILearner_Reset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockILearner_Reset_Call) Return() *MockILearner_Reset_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockILearner_Reset_Call) RunAndReturn(run func(context.Context)) *MockILearner_Reset_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockILearner creates a new instance of MockILearner. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockILearner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockILearner {
+	mock := &MockILearner{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"io"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"anomaly_detector/cli"
 	"anomaly_detector/config"
 	"anomaly_detector/infrautils"
+	"anomaly_detector/learner"
+	"anomaly_detector/openapi"
+	"anomaly_detector/proxy"
 	"anomaly_detector/server"
 	"anomaly_detector/store"
+	"anomaly_detector/tenant"
 	"anomaly_detector/validator"
 
 	"github.com/gorilla/mux"
@@ -20,9 +27,14 @@ import (
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	// Any argument selects a command line tool instead of the servers
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	logger := slog.New(tenant.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
-	}))
+	})))
 	slog.SetDefault(logger)
 
 	container := buildContainer()
@@ -47,34 +59,112 @@ func buildContainer() *dig.Container {
 	infrautils.IocProvideWrapper(c, server.NewHealthcheckServer)
 
 	// Register store
-	infrautils.IocProvideWrapper(c, store.NewModelStore)
+	infrautils.IocProvideWrapper(c, store.NewConfiguredModelStore)
+
+	// Register traffic learner
+	infrautils.IocProvideWrapper(c, learner.NewLearner)
 
 	// Register handlers
 	infrautils.IocProvideWrapper(c, store.NewStoreHandler)
 	infrautils.IocProvideWrapper(c, validator.NewValidateHandler)
+	infrautils.IocProvideWrapper(c, openapi.NewOpenAPIHandler)
+	infrautils.IocProvideWrapper(c, learner.NewLearnHandler)
+
+	// Register the reverse proxy, nil unless an upstream is configured
+	infrautils.IocProvideWrapper(c, proxy.NewProxy)
 
 	return c
 }
 
+// setProxyHandlers serves the API under the API prefix and forwards every other request through the proxy
+func setProxyHandlers(
+	router *mux.Router,
+	cfg *config.InitConfig,
+	proxyHandler proxy.IProxy,
+	storeHandler store.IStoreHandler,
+	validateHandler validator.IValidateHandler,
+	openAPIHandler openapi.IOpenAPIHandler,
+	learnHandler learner.ILearnHandler,
+) {
+	prefix := strings.TrimRight(cfg.ProxyAPIPrefix, "/")
+
+	apiRouter := router.PathPrefix(prefix).Subrouter()
+	apiRouter.HandleFunc("/proxy/stats", proxyHandler.HandleStats).Methods("GET")
+	setMuxHandlers(apiRouter, storeHandler, validateHandler, openAPIHandler, learnHandler)
+
+	// The prefix is reserved to the API, unknown API paths are not forwarded
+	router.MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+		return r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/")
+	}).Handler(proxyHandler)
+}
+
 func setMuxHandlers(
 	router *mux.Router,
 	storeHandler store.IStoreHandler,
 	validateHandler validator.IValidateHandler,
+	openAPIHandler openapi.IOpenAPIHandler,
+	learnHandler learner.ILearnHandler,
+) {
+	// Every request is scoped to a tenant, and every write records its author and comment in the model history
+	router.Use(tenant.Middleware, store.ChangeMiddleware)
+
+	router.HandleFunc("/tenants/{tenant}", storeHandler.HandleDeleteTenant).Methods("DELETE")
+
+	// The same API is served under /tenants/{tenant} for clients that address a tenant by URL
+	// rather than by the X-Tenant header
+	for _, r := range []*mux.Router{router, router.PathPrefix("/tenants/{tenant}").Subrouter()} {
+		setAPIHandlers(r, storeHandler, validateHandler, openAPIHandler, learnHandler)
+	}
+}
+
+func setAPIHandlers(
+	router *mux.Router,
+	storeHandler store.IStoreHandler,
+	validateHandler validator.IValidateHandler,
+	openAPIHandler openapi.IOpenAPIHandler,
+	learnHandler learner.ILearnHandler,
 ) {
 	router.HandleFunc("/models", storeHandler.Handle).Methods("POST")
+	router.HandleFunc("/models", storeHandler.HandleList).Methods("GET")
+	router.HandleFunc("/models/import/openapi", openAPIHandler.Handle).Methods("POST")
+	// Registered before the single model routes, which would otherwise read their first segment as a method
+	router.HandleFunc("/models/export/openapi", openAPIHandler.HandleExport).Methods("GET")
+	router.HandleFunc("/models/versions/{method}/{path:.*}", storeHandler.HandleVersions).Methods("GET")
+	router.HandleFunc("/models/diff/{method}/{path:.*}", storeHandler.HandleDiff).Methods("GET")
+	router.HandleFunc("/models/rollback/{method}/{path:.*}", storeHandler.HandleRollback).Methods("POST")
+	router.HandleFunc("/models/{method}/{path:.*}", storeHandler.HandleGet).Methods("GET")
+	router.HandleFunc("/models/{method}/{path:.*}", storeHandler.HandleReplace).Methods("PUT")
+	router.HandleFunc("/models/{method}/{path:.*}", storeHandler.HandleDelete).Methods("DELETE")
+
+	router.HandleFunc("/types", storeHandler.HandleDefineType).Methods("POST")
+	router.HandleFunc("/types", storeHandler.HandleTypes).Methods("GET")
 
 	router.HandleFunc("/validate", validateHandler.Handle).Methods("POST")
+	router.HandleFunc("/validate/batch", validateHandler.HandleBatch).Methods("POST")
+	router.HandleFunc("/validate/capture", validateHandler.HandleCapture).Methods("POST")
+
+	router.HandleFunc("/learn", learnHandler.Handle).Methods("POST")
+	router.HandleFunc("/learn", learnHandler.HandleReset).Methods("DELETE")
+	router.HandleFunc("/learn/proposals", learnHandler.HandleProposals).Methods("GET")
+	router.HandleFunc("/learn/commit", learnHandler.HandleCommit).Methods("POST")
 }
 
 func runServer(
 	router *mux.Router, mainServer server.IHTTPServer, store store.IStoreHandler,
-	validate validator.IValidateHandler, healthServer server.IHealthcheckServer) error {
+	validate validator.IValidateHandler, openAPI openapi.IOpenAPIHandler, learn learner.ILearnHandler,
+	healthServer server.IHealthcheckServer, modelStore store.IModelStore,
+	cfg *config.InitConfig, proxyHandler proxy.IProxy) error {
 	ctx := context.Background()
 
 	signals := make(chan os.Signal, 1)
 	shutdown := make(chan bool, 1)
 
-	setMuxHandlers(router, store, validate)
+	if proxyHandler != nil {
+		setProxyHandlers(router, cfg, proxyHandler, store, validate, openAPI, learn)
+		slog.InfoContext(ctx, "Proxying requests", "upstream", cfg.ProxyUpstream, "api_prefix", cfg.ProxyAPIPrefix)
+	} else {
+		setMuxHandlers(router, store, validate, openAPI, learn)
+	}
 
 	mainServer.SetHandler(router)
 
@@ -109,6 +199,13 @@ func runServer(
 	// Shutdown servers gracefully
 	doShutdown(mainServer, healthServer)
 
+	// Persistent stores hold open files that must be flushed once no more requests are served
+	if closer, ok := modelStore.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.ErrorContext(ctx, "Error closing model store", "error", err)
+		}
+	}
+
 	slog.InfoContext(ctx, "Servers exited")
 
 	return nil
@@ -12,18 +12,152 @@ const (
 	TypeEmail     ParamType = "Email"
 	TypeUUID      ParamType = "UUID"
 	TypeAuthToken ParamType = "Auth-Token"
+	TypeObject    ParamType = "Object"
+	TypeIP        ParamType = "IP"
+	TypeCIDR      ParamType = "CIDR"
+	TypeURL       ParamType = "URL"
+	TypeHostname  ParamType = "Hostname"
+	TypePhone     ParamType = "Phone"
+	TypeTimestamp ParamType = "Timestamp"
+	TypeBase64    ParamType = "Base64"
+	TypeJWT       ParamType = "JWT"
+	// TypeNumber and TypeFloat accept any JSON number, integral or not, e.g. a price of 9.99.
+	// Float is imported from and exported as the float and double OpenAPI formats.
+	TypeNumber ParamType = "Number"
+	TypeFloat  ParamType = "Float"
+	TypeNull   ParamType = "Null"
 )
 
+// UnexpectedParamsPolicy decides how request parameters that are not declared in a model are treated
+type UnexpectedParamsPolicy string
+
+const (
+	// UnexpectedParamsAllow ignores undeclared parameters
+	UnexpectedParamsAllow UnexpectedParamsPolicy = "allow"
+	// UnexpectedParamsReport lists undeclared parameters as warnings without invalidating the request
+	UnexpectedParamsReport UnexpectedParamsPolicy = "report"
+	// UnexpectedParamsReject reports undeclared parameters as anomalies, invalidating the request
+	UnexpectedParamsReject UnexpectedParamsPolicy = "reject"
+)
+
+// EnforcementAction decides what the proxy does with a request that is not valid against its model
+type EnforcementAction string
+
+const (
+	// EnforcementPass forwards the request unchanged, the anomalies are only logged
+	EnforcementPass EnforcementAction = "pass"
+	// EnforcementAnnotate forwards the request with headers carrying its risk score and anomaly codes
+	EnforcementAnnotate EnforcementAction = "annotate"
+	// EnforcementBlock answers the request without forwarding it
+	EnforcementBlock EnforcementAction = "block"
+)
+
+// IsValid reports whether the action is one of the known actions
+func (a EnforcementAction) IsValid() bool {
+	return a == EnforcementPass || a == EnforcementAnnotate || a == EnforcementBlock
+}
+
+// Enforcement is how the proxy treats the requests of a model that are not valid
+type Enforcement struct {
+	Action EnforcementAction `json:"action"`
+	// BlockStatus is the status of blocked requests, defaults to PROXY_BLOCK_STATUS
+	BlockStatus int `json:"block_status,omitempty"`
+	// BlockBody is the JSON body of blocked requests, defaults to an error message
+	BlockBody any `json:"block_body,omitempty"`
+}
+
+// IsTimeType reports whether a type holds dates or times, which may declare formats and range constraints
+func IsTimeType(paramType ParamType) bool {
+	return paramType == TypeDate || paramType == TypeTimestamp
+}
+
 type Parameter struct {
 	Name     string      `json:"name"`
 	Types    []ParamType `json:"types"`
 	Required bool        `json:"required"`
+	// Repeated allows a query param or header to be sent several times, each value being validated.
+	// Otherwise a repeated name is a DUPLICATE_PARAM anomaly.
+	Repeated bool `json:"repeated,omitempty"`
+	// Nullable accepts a null value in addition to the types, without checking it against the constraints
+	Nullable bool `json:"nullable,omitempty"`
+
+	// Properties describes the fields of an Object value
+	Properties []*Parameter `json:"properties,omitempty"`
+	// Items describes every element of a List value. Its name is ignored.
+	Items *Parameter `json:"items,omitempty"`
+
+	// Optional value constraints, checked once the value matched one of the types.
+	// Minimum and Maximum apply to numbers, MinLength and MaxLength to strings and lists,
+	// Pattern (a Go regular expression) to strings, and Enum to any value.
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"min_length,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Enum      []any    `json:"enum,omitempty"`
+
+	// Formats accepted by a Date or Timestamp parameter instead of the default of its type (dd-mm-yyyy for Date,
+	// rfc3339 for Timestamp): presets such as iso8601 or unix, or Go layouts such as 2006/01/02
+	Formats []string `json:"formats,omitempty"`
+	// Optional range constraints of Date and Timestamp values, relative to the time of validation.
+	// WithinLastDays and WithinNextDays count days of 24 hours.
+	NotFuture      bool `json:"not_future,omitempty"`
+	NotPast        bool `json:"not_past,omitempty"`
+	WithinLastDays *int `json:"within_last_days,omitempty"`
+	WithinNextDays *int `json:"within_next_days,omitempty"`
+
+	// Severity of the anomalies found in this parameter, inherited by its properties and items.
+	// Defaults to critical.
+	Severity Severity `json:"severity,omitempty"`
+	// RiskWeight multiplies the risk of the anomalies found in this parameter, inherited by its properties
+	// and items. Defaults to 1, and 0 keeps its anomalies out of the risk score.
+	RiskWeight *float64 `json:"risk_weight,omitempty"`
+
+	// JWT turns on JWT validation of an Auth-Token or JWT parameter
+	JWT *JWTRules `json:"jwt,omitempty"`
+}
+
+// JWTRules are checked against the token of an Auth-Token or JWT parameter. The exp, nbf and iat claims are
+// always checked when present, and the signature whenever verification keys are configured.
+type JWTRules struct {
+	// Algorithms allowed in the alg header, e.g. RS256. Empty allows every supported algorithm, never none.
+	Algorithms []string `json:"algorithms,omitempty"`
+	// RequiredClaims must all be present in the claims, e.g. sub or exp
+	RequiredClaims []string `json:"required_claims,omitempty"`
+	// LeewaySeconds tolerates clock skew when checking exp, nbf and iat
+	LeewaySeconds int `json:"leeway_seconds,omitempty"`
+}
+
+// jwtAlgorithms are the JWS algorithms the validator verifies. The unsecured none is deliberately missing.
+var jwtAlgorithms = map[string]struct{}{
+	"HS256": {}, "HS384": {}, "HS512": {},
+	"RS256": {}, "RS384": {}, "RS512": {},
+	"PS256": {}, "PS384": {}, "PS512": {},
+	"ES256": {}, "ES384": {}, "ES512": {},
+	"EdDSA": {},
+}
+
+// IsJWTAlgorithm reports whether alg is a supported JWS algorithm
+func IsJWTAlgorithm(alg string) bool {
+	_, exists := jwtAlgorithms[alg]
+	return exists
 }
 
 type APIModel struct {
+	// Path is either a literal path or a template with variables, e.g. /users/{user_id}/orders/{order_id}
 	Path        string       `json:"path"`
 	Method      string       `json:"method"`
+	PathParams  []*Parameter `json:"path_params,omitempty"`
 	QueryParams []*Parameter `json:"query_params"`
 	Headers     []*Parameter `json:"headers"`
+	Cookies     []*Parameter `json:"cookies,omitempty"`
 	Body        []*Parameter `json:"body"`
+
+	// UnexpectedParams is the policy for parameters not declared in the model, defaults to report
+	UnexpectedParams UnexpectedParamsPolicy `json:"unexpected_params,omitempty"`
+	// Coerce lists the sections, among query_params, headers and cookies, whose string values are parsed as the
+	// Int, Boolean or List they are declared as, e.g. ?id=42&ids=1,2. Path params are always coerced.
+	Coerce []string `json:"coerce,omitempty"`
+	// Enforcement applies when requests go through the proxy, defaults to PROXY_DEFAULT_ACTION
+	Enforcement *Enforcement `json:"enforcement,omitempty"`
 }
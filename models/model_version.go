@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ChangeType describes the change that produced a model version
+type ChangeType string
+
+const (
+	ChangeCreated    ChangeType = "created"
+	ChangeReplaced   ChangeType = "replaced"
+	ChangeRolledBack ChangeType = "rolled_back"
+	ChangeDeleted    ChangeType = "deleted"
+)
+
+// ModelVersion is an immutable entry of the history of a path and method.
+// Versions are numbered from 1; a deleted version records the deletion and has no model.
+type ModelVersion struct {
+	Tenant    string     `json:"tenant"`
+	Path      string     `json:"path"`
+	Method    string     `json:"method"`
+	Version   int        `json:"version"`
+	Change    ChangeType `json:"change"`
+	Author    string     `json:"author"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	// RolledBackFrom is the version whose model a rollback restored
+	RolledBackFrom int       `json:"rolled_back_from,omitempty"`
+	Model          *APIModel `json:"model,omitempty"`
+}
+
+// ModelDiff lists the differences between two versions of a model
+type ModelDiff struct {
+	Path    string         `json:"path"`
+	Method  string         `json:"method"`
+	From    int            `json:"from"`
+	To      int            `json:"to"`
+	Changes []*ModelChange `json:"changes"`
+}
+
+// DiffChange is the kind of a single difference between two model versions
+type DiffChange string
+
+const (
+	DiffAdded              DiffChange = "added"
+	DiffRemoved            DiffChange = "removed"
+	DiffTypesChanged       DiffChange = "types_changed"
+	DiffRequiredChanged    DiffChange = "required_changed"
+	DiffConstraintsChanged DiffChange = "constraint_changed"
+	DiffPolicyChanged      DiffChange = "policy_changed"
+)
+
+// ModelChange is a single difference between two model versions. Field is the section of the parameter
+// and Name its dotted name; Attribute names the changed constraint for constraint changes.
+type ModelChange struct {
+	Field     string     `json:"field,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Change    DiffChange `json:"change"`
+	Attribute string     `json:"attribute,omitempty"`
+	From      any        `json:"from,omitempty"`
+	To        any        `json:"to,omitempty"`
+}
@@ -1,14 +1,44 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 type RequestParam struct {
 	Name  string `json:"name"`
 	Value any    `json:"value"`
 }
 
+// UnmarshalJSON decodes the numbers of the value as json.Number rather than float64, so that 64-bit integers
+// such as IDs keep their precision
+func (p *RequestParam) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.Name, p.Value = raw.Name, nil
+
+	if len(raw.Value) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw.Value))
+	decoder.UseNumber()
+
+	return decoder.Decode(&p.Value)
+}
+
 type Request struct {
 	Path        string          `json:"path"`
 	Method      string          `json:"method"`
 	QueryParams []*RequestParam `json:"query_params"`
 	Headers     []*RequestParam `json:"headers"`
+	Cookies     []*RequestParam `json:"cookies,omitempty"`
 	Body        []*RequestParam `json:"body"`
 }
@@ -0,0 +1,14 @@
+package models
+
+// TypeDefinition is a custom parameter type defined at runtime for a tenant. A value is of the type when it
+// satisfies every rule that is set: it is a string matching Pattern, it is one of Enum, and it is of one of
+// the AnyOf types. At least one rule must be set.
+type TypeDefinition struct {
+	Name        ParamType `json:"name"`
+	Description string    `json:"description,omitempty"`
+	// Pattern is a Go regular expression, only strings can match it
+	Pattern string `json:"pattern,omitempty"`
+	Enum    []any  `json:"enum,omitempty"`
+	// AnyOf composes existing types, built-in, registered in Go or defined before this one
+	AnyOf []ParamType `json:"any_of,omitempty"`
+}
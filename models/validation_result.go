@@ -1,12 +1,95 @@
 package models
 
+// AnomalyCode is the stable, machine-readable kind of an anomaly
+type AnomalyCode string
+
+const (
+	CodeMissingRequired     AnomalyCode = "MISSING_REQUIRED"
+	CodeTypeMismatch        AnomalyCode = "TYPE_MISMATCH"
+	CodeUnexpectedParam     AnomalyCode = "UNEXPECTED_PARAM"
+	CodeConstraintViolation AnomalyCode = "CONSTRAINT_VIOLATION"
+	CodeDuplicateParam      AnomalyCode = "DUPLICATE_PARAM"
+
+	// Codes of the JWT checks of Auth-Token and JWT parameters
+	CodeInvalidToken        AnomalyCode = "INVALID_TOKEN"
+	CodeDisallowedAlgorithm AnomalyCode = "DISALLOWED_ALGORITHM"
+	CodeTokenExpired        AnomalyCode = "TOKEN_EXPIRED"
+	CodeTokenNotYetValid    AnomalyCode = "TOKEN_NOT_YET_VALID"
+	CodeMissingClaim        AnomalyCode = "MISSING_CLAIM"
+	CodeInvalidSignature    AnomalyCode = "INVALID_SIGNATURE"
+)
+
+// Severity ranks anomalies for triage, from info to critical
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders the known severities, unknown ones rank below info
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// IsValid reports whether the severity is one of the known levels
+func (s Severity) IsValid() bool {
+	_, exists := severityRanks[s]
+	return exists
+}
+
+// Rank orders severities, higher is more severe
+func (s Severity) Rank() int {
+	return severityRanks[s]
+}
+
 type FieldAnomaly struct {
-	Field         string `json:"field"`
-	ParameterName string `json:"parameter_name"`
-	Reason        string `json:"reason"`
+	Field         string      `json:"field"`
+	ParameterName string      `json:"parameter_name"`
+	Code          AnomalyCode `json:"code"`
+	Severity      Severity    `json:"severity"`
+	// Reason describes the anomaly for humans, its wording is not stable
+	Reason string `json:"reason"`
+	// Constraint names the violated constraint of a CONSTRAINT_VIOLATION, e.g. max_length,
+	// or the claim of a MISSING_CLAIM, TOKEN_EXPIRED or TOKEN_NOT_YET_VALID, e.g. exp
+	Constraint string `json:"constraint,omitempty"`
+	// Expected is what the model allows (the types, or the constraint value) and Actual what the request held
+	// (the JSON type of the value, or the value or length checked against the constraint)
+	Expected any `json:"expected,omitempty"`
+	Actual   any `json:"actual,omitempty"`
+	// Tenant is the tenant whose model the request was validated against, set by the validation API and the proxy
+	Tenant string `json:"tenant,omitempty"`
+	// Risk is what the anomaly adds to the risk score of the request, warnings add nothing
+	Risk float64 `json:"risk,omitempty"`
 }
 
 type ValidationResult struct {
+	// Valid is true while the risk score does not exceed the configured threshold
 	Valid     bool            `json:"valid"`
 	Anomalies []*FieldAnomaly `json:"anomalies,omitempty"`
+	// Warnings are findings that do not invalidate the request, such as undeclared parameters in report mode
+	Warnings []*FieldAnomaly `json:"warnings,omitempty"`
+	// HighestSeverity is the highest severity among the anomalies and warnings, empty when there are none
+	HighestSeverity Severity `json:"highest_severity,omitempty"`
+	// RiskScore sums the risk of the anomalies, from 0 to 100
+	RiskScore float64 `json:"risk_score"`
+}
+
+// SetTenant names the tenant whose model the request was validated against on every anomaly and warning
+func (r *ValidationResult) SetTenant(name string) {
+	for _, anomalies := range [][]*FieldAnomaly{r.Anomalies, r.Warnings} {
+		for _, anomaly := range anomalies {
+			anomaly.Tenant = name
+		}
+	}
+}
+
+// BatchValidationResult is one line of a batch validation response, in the same order as the input
+type BatchValidationResult struct {
+	Index  int               `json:"index"`
+	Result *ValidationResult `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
 }
@@ -0,0 +1,597 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+)
+
+const (
+	cRefPrefixSchemas       = "#/components/schemas/"
+	cRefPrefixParameters    = "#/components/parameters/"
+	cRefPrefixRequestBodies = "#/components/requestBodies/"
+
+	cAuthorizationHeader = "Authorization"
+
+	cSectionQueryParams = "query_params"
+	cSectionHeaders     = "headers"
+	cSectionCookies     = "cookies"
+)
+
+// ImportResult holds the models translated from a document and the constructs that could not be translated
+type ImportResult struct {
+	Models   []*models.APIModel `json:"models"`
+	Warnings []string           `json:"warnings"`
+}
+
+// requestBodyContentTypes are the media types whose schema describes the body, in order of preference
+var requestBodyContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"multipart/form-data",
+}
+
+// converter translates a single document. Warnings are prefixed with the location they relate to.
+type converter struct {
+	doc      *Document
+	warnings []string
+}
+
+// Convert translates every operation of an OpenAPI document into an APIModel.
+// Constructs that have no equivalent in the model format are skipped and reported as warnings.
+func Convert(doc *Document) *ImportResult {
+	c := &converter{doc: doc}
+
+	var apiModels []*models.APIModel
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+
+		for _, op := range item.operations() {
+			apiModels = append(apiModels, c.convertOperation(path, op.method, item, op.operation))
+		}
+	}
+
+	return &ImportResult{Models: apiModels, Warnings: c.warnings}
+}
+
+type methodOperation struct {
+	method    string
+	operation *Operation
+}
+
+// operations returns the operations defined on a path item in a stable order
+func (p *PathItem) operations() []methodOperation {
+	all := []methodOperation{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch}, {"TRACE", p.Trace},
+	}
+
+	defined := make([]methodOperation, 0, len(all))
+
+	for _, op := range all {
+		if op.operation != nil {
+			defined = append(defined, op)
+		}
+	}
+
+	return defined
+}
+
+func (c *converter) warnf(location, format string, args ...any) {
+	c.warnings = append(c.warnings, location+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *converter) convertOperation(path, method string, item *PathItem, op *Operation) *models.APIModel {
+	location := method + " " + path
+	model := &models.APIModel{
+		Path:        path,
+		Method:      method,
+		QueryParams: []*models.Parameter{},
+		Headers:     []*models.Parameter{},
+		Body:        []*models.Parameter{},
+	}
+
+	for _, param := range c.mergeParameters(location, item.Parameters, op.Parameters) {
+		converted := c.convertSchema(location+" "+param.In+" "+param.Name, param.Name, param.Schema)
+		converted.Required = param.Required || param.In == "path"
+
+		switch param.In {
+		case "path":
+			model.PathParams = append(model.PathParams, converted)
+		case "query":
+			model.QueryParams = append(model.QueryParams, converted)
+		case "header":
+			model.Headers = append(model.Headers, converted)
+		case "cookie":
+			model.Cookies = append(model.Cookies, converted)
+		default:
+			c.warnf(location, "parameter %q in %q is not supported", param.Name, param.In)
+		}
+	}
+
+	model.Headers = append(model.Headers, c.securityHeaders(location, op, model.Headers)...)
+	model.Coerce = coercedSections(model)
+
+	if op.RequestBody != nil {
+		model.Body = c.convertRequestBody(location, op.RequestBody)
+	}
+
+	return model
+}
+
+// mergeParameters resolves path and operation parameters, operation ones overriding by name and location
+func (c *converter) mergeParameters(location string, pathParams, opParams []*Parameter) []*Parameter {
+	var merged []*Parameter
+
+	index := map[string]int{}
+
+	for _, raw := range append(append([]*Parameter{}, pathParams...), opParams...) {
+		param := c.resolveParameter(location, raw)
+		if param == nil {
+			continue
+		}
+
+		key := param.In + "\x00" + param.Name
+		if i, exists := index[key]; exists {
+			merged[i] = param
+			continue
+		}
+
+		index[key] = len(merged)
+		merged = append(merged, param)
+	}
+
+	return merged
+}
+
+func (c *converter) resolveParameter(location string, param *Parameter) *Parameter {
+	if param == nil || param.Ref == "" {
+		return param
+	}
+
+	name, found := strings.CutPrefix(param.Ref, cRefPrefixParameters)
+	if found && c.doc.Components != nil && c.doc.Components.Parameters[name] != nil {
+		return c.doc.Components.Parameters[name]
+	}
+
+	c.warnf(location, "unresolved parameter reference %q", param.Ref)
+
+	return nil
+}
+
+func (c *converter) convertRequestBody(location string, body *RequestBody) []*models.Parameter {
+	if body.Ref != "" {
+		name, found := strings.CutPrefix(body.Ref, cRefPrefixRequestBodies)
+		if !found || c.doc.Components == nil || c.doc.Components.RequestBodies[name] == nil {
+			c.warnf(location, "unresolved request body reference %q", body.Ref)
+			return []*models.Parameter{}
+		}
+
+		body = c.doc.Components.RequestBodies[name]
+	}
+
+	for _, contentType := range requestBodyContentTypes {
+		media, exists := body.Content[contentType]
+		if !exists || media == nil || media.Schema == nil {
+			continue
+		}
+
+		schema := c.resolveSchema(location+" body", media.Schema, map[string]bool{})
+		if schema == nil {
+			return []*models.Parameter{}
+		}
+
+		if !schema.isObject() {
+			c.warnf(location, "request body schema must be an object, found %v", []string(schema.Type))
+			return []*models.Parameter{}
+		}
+
+		return c.convertProperties(location+" body", schema, map[string]bool{})
+	}
+
+	if len(body.Content) > 0 {
+		c.warnf(location, "request body content types %v are not supported", sortedContentTypes(body.Content))
+	}
+
+	return []*models.Parameter{}
+}
+
+// securityHeaders adds the header parameters implied by the security requirements of an operation.
+// Headers that were declared explicitly are left untouched.
+func (c *converter) securityHeaders(location string, op *Operation, declared []*models.Parameter) []*models.Parameter {
+	requirements := op.Security
+	if requirements == nil {
+		requirements = c.doc.Security
+	}
+
+	// Alternative requirements cannot be expressed as required headers, only a single one is translated
+	if len(requirements) != 1 {
+		if len(requirements) > 1 {
+			c.warnf(location, "alternative security requirements are not supported")
+		}
+
+		return nil
+	}
+
+	names := make([]string, 0, len(requirements[0]))
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var headers []*models.Parameter
+
+	for _, name := range names {
+		header := c.securityHeader(location, name)
+		if header == nil || hasParameter(declared, header.Name) || hasParameter(headers, header.Name) {
+			continue
+		}
+
+		headers = append(headers, header)
+	}
+
+	return headers
+}
+
+func (c *converter) securityHeader(location, name string) *models.Parameter {
+	var scheme *SecurityScheme
+	if c.doc.Components != nil {
+		scheme = c.doc.Components.SecuritySchemes[name]
+	}
+
+	switch {
+	case scheme == nil:
+		c.warnf(location, "unknown security scheme %q", name)
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+		return &models.Parameter{Name: cAuthorizationHeader, Types: []models.ParamType{models.TypeAuthToken}, Required: true}
+	case scheme.Type == "apiKey" && scheme.In == "header":
+		return &models.Parameter{Name: scheme.Name, Types: []models.ParamType{models.TypeString}, Required: true}
+	default:
+		c.warnf(location, "security scheme %q of type %q is not supported", name, scheme.Type)
+	}
+
+	return nil
+}
+
+// convertProperties translates the properties of an object schema into parameters sorted by name
+func (c *converter) convertProperties(location string, schema *Schema, visiting map[string]bool) []*models.Parameter {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	params := make([]*models.Parameter, 0, len(names))
+
+	for _, name := range names {
+		param := c.convertSchemaVisiting(location+"."+name, name, schema.Properties[name], visiting)
+		param.Required = required[name]
+		params = append(params, param)
+	}
+
+	return params
+}
+
+func (c *converter) convertSchema(location, name string, schema *Schema) *models.Parameter {
+	return c.convertSchemaVisiting(location, name, schema, map[string]bool{})
+}
+
+// convertSchemaVisiting translates a schema into a parameter. visiting holds the references being expanded,
+// so that recursive schemas are cut instead of expanded forever.
+func (c *converter) convertSchemaVisiting(
+	location, name string, schema *Schema, visiting map[string]bool,
+) *models.Parameter {
+	param := &models.Parameter{Name: name}
+
+	if schema != nil && schema.Ref != "" {
+		if visiting[schema.Ref] {
+			c.warnf(location, "recursive reference %q is not expanded", schema.Ref)
+			param.Types = []models.ParamType{models.TypeObject}
+
+			return param
+		}
+
+		visiting[schema.Ref] = true
+		defer delete(visiting, schema.Ref)
+	}
+
+	schema = c.resolveSchema(location, schema, visiting)
+	if schema == nil {
+		param.Types = []models.ParamType{models.TypeString}
+		return param
+	}
+
+	param.Types = c.convertTypes(location, schema)
+
+	// OpenAPI 3.1 expresses nullable values with a null type, which is kept as a type only when it is the sole one
+	param.Nullable = (schema.Nullable || slices.Contains(schema.Type, "null")) && !slices.Contains(param.Types, models.TypeNull)
+	c.copyConstraints(location, param, schema)
+
+	// Date is only imported from the date format, which holds ISO 8601 dates
+	if slices.Contains(param.Types, models.TypeDate) {
+		param.Formats = []string{dateformat.ISO8601}
+	}
+
+	if schema.isObject() && len(schema.Properties) > 0 {
+		param.Properties = c.convertProperties(location, schema, visiting)
+	}
+
+	if schema.Items != nil && slices.Contains(param.Types, models.TypeList) {
+		param.Items = c.convertSchemaVisiting(location+"[]", "", schema.Items, visiting)
+	}
+
+	return param
+}
+
+// resolveSchema follows a schema reference and flattens allOf compositions
+func (c *converter) resolveSchema(location string, schema *Schema, visiting map[string]bool) *Schema {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		name, found := strings.CutPrefix(schema.Ref, cRefPrefixSchemas)
+		if !found || c.doc.Components == nil || c.doc.Components.Schemas[name] == nil {
+			c.warnf(location, "unresolved schema reference %q", schema.Ref)
+			return nil
+		}
+
+		return c.resolveSchema(location, c.doc.Components.Schemas[name], visiting)
+	}
+
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = map[string]*Schema{}
+
+	for name, property := range schema.Properties {
+		merged.Properties[name] = property
+	}
+
+	for _, part := range schema.AllOf {
+		resolved := c.resolveSchema(location, part, visiting)
+		if resolved == nil {
+			continue
+		}
+
+		if len(merged.Type) == 0 {
+			merged.Type = resolved.Type
+		}
+
+		for name, property := range resolved.Properties {
+			merged.Properties[name] = property
+		}
+
+		merged.Required = append(merged.Required, resolved.Required...)
+	}
+
+	return &merged
+}
+
+// convertTypes maps the schema type and format onto parameter types. oneOf and anyOf become a union of types.
+func (c *converter) convertTypes(location string, schema *Schema) []models.ParamType {
+	alternatives := append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...)
+	if len(alternatives) > 0 {
+		var types []models.ParamType
+
+		for _, alternative := range alternatives {
+			resolved := c.resolveSchema(location, alternative, map[string]bool{})
+			if resolved == nil {
+				continue
+			}
+
+			for _, paramType := range c.convertTypes(location, resolved) {
+				if !slices.Contains(types, paramType) {
+					types = append(types, paramType)
+				}
+			}
+		}
+
+		if len(types) > 0 {
+			return types
+		}
+	}
+
+	if schema.isNull() {
+		return []models.ParamType{models.TypeNull}
+	}
+
+	if len(schema.Type) == 0 {
+		if len(schema.Properties) > 0 {
+			return []models.ParamType{models.TypeObject}
+		}
+
+		c.warnf(location, "schema without a type is imported as String")
+
+		return []models.ParamType{models.TypeString}
+	}
+
+	var types []models.ParamType
+
+	for _, schemaType := range schema.Type {
+		// A null type next to others makes the parameter nullable instead
+		if schemaType == "null" && len(schema.Type) > 1 {
+			continue
+		}
+
+		types = append(types, c.convertType(location, schemaType, schema.Format))
+	}
+
+	if len(types) == 0 {
+		return []models.ParamType{models.TypeString}
+	}
+
+	return types
+}
+
+func (c *converter) convertType(location, schemaType, format string) models.ParamType {
+	switch schemaType {
+	case "integer":
+		return models.TypeInt
+	case "number":
+		if format == "float" || format == "double" {
+			return models.TypeFloat
+		}
+
+		return models.TypeNumber
+	case "null":
+		return models.TypeNull
+	case "boolean":
+		return models.TypeBoolean
+	case "array":
+		return models.TypeList
+	case "object":
+		return models.TypeObject
+	case "string":
+		return c.convertStringFormat(location, format)
+	default:
+		c.warnf(location, "unknown type %q is imported as String", schemaType)
+		return models.TypeString
+	}
+}
+
+func (c *converter) convertStringFormat(location, format string) models.ParamType {
+	switch format {
+	case "":
+		return models.TypeString
+	case "uuid":
+		return models.TypeUUID
+	case "email":
+		return models.TypeEmail
+	case "date":
+		return models.TypeDate
+	case "date-time":
+		return models.TypeTimestamp
+	case "ipv4", "ipv6":
+		c.warnf(location, "format %s is imported as IP, which accepts both IPv4 and IPv6 addresses", format)
+		return models.TypeIP
+	case "uri", "url":
+		return models.TypeURL
+	case "hostname":
+		return models.TypeHostname
+	case "byte":
+		return models.TypeBase64
+	default:
+		c.warnf(location, "format %q is not supported and is imported as String", format)
+		return models.TypeString
+	}
+}
+
+// copyConstraints carries the schema constraints that have an equivalent on parameters
+func (c *converter) copyConstraints(location string, param *models.Parameter, schema *Schema) {
+	param.Minimum = schema.Minimum
+	param.Maximum = schema.Maximum
+	param.Pattern = schema.Pattern
+
+	if !schema.isNull() {
+		param.Enum = schema.Enum
+	}
+
+	param.MinLength = schema.MinLength
+	param.MaxLength = schema.MaxLength
+
+	// Parameter lengths apply to strings and lists alike
+	if schema.MinItems != nil {
+		param.MinLength = schema.MinItems
+	}
+
+	if schema.MaxItems != nil {
+		param.MaxLength = schema.MaxItems
+	}
+
+	if param.Pattern != "" && !isValidPattern(param.Pattern) {
+		c.warnf(location, "pattern %q is not a valid Go regular expression and is ignored", param.Pattern)
+		param.Pattern = ""
+	}
+}
+
+// isNull tells whether a schema only accepts null, which OpenAPI 3.0 spells as a nullable enum of null
+func (s *Schema) isNull() bool {
+	return len(s.Type) == 0 && s.Nullable && len(s.Enum) == 1 && s.Enum[0] == nil
+}
+
+func (s *Schema) isObject() bool {
+	if len(s.Type) == 0 {
+		return len(s.Properties) > 0
+	}
+
+	for _, schemaType := range s.Type {
+		if schemaType == "object" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isValidPattern(pattern string) bool {
+	_, err := regexp.Compile(pattern)
+	return err == nil
+}
+
+// coercedSections lists the sections of a model whose parameters arrive as strings on the wire, so that the
+// types the document declares for them, e.g. an integer query parameter, validate ?limit=10
+func coercedSections(model *models.APIModel) []string {
+	var sections []string
+
+	if len(model.QueryParams) > 0 {
+		sections = append(sections, cSectionQueryParams)
+	}
+
+	if len(model.Headers) > 0 {
+		sections = append(sections, cSectionHeaders)
+	}
+
+	if len(model.Cookies) > 0 {
+		sections = append(sections, cSectionCookies)
+	}
+
+	return sections
+}
+
+func hasParameter(params []*models.Parameter, name string) bool {
+	for _, param := range params {
+		if strings.EqualFold(param.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedContentTypes(content map[string]*MediaType) []string {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
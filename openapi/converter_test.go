@@ -0,0 +1,367 @@
+package openapi
+
+import (
+	"context"
+	"testing"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+	"anomaly_detector/validator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tSpecYAML = `
+openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0
+security:
+  - bearer: []
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+  parameters:
+    UserID:
+      name: user_id
+      in: path
+      required: true
+      schema:
+        type: integer
+        minimum: 1
+  schemas:
+    Address:
+      type: object
+      required: [city]
+      properties:
+        city:
+          type: string
+          maxLength: 50
+    User:
+      type: object
+      required: [email]
+      properties:
+        email:
+          type: string
+          format: email
+        tags:
+          type: array
+          maxItems: 3
+          items:
+            type: string
+            enum: [admin, user]
+        address:
+          $ref: '#/components/schemas/Address'
+paths:
+  /users/{user_id}:
+    parameters:
+      - $ref: '#/components/parameters/UserID'
+    get:
+      parameters:
+        - name: verbose
+          in: query
+          schema:
+            type: boolean
+        - name: session
+          in: cookie
+          schema:
+            type: string
+            format: password
+      responses:
+        200:
+          description: The user
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+`
+
+func TestParse(t *testing.T) {
+	t.Run("parse YAML document", func(t *testing.T) {
+		doc, err := Parse([]byte(tSpecYAML))
+		require.NoError(t, err)
+		assert.Equal(t, "3.0.3", doc.OpenAPI)
+		assert.Contains(t, doc.Paths, "/users/{user_id}")
+	})
+
+	t.Run("parse JSON document with OpenAPI 3.1 type lists", func(t *testing.T) {
+		doc, err := Parse([]byte(`{"openapi": "3.1.0", "paths": {}, "components": {
+			"schemas": {"Name": {"type": ["string", "null"]}}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, SchemaType{"string", "null"}, doc.Components.Schemas["Name"].Type)
+	})
+
+	t.Run("fail on unsupported version", func(t *testing.T) {
+		_, err := Parse([]byte(`{"swagger": "2.0", "paths": {}}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("fail on invalid document", func(t *testing.T) {
+		_, err := Parse([]byte("openapi: [3.0"))
+		assert.Error(t, err)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	doc, err := Parse([]byte(tSpecYAML))
+	require.NoError(t, err)
+
+	result := Convert(doc)
+	require.Len(t, result.Models, 2)
+
+	minimum := 1.0
+	maxCity := 50
+	maxTags := 3
+	tUserID := &models.Parameter{
+		Name: "user_id", Types: []models.ParamType{models.TypeInt}, Required: true, Minimum: &minimum,
+	}
+	tAuthorization := &models.Parameter{
+		Name: "Authorization", Types: []models.ParamType{models.TypeAuthToken}, Required: true,
+	}
+
+	t.Run("convert path, query, header and cookie parameters", func(t *testing.T) {
+		assert.Equal(t, &models.APIModel{
+			Path:       "/users/{user_id}",
+			Method:     "GET",
+			PathParams: []*models.Parameter{tUserID},
+			QueryParams: []*models.Parameter{
+				{Name: "verbose", Types: []models.ParamType{models.TypeBoolean}},
+			},
+			Headers: []*models.Parameter{tAuthorization},
+			Cookies: []*models.Parameter{{Name: "session", Types: []models.ParamType{models.TypeString}}},
+			Body:    []*models.Parameter{},
+			Coerce:  []string{"query_params", "headers", "cookies"},
+		}, result.Models[0])
+	})
+
+	t.Run("convert request body with nested schemas", func(t *testing.T) {
+		model := result.Models[1]
+		assert.Equal(t, "PUT", model.Method)
+		assert.Equal(t, []*models.Parameter{
+			{
+				Name:  "address",
+				Types: []models.ParamType{models.TypeObject},
+				Properties: []*models.Parameter{
+					{Name: "city", Types: []models.ParamType{models.TypeString}, Required: true, MaxLength: &maxCity},
+				},
+			},
+			{Name: "email", Types: []models.ParamType{models.TypeEmail}, Required: true},
+			{
+				Name:      "tags",
+				Types:     []models.ParamType{models.TypeList},
+				MaxLength: &maxTags,
+				Items:     &models.Parameter{Types: []models.ParamType{models.TypeString}, Enum: []any{"admin", "user"}},
+			},
+		}, model.Body)
+	})
+
+	t.Run("report untranslated constructs", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`GET /users/{user_id} cookie session: format "password" is not supported and is imported as String`,
+		}, result.Warnings)
+	})
+}
+
+func TestConvertDates(t *testing.T) {
+	doc, err := Parse([]byte(`
+openapi: 3.0.3
+info:
+  title: Events
+  version: 1.0
+paths:
+  /events:
+    get:
+      parameters:
+        - name: since
+          in: query
+          required: true
+          schema:
+            type: string
+            format: date
+      responses:
+        200:
+          description: The events
+`))
+	require.NoError(t, err)
+
+	result := Convert(doc)
+	require.Len(t, result.Models, 1)
+
+	tValidator := validator.NewRequestValidator()
+	validate := func(since string) *models.ValidationResult {
+		return tValidator.Validate(context.Background(), &models.Request{
+			Path:        "/events",
+			Method:      "GET",
+			QueryParams: []*models.RequestParam{{Name: "since", Value: since}},
+		}, result.Models[0])
+	}
+
+	t.Run("accept ISO 8601 dates", func(t *testing.T) {
+		tResult := validate("2024-03-15")
+		assert.True(t, tResult.Valid)
+		assert.Empty(t, tResult.Anomalies)
+	})
+
+	t.Run("reject other date layouts", func(t *testing.T) {
+		tResult := validate("15-03-2024")
+		assert.False(t, tResult.Valid)
+		assert.NotEmpty(t, tResult.Anomalies)
+	})
+}
+
+func TestConvertCoercion(t *testing.T) {
+	doc, err := Parse([]byte(`
+openapi: 3.0.3
+info:
+  title: Events
+  version: 1.0
+paths:
+  /events:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: X-Page
+          in: header
+          schema:
+            type: integer
+      responses:
+        200:
+          description: The events
+`))
+	require.NoError(t, err)
+
+	result := Convert(doc)
+	require.Len(t, result.Models, 1)
+	assert.Equal(t, []string{"query_params", "headers"}, result.Models[0].Coerce)
+
+	tResult := validator.NewRequestValidator().Validate(context.Background(), &models.Request{
+		Path:        "/events",
+		Method:      "GET",
+		QueryParams: []*models.RequestParam{{Name: "limit", Value: "10"}},
+		Headers:     []*models.RequestParam{{Name: "X-Page", Value: "2"}},
+	}, result.Models[0])
+	assert.True(t, tResult.Valid)
+	assert.Empty(t, tResult.Anomalies)
+}
+
+func TestConvertSchemas(t *testing.T) {
+	convert := func(t *testing.T, schema *Schema, components map[string]*Schema) (*models.Parameter, []string) {
+		t.Helper()
+
+		c := &converter{doc: &Document{Components: &Components{Schemas: components}}}
+
+		return c.convertSchema("location", "field", schema), c.warnings
+	}
+
+	t.Run("map string formats", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Type: SchemaType{"string"}, Format: "uuid"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeUUID}, param.Types)
+		assert.Empty(t, warnings)
+
+		formats := map[string]models.ParamType{
+			"date-time": models.TypeTimestamp,
+			"uri":       models.TypeURL,
+			"hostname":  models.TypeHostname,
+			"byte":      models.TypeBase64,
+		}
+
+		for format, paramType := range formats {
+			param, warnings = convert(t, &Schema{Type: SchemaType{"string"}, Format: format}, nil)
+			assert.Equal(t, []models.ParamType{paramType}, param.Types, format)
+			assert.Empty(t, warnings, format)
+		}
+
+		param, warnings = convert(t, &Schema{Type: SchemaType{"string"}, Format: "date"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeDate}, param.Types)
+		assert.Equal(t, []string{dateformat.ISO8601}, param.Formats)
+		assert.Empty(t, warnings)
+
+		// IP accepts both address families, so the import is looser than the schema
+		param, warnings = convert(t, &Schema{Type: SchemaType{"string"}, Format: "ipv4"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeIP}, param.Types)
+		assert.Len(t, warnings, 1)
+
+		param, warnings = convert(t, &Schema{Type: SchemaType{"string"}, Format: "password"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeString}, param.Types)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("map numbers and nulls", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Type: SchemaType{"number"}}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeNumber}, param.Types)
+		assert.Empty(t, warnings)
+
+		param, _ = convert(t, &Schema{Type: SchemaType{"number"}, Format: "float"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeFloat}, param.Types)
+
+		param, _ = convert(t, &Schema{Type: SchemaType{"null"}}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeNull}, param.Types)
+		assert.False(t, param.Nullable)
+	})
+
+	t.Run("nullable schemas", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Type: SchemaType{"integer"}, Nullable: true}, nil)
+		assert.Equal(t, &models.Parameter{Name: "field", Types: []models.ParamType{models.TypeInt}, Nullable: true}, param)
+		assert.Empty(t, warnings)
+
+		// OpenAPI 3.1 type lists
+		param, warnings = convert(t, &Schema{Type: SchemaType{"string", "null"}}, nil)
+		assert.Equal(t, &models.Parameter{Name: "field", Types: []models.ParamType{models.TypeString}, Nullable: true}, param)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("union of oneOf alternatives", func(t *testing.T) {
+		param, _ := convert(t, &Schema{OneOf: []*Schema{
+			{Type: SchemaType{"integer"}}, {Type: SchemaType{"string"}}, {Type: SchemaType{"integer"}},
+		}}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeInt, models.TypeString}, param.Types)
+	})
+
+	t.Run("merge allOf properties", func(t *testing.T) {
+		param, _ := convert(t, &Schema{AllOf: []*Schema{
+			{Ref: "#/components/schemas/Base"},
+			{Type: SchemaType{"object"}, Properties: map[string]*Schema{"b": {Type: SchemaType{"boolean"}}}},
+		}}, map[string]*Schema{
+			"Base": {Type: SchemaType{"object"}, Required: []string{"a"}, Properties: map[string]*Schema{
+				"a": {Type: SchemaType{"integer"}},
+			}},
+		})
+		assert.Equal(t, []models.ParamType{models.TypeObject}, param.Types)
+		assert.Equal(t, []*models.Parameter{
+			{Name: "a", Types: []models.ParamType{models.TypeInt}, Required: true},
+			{Name: "b", Types: []models.ParamType{models.TypeBoolean}},
+		}, param.Properties)
+	})
+
+	t.Run("cut recursive references", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Ref: "#/components/schemas/Node"}, map[string]*Schema{
+			"Node": {Type: SchemaType{"object"}, Properties: map[string]*Schema{
+				"child": {Ref: "#/components/schemas/Node"},
+			}},
+		})
+		require.Len(t, param.Properties, 1)
+		assert.Equal(t, []models.ParamType{models.TypeObject}, param.Properties[0].Types)
+		assert.Nil(t, param.Properties[0].Properties)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("drop patterns Go cannot compile", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Type: SchemaType{"string"}, Pattern: `^(?!admin)`}, nil)
+		assert.Empty(t, param.Pattern)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("warn on unresolved reference", func(t *testing.T) {
+		param, warnings := convert(t, &Schema{Ref: "#/components/schemas/Missing"}, nil)
+		assert.Equal(t, []models.ParamType{models.TypeString}, param.Types)
+		assert.Len(t, warnings, 1)
+	})
+}
@@ -0,0 +1,316 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+	"anomaly_detector/pathtemplate"
+	"anomaly_detector/validator"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	cExportVersion = "3.0.3"
+	cExportTitle   = "Anomaly Detector models"
+
+	cYAMLIndent = 2
+)
+
+// Export renders every model as an OpenAPI 3 operation. Models whose method has no OpenAPI operation are skipped
+// and reported as warnings.
+func Export(apiModels []*models.APIModel) (*Document, []string) {
+	doc := &Document{
+		OpenAPI: cExportVersion,
+		Info:    map[string]any{"title": cExportTitle, "version": "1.0.0"},
+		Paths:   map[string]*PathItem{},
+	}
+
+	var warnings []string
+
+	for _, model := range apiModels {
+		item, exists := doc.Paths[model.Path]
+		if !exists {
+			item = &PathItem{}
+		}
+
+		slot := item.operationSlot(model.Method)
+		if slot == nil {
+			warnings = append(warnings, fmt.Sprintf("%s %s: method has no OpenAPI operation", model.Method, model.Path))
+			continue
+		}
+
+		*slot = exportOperation(model)
+		doc.Paths[model.Path] = item
+	}
+
+	return doc, warnings
+}
+
+// operationSlot returns the field holding the operation of a method, or nil if OpenAPI has no such operation
+func (p *PathItem) operationSlot(method string) **Operation {
+	slots := map[string]**Operation{
+		"GET": &p.Get, "PUT": &p.Put, "POST": &p.Post, "DELETE": &p.Delete,
+		"OPTIONS": &p.Options, "HEAD": &p.Head, "PATCH": &p.Patch, "TRACE": &p.Trace,
+	}
+
+	return slots[strings.ToUpper(method)]
+}
+
+func exportOperation(model *models.APIModel) *Operation {
+	op := &Operation{
+		Responses: map[string]any{"default": map[string]any{"description": "Any response"}},
+	}
+
+	declared := map[string]bool{}
+
+	for _, param := range model.PathParams {
+		declared[param.Name] = true
+		op.Parameters = append(op.Parameters, exportParameter("path", param))
+	}
+
+	// OpenAPI requires every template variable to be declared, undeclared ones accept any segment
+	for _, name := range pathtemplate.Params(model.Path) {
+		if !declared[name] {
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: SchemaType{"string"}},
+			})
+		}
+	}
+
+	for _, param := range model.QueryParams {
+		op.Parameters = append(op.Parameters, exportParameter("query", param))
+	}
+
+	for _, param := range model.Headers {
+		op.Parameters = append(op.Parameters, exportParameter("header", param))
+	}
+
+	for _, param := range model.Cookies {
+		op.Parameters = append(op.Parameters, exportParameter("cookie", param))
+	}
+
+	if len(model.Body) > 0 {
+		body := exportObject(model.Body)
+		op.RequestBody = &RequestBody{
+			Required: len(body.Required) > 0,
+			Content:  map[string]*MediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	return op
+}
+
+func exportParameter(in string, param *models.Parameter) *Parameter {
+	return &Parameter{
+		Name:     param.Name,
+		In:       in,
+		Required: param.Required || in == "path",
+		Schema:   exportSchema(param),
+	}
+}
+
+// exportObject renders parameters as the properties of an object schema
+func exportObject(params []*models.Parameter) *Schema {
+	schema := &Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{}}
+
+	for _, param := range params {
+		schema.Properties[param.Name] = exportSchema(param)
+
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+
+	return schema
+}
+
+// exportSchema renders the types and constraints of a parameter. Several types become a oneOf of their schemas,
+// with the constraints applying to the value whichever type it matched.
+func exportSchema(param *models.Parameter) *Schema {
+	constraints := exportConstraints(param)
+
+	if len(param.Types) != 1 {
+		alternatives := make([]*Schema, 0, len(param.Types))
+		for _, paramType := range param.Types {
+			alternatives = append(alternatives, exportType(param, paramType))
+		}
+
+		constraints.OneOf = alternatives
+
+		return constraints
+	}
+
+	schema := exportType(param, param.Types[0])
+
+	// Both the type and the parameter define a pattern, which a single schema cannot express
+	if schema.Pattern != "" && constraints.Pattern != "" {
+		return &Schema{AllOf: []*Schema{schema, constraints}}
+	}
+
+	if constraints.Pattern != "" {
+		schema.Pattern = constraints.Pattern
+	}
+
+	if constraints.Enum != nil {
+		schema.Enum = constraints.Enum
+	}
+
+	schema.Minimum, schema.Maximum = constraints.Minimum, constraints.Maximum
+	schema.Nullable = schema.Nullable || constraints.Nullable
+
+	if len(schema.Type) == 1 && schema.Type[0] == "array" {
+		schema.MinItems, schema.MaxItems = param.MinLength, param.MaxLength
+	} else {
+		schema.MinLength, schema.MaxLength = param.MinLength, param.MaxLength
+	}
+
+	return schema
+}
+
+func exportConstraints(param *models.Parameter) *Schema {
+	return &Schema{
+		Minimum:   param.Minimum,
+		Maximum:   param.Maximum,
+		MinLength: param.MinLength,
+		MaxLength: param.MaxLength,
+		Pattern:   param.Pattern,
+		Enum:      param.Enum,
+		Nullable:  param.Nullable,
+	}
+}
+
+// exportType maps a parameter type onto a schema type and format. Types without a standard format carry
+// the pattern the validator checks them with.
+func exportType(param *models.Parameter, paramType models.ParamType) *Schema {
+	if len(param.Formats) > 0 && models.IsTimeType(paramType) {
+		return exportTimeFormats(param.Formats)
+	}
+
+	switch paramType {
+	case models.TypeInt:
+		return &Schema{Type: SchemaType{"integer"}}
+	case models.TypeNumber:
+		return &Schema{Type: SchemaType{"number"}}
+	case models.TypeFloat:
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case models.TypeNull:
+		// OpenAPI 3.0 has no null type, only nullable schemas
+		return &Schema{Nullable: true, Enum: []any{nil}}
+	case models.TypeBoolean:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case models.TypeEmail:
+		return &Schema{Type: SchemaType{"string"}, Format: "email"}
+	case models.TypeUUID:
+		return &Schema{Type: SchemaType{"string"}, Format: "uuid"}
+	case models.TypeDate:
+		return exportTimeFormat(dateformat.DDMMYYYY)
+	case models.TypeAuthToken:
+		return &Schema{Type: SchemaType{"string"}, Pattern: validator.AuthTokenPattern}
+	case models.TypeIP:
+		return &Schema{Type: SchemaType{"string"}, AnyOf: []*Schema{{Format: "ipv4"}, {Format: "ipv6"}}}
+	case models.TypeURL:
+		return &Schema{Type: SchemaType{"string"}, Format: "uri"}
+	case models.TypeHostname:
+		return &Schema{Type: SchemaType{"string"}, Format: "hostname"}
+	case models.TypeTimestamp:
+		return exportTimeFormat(dateformat.RFC3339)
+	case models.TypeBase64:
+		return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+	case models.TypePhone:
+		return &Schema{Type: SchemaType{"string"}, Pattern: validator.PhonePattern}
+	case models.TypeJWT:
+		return &Schema{Type: SchemaType{"string"}, Pattern: validator.JWTPattern}
+	case models.TypeList:
+		schema := &Schema{Type: SchemaType{"array"}, Items: &Schema{}}
+		if param.Items != nil {
+			schema.Items = exportSchema(param.Items)
+		}
+
+		return schema
+	case models.TypeObject:
+		schema := exportObject(param.Properties)
+		if len(schema.Properties) == 0 {
+			schema.Properties = nil
+		}
+
+		return schema
+	default:
+		return &Schema{Type: SchemaType{"string"}}
+	}
+}
+
+// exportTimeFormats renders the formats of a Date or Timestamp parameter, any of which a value may match
+func exportTimeFormats(formats []string) *Schema {
+	if len(formats) == 1 {
+		return exportTimeFormat(formats[0])
+	}
+
+	alternatives := make([]*Schema, 0, len(formats))
+	for _, format := range formats {
+		alternatives = append(alternatives, exportTimeFormat(format))
+	}
+
+	return &Schema{AnyOf: alternatives}
+}
+
+// exportTimeFormat maps a date format onto a schema. Formats without a standard equivalent, such as
+// RFC 1123 dates or Go layouts, become a plain string.
+func exportTimeFormat(format string) *Schema {
+	switch format {
+	case dateformat.ISO8601:
+		return &Schema{Type: SchemaType{"string"}, Format: "date"}
+	case dateformat.RFC3339:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case dateformat.DDMMYYYY:
+		return &Schema{Type: SchemaType{"string"}, Pattern: validator.DatePattern}
+	case dateformat.Unix, dateformat.UnixMilli:
+		return &Schema{Type: SchemaType{"integer"}}
+	default:
+		return &Schema{Type: SchemaType{"string"}}
+	}
+}
+
+// MarshalYAML renders a document as YAML, keeping the field order of its JSON form
+func MarshalYAML(doc *Document) ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	// JSON is valid YAML, decoding it into a node keeps the key order that a map would lose
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert OpenAPI document: %w", err)
+	}
+
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(cYAMLIndent)
+
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resetStyle switches the flow style and quoting inherited from JSON to the default block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
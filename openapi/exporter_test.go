@@ -0,0 +1,170 @@
+package openapi
+
+import (
+	"testing"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+	"anomaly_detector/validator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	minimum := 1.0
+	maxTags := 3
+	tModels := []*models.APIModel{
+		{
+			Path:   "/users/{user_id}/orders/{order_id}",
+			Method: "GET",
+			PathParams: []*models.Parameter{
+				{Name: "user_id", Types: []models.ParamType{models.TypeInt}, Required: true, Minimum: &minimum},
+			},
+			QueryParams: []*models.Parameter{
+				{Name: "since", Types: []models.ParamType{models.TypeDate}},
+				{Name: "ref", Types: []models.ParamType{models.TypeInt, models.TypeUUID}, Required: true},
+			},
+			Headers: []*models.Parameter{
+				{Name: "Authorization", Types: []models.ParamType{models.TypeAuthToken}, Required: true},
+			},
+			Cookies: []*models.Parameter{
+				{Name: "session", Types: []models.ParamType{models.TypeString}, Required: true},
+			},
+		},
+		{
+			Path:   "/users",
+			Method: "post",
+			Body: []*models.Parameter{
+				{Name: "deleted_at", Types: []models.ParamType{models.TypeNull}},
+				{Name: "email", Types: []models.ParamType{models.TypeEmail}, Required: true},
+				{Name: "price", Types: []models.ParamType{models.TypeFloat}, Required: true, Nullable: true},
+				{
+					Name: "tags", Types: []models.ParamType{models.TypeList}, MaxLength: &maxTags,
+					Items: &models.Parameter{Types: []models.ParamType{models.TypeString}, Enum: []any{"admin"}},
+				},
+			},
+		},
+		{Path: "/users", Method: "PURGE"},
+	}
+
+	doc, warnings := Export(tModels)
+
+	t.Run("export parameters", func(t *testing.T) {
+		op := doc.Paths["/users/{user_id}/orders/{order_id}"].Get
+		require.NotNil(t, op)
+		assert.Equal(t, []*Parameter{
+			{Name: "user_id", In: "path", Required: true, Schema: &Schema{Type: SchemaType{"integer"}, Minimum: &minimum}},
+			{Name: "order_id", In: "path", Required: true, Schema: &Schema{Type: SchemaType{"string"}}},
+			{Name: "since", In: "query", Schema: &Schema{Type: SchemaType{"string"}, Pattern: validator.DatePattern}},
+			{Name: "ref", In: "query", Required: true, Schema: &Schema{OneOf: []*Schema{
+				{Type: SchemaType{"integer"}}, {Type: SchemaType{"string"}, Format: "uuid"},
+			}}},
+			{Name: "Authorization", In: "header", Required: true, Schema: &Schema{
+				Type: SchemaType{"string"}, Pattern: validator.AuthTokenPattern,
+			}},
+			{Name: "session", In: "cookie", Required: true, Schema: &Schema{Type: SchemaType{"string"}}},
+		}, op.Parameters)
+		assert.Nil(t, op.RequestBody)
+	})
+
+	t.Run("export body as a JSON object", func(t *testing.T) {
+		op := doc.Paths["/users"].Post
+		require.NotNil(t, op)
+		require.NotNil(t, op.RequestBody)
+		assert.True(t, op.RequestBody.Required)
+		assert.Equal(t, &Schema{
+			Type:     SchemaType{"object"},
+			Required: []string{"email", "price"},
+			Properties: map[string]*Schema{
+				"deleted_at": {Nullable: true, Enum: []any{nil}},
+				"email":      {Type: SchemaType{"string"}, Format: "email"},
+				"price":      {Type: SchemaType{"number"}, Format: "double", Nullable: true},
+				"tags": {
+					Type: SchemaType{"array"}, MaxItems: &maxTags,
+					Items: &Schema{Type: SchemaType{"string"}, Enum: []any{"admin"}},
+				},
+			},
+		}, op.RequestBody.Content["application/json"].Schema)
+	})
+
+	t.Run("skip methods without an OpenAPI operation", func(t *testing.T) {
+		assert.Equal(t, []string{"PURGE /users: method has no OpenAPI operation"}, warnings)
+	})
+
+	t.Run("round trip through import", func(t *testing.T) {
+		result := Convert(doc)
+		require.Len(t, result.Models, 2)
+		assert.Equal(t, tModels[1].Body, result.Models[0].Body)
+	})
+}
+
+func TestExportTypes(t *testing.T) {
+	tests := map[models.ParamType]*Schema{
+		models.TypeIP:        {Type: SchemaType{"string"}, AnyOf: []*Schema{{Format: "ipv4"}, {Format: "ipv6"}}},
+		models.TypeCIDR:      {Type: SchemaType{"string"}},
+		models.TypeURL:       {Type: SchemaType{"string"}, Format: "uri"},
+		models.TypeHostname:  {Type: SchemaType{"string"}, Format: "hostname"},
+		models.TypePhone:     {Type: SchemaType{"string"}, Pattern: validator.PhonePattern},
+		models.TypeTimestamp: {Type: SchemaType{"string"}, Format: "date-time"},
+		models.TypeBase64:    {Type: SchemaType{"string"}, Format: "byte"},
+		models.TypeJWT:       {Type: SchemaType{"string"}, Pattern: validator.JWTPattern},
+		models.TypeNumber:    {Type: SchemaType{"number"}},
+		models.TypeFloat:     {Type: SchemaType{"number"}, Format: "double"},
+		models.TypeNull:      {Nullable: true, Enum: []any{nil}},
+	}
+
+	for paramType, expected := range tests {
+		t.Run(string(paramType), func(t *testing.T) {
+			assert.Equal(t, expected, exportType(&models.Parameter{}, paramType))
+		})
+	}
+}
+
+func TestExportTimeFormats(t *testing.T) {
+	tests := map[string]struct {
+		param    *models.Parameter
+		expected *Schema
+	}{
+		"iso date": {
+			param:    &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{dateformat.ISO8601}},
+			expected: &Schema{Type: SchemaType{"string"}, Format: "date"},
+		},
+		"several formats": {
+			param: &models.Parameter{
+				Types: []models.ParamType{models.TypeTimestamp}, Formats: []string{dateformat.RFC3339, dateformat.Unix},
+			},
+			expected: &Schema{AnyOf: []*Schema{
+				{Type: SchemaType{"string"}, Format: "date-time"}, {Type: SchemaType{"integer"}},
+			}},
+		},
+		"go layout": {
+			param:    &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{"2006/01/02"}},
+			expected: &Schema{Type: SchemaType{"string"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, exportType(tc.param, tc.param.Types[0]))
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	doc, _ := Export([]*models.APIModel{
+		{Path: "/users", Method: "GET", QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+		}},
+	})
+
+	data, err := MarshalYAML(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "openapi: 3.0.3\n")
+	assert.Contains(t, string(data), "  version: 1.0.0\n")
+	assert.Contains(t, string(data), "  /users:\n    get:\n")
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, doc, parsed)
+}
@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"anomaly_detector/api"
+	"anomaly_detector/store"
+)
+
+const (
+	cQueryDryRun = "dry_run"
+	cQueryFormat = "format"
+
+	cFormatJSON = "json"
+	cFormatYAML = "yaml"
+
+	cContentTypeYAML = "application/yaml"
+
+	cMaxSpecSize = 10 * 1024 * 1024
+)
+
+type IOpenAPIHandler interface {
+	api.IHandler
+	HandleExport(w http.ResponseWriter, r *http.Request)
+}
+
+type openAPIHandler struct {
+	store store.IModelStore
+}
+
+func NewOpenAPIHandler(store store.IModelStore) IOpenAPIHandler {
+	return &openAPIHandler{store: store}
+}
+
+// Handle imports the operations of an OpenAPI 3 document, given as JSON or YAML, as models.
+// All models are stored in a single StoreAll call, so an import is applied entirely or not at all.
+// With dry_run=true the translated models are returned without being stored.
+func (h *openAPIHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cMaxSpecSize))
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "failed to read OpenAPI document")
+		return
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := Convert(doc)
+
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get(cQueryDryRun)); dryRun {
+		api.RespondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	ok, err := h.store.StoreAll(ctx, result.Models)
+	if err != nil {
+		if !ok {
+			slog.ErrorContext(ctx, "error storing imported models", "error", err)
+			api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+			return
+		}
+
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	response := map[string]any{
+		"message":  "models imported successfully",
+		"imported": len(result.Models),
+		"warnings": result.Warnings,
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleExport renders every stored model as an OpenAPI 3 document. The format query parameter (json or yaml)
+// selects the output, defaulting to YAML when the Accept header asks for it and to JSON otherwise.
+func (h *openAPIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, ok := exportFormat(r)
+	if !ok {
+		api.RespondError(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
+
+	apiModels, err := h.store.List(ctx, "", "")
+	if err != nil {
+		slog.ErrorContext(ctx, "error listing models", "error", err)
+		api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	doc, warnings := Export(apiModels)
+	for _, warning := range warnings {
+		slog.WarnContext(ctx, "model skipped from OpenAPI export", "reason", warning)
+	}
+
+	if format == cFormatJSON {
+		api.RespondJSON(w, http.StatusOK, doc)
+		return
+	}
+
+	data, err := MarshalYAML(doc)
+	if err != nil {
+		slog.ErrorContext(ctx, "error encoding OpenAPI document", "error", err)
+		api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	w.Header().Set("Content-Type", cContentTypeYAML)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		slog.ErrorContext(ctx, "failed to write OpenAPI document", "error", err)
+	}
+}
+
+// exportFormat resolves the requested export format, reporting false for an unknown format
+func exportFormat(r *http.Request) (string, bool) {
+	switch format := strings.ToLower(r.URL.Query().Get(cQueryFormat)); format {
+	case cFormatJSON, cFormatYAML:
+		return format, true
+	case "":
+		if strings.Contains(r.Header.Get("Accept"), "yaml") {
+			return cFormatYAML, true
+		}
+
+		return cFormatJSON, true
+	default:
+		return "", false
+	}
+}
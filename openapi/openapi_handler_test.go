@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	tImportPath = "/models/import/openapi"
+	tExportPath = "/models/export/openapi"
+)
+
+func TestImportHandler(t *testing.T) {
+	tStoreMock := store.NewMockIModelStore(t)
+
+	tHandler := &openAPIHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("import stores all models at once", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tImportPath, strings.NewReader(tSpecYAML))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			StoreAll(mock.Anything, mock.MatchedBy(func(apiModels []*models.APIModel) bool {
+				return len(apiModels) == 2
+			})).
+			Return(true, nil).Once()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response map[string]any
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(2), response["imported"])
+		assert.Len(t, response["warnings"], 1)
+	})
+
+	t.Run("dry run does not store models", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tImportPath+"?dry_run=true", strings.NewReader(tSpecYAML))
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var result ImportResult
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Len(t, result.Models, 2)
+	})
+
+	t.Run("error with invalid document", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tImportPath, strings.NewReader(`{"openapi": "2.0"}`))
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("error with rejected models", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tImportPath, strings.NewReader(tSpecYAML))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			StoreAll(mock.Anything, mock.Anything).
+			Return(true, errors.New("model already exists: GET /users/{user_id}")).Once()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("internal error storing models", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tImportPath, strings.NewReader(tSpecYAML))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			StoreAll(mock.Anything, mock.Anything).
+			Return(false, errors.New("disk full")).Once()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusInternalServerError, tRecorder.Code)
+	})
+}
+
+func TestExportHandler(t *testing.T) {
+	tStoreMock := store.NewMockIModelStore(t)
+
+	tHandler := &openAPIHandler{
+		store: tStoreMock,
+	}
+
+	tModels := []*models.APIModel{
+		{Path: "/users", Method: "GET", QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+		}},
+	}
+
+	t.Run("export JSON by default", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tExportPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().List(mock.Anything, "", "").Return(tModels, nil).Once()
+
+		tHandler.HandleExport(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, "application/json", tRecorder.Header().Get("Content-Type"))
+
+		doc, err := Parse(tRecorder.Body.Bytes())
+		assert.NoError(t, err)
+		assert.NotNil(t, doc.Paths["/users"].Get)
+	})
+
+	t.Run("export YAML", func(t *testing.T) {
+		for _, setup := range []func(r *http.Request){
+			func(r *http.Request) { r.URL.RawQuery = "format=yaml" },
+			func(r *http.Request) { r.Header.Set("Accept", "application/yaml") },
+		} {
+			httpRequest := httptest.NewRequest(http.MethodGet, tExportPath, nil)
+			setup(httpRequest)
+
+			tRecorder := httptest.NewRecorder()
+
+			tStoreMock.EXPECT().List(mock.Anything, "", "").Return(tModels, nil).Once()
+
+			tHandler.HandleExport(tRecorder, httpRequest)
+
+			assert.Equal(t, http.StatusOK, tRecorder.Code)
+			assert.Equal(t, cContentTypeYAML, tRecorder.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(tRecorder.Body.String(), "openapi: 3.0.3\n"))
+		}
+	})
+
+	t.Run("error with unknown format", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tExportPath+"?format=xml", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleExport(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("internal error listing models", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tExportPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().List(mock.Anything, "", "").Return(nil, errors.New("boom")).Once()
+
+		tHandler.HandleExport(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusInternalServerError, tRecorder.Code)
+	})
+}
@@ -0,0 +1,91 @@
+package pathtemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segments splits a path into its non-empty segments, ignoring leading and trailing slashes
+func Segments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+
+	return strings.Split(trimmed, "/")
+}
+
+// ParamName returns the variable name of a template segment such as {user_id}
+func ParamName(segment string) (string, bool) {
+	if len(segment) < 2 || segment[0] != '{' || segment[len(segment)-1] != '}' {
+		return "", false
+	}
+
+	return segment[1 : len(segment)-1], true
+}
+
+// Params returns the variable names of a template in the order they appear
+func Params(template string) []string {
+	var names []string
+
+	for _, segment := range Segments(template) {
+		if name, ok := ParamName(segment); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Validate checks that every variable of a template is well-formed and unique
+func Validate(template string) error {
+	seen := make(map[string]struct{})
+
+	for _, segment := range Segments(template) {
+		name, ok := ParamName(segment)
+		if !ok {
+			if strings.ContainsAny(segment, "{}") {
+				return fmt.Errorf("invalid path segment %q in template %s", segment, template)
+			}
+
+			continue
+		}
+
+		if name == "" || strings.ContainsAny(name, "{}") {
+			return fmt.Errorf("invalid path parameter %q in template %s", segment, template)
+		}
+
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("duplicate path parameter %q in template %s", name, template)
+		}
+
+		seen[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// Extract matches a concrete path against a template and returns the value of each template variable
+func Extract(template, path string) (map[string]string, bool) {
+	templateSegments := Segments(template)
+	pathSegments := Segments(path)
+
+	if len(templateSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+
+	for i, segment := range templateSegments {
+		if name, ok := ParamName(segment); ok {
+			values[name] = pathSegments[i]
+			continue
+		}
+
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
@@ -0,0 +1,58 @@
+package pathtemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		isValid  bool
+	}{
+		{name: "literal path", template: "/users/info", isValid: true},
+		{name: "single variable", template: "/users/{user_id}", isValid: true},
+		{name: "multiple variables", template: "/users/{user_id}/orders/{order_id}", isValid: true},
+		{name: "empty variable name", template: "/users/{}", isValid: false},
+		{name: "partial variable segment", template: "/users/id-{user_id}", isValid: false},
+		{name: "duplicate variable", template: "/users/{id}/orders/{id}", isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.template)
+			assert.Equal(t, tc.isValid, err == nil)
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("extracts variables", func(t *testing.T) {
+		values, ok := Extract("/users/{user_id}/orders/{order_id}", "/users/42/orders/abc")
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"user_id": "42", "order_id": "abc"}, values)
+	})
+
+	t.Run("ignores trailing slash", func(t *testing.T) {
+		values, ok := Extract("/users/{user_id}", "/users/42/")
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"user_id": "42"}, values)
+	})
+
+	t.Run("literal mismatch", func(t *testing.T) {
+		_, ok := Extract("/users/{user_id}/orders", "/users/42/items")
+		assert.False(t, ok)
+	})
+
+	t.Run("segment count mismatch", func(t *testing.T) {
+		_, ok := Extract("/users/{user_id}", "/users/42/orders")
+		assert.False(t, ok)
+	})
+}
+
+func TestParams(t *testing.T) {
+	assert.Equal(t, []string{"user_id", "order_id"}, Params("/users/{user_id}/orders/{order_id}"))
+	assert.Empty(t, Params("/users/info"))
+}
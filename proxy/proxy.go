@@ -0,0 +1,269 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"anomaly_detector/api"
+	"anomaly_detector/capture"
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/tenant"
+	"anomaly_detector/validator"
+)
+
+// Headers of the annotated requests forwarded upstream. They are removed from every incoming request,
+// so that clients cannot forge them.
+const (
+	HeaderRiskScore = "X-Anomaly-Risk-Score"
+	HeaderCodes     = "X-Anomaly-Codes"
+	HeaderError     = "X-Anomaly-Error"
+)
+
+const (
+	// cHeaderServerTiming reports the validation overhead of every proxied request to the client
+	cHeaderServerTiming = "Server-Timing"
+	cServerTimingMetric = "anomaly-detector"
+
+	cBlockedMessage      = "request blocked"
+	cUnparsableMessage   = "request could not be parsed"
+	cUpstreamUnavailable = "upstream unavailable"
+)
+
+type IProxy interface {
+	http.Handler
+	HandleStats(w http.ResponseWriter, r *http.Request)
+}
+
+type proxy struct {
+	store        store.IModelStore
+	validator    validator.IRequestValidator
+	reverseProxy *httputil.ReverseProxy
+
+	// tenant whose models validate the proxied traffic, clients cannot choose it
+	tenant             string
+	defaultEnforcement *models.Enforcement
+	blockStatus        int
+
+	stats *overheadStats
+}
+
+// NewProxy creates the validating reverse proxy to PROXY_UPSTREAM, or returns nil when no upstream is configured
+func NewProxy(cfg *config.InitConfig, modelStore store.IModelStore) (IProxy, error) {
+	if cfg.ProxyUpstream == "" {
+		return nil, nil
+	}
+
+	upstream, err := url.Parse(cfg.ProxyUpstream)
+	if err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid proxy upstream %q: expected an http or https URL", cfg.ProxyUpstream)
+	}
+
+	if !strings.HasPrefix(cfg.ProxyAPIPrefix, "/") || strings.TrimRight(cfg.ProxyAPIPrefix, "/") == "" {
+		return nil, fmt.Errorf("invalid proxy API prefix %q: expected a path below /", cfg.ProxyAPIPrefix)
+	}
+
+	if err := tenant.Validate(cfg.ProxyTenant); err != nil {
+		return nil, fmt.Errorf("invalid proxy tenant: %w", err)
+	}
+
+	action := models.EnforcementAction(cfg.ProxyDefaultAction)
+	if !action.IsValid() {
+		return nil, fmt.Errorf("invalid proxy default action %q", cfg.ProxyDefaultAction)
+	}
+
+	if !isErrorStatus(cfg.ProxyBlockStatus) {
+		return nil, fmt.Errorf("invalid proxy block status %d: expected a 4xx or 5xx status", cfg.ProxyBlockStatus)
+	}
+
+	requestValidator, err := validator.NewConfiguredRequestValidator(cfg, modelStore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator configuration: %w", err)
+	}
+
+	return &proxy{
+		store:              modelStore,
+		validator:          requestValidator,
+		reverseProxy:       newReverseProxy(upstream),
+		tenant:             cfg.ProxyTenant,
+		defaultEnforcement: &models.Enforcement{Action: action},
+		blockStatus:        cfg.ProxyBlockStatus,
+		stats:              newOverheadStats(),
+	}, nil
+}
+
+func newReverseProxy(upstream *url.URL) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(upstream)
+			pr.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.ErrorContext(r.Context(), "Failed to proxy request", "method", r.Method, "path", r.URL.Path, "error", err)
+			api.RespondError(w, http.StatusBadGateway, cUpstreamUnavailable)
+		},
+	}
+}
+
+// ServeHTTP validates a request against the model it matches in the proxy tenant and, if it is not valid,
+// applies the enforcement of the model. Requests without a model are forwarded unchecked.
+// The time spent before forwarding or blocking is the overhead of the proxy, reported in a Server-Timing header.
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	r = r.Clone(tenant.WithTenant(r.Context(), p.tenant))
+	for _, name := range []string{HeaderRiskScore, HeaderCodes, HeaderError} {
+		r.Header.Del(name)
+	}
+
+	model, err := p.store.Match(r.Context(), r.URL.Path, r.Method)
+	if err != nil {
+		p.forward(w, r, start, cOutcomeUnmatched)
+		return
+	}
+
+	result, err := p.validate(r, model)
+	if err == nil && result.Valid {
+		p.forward(w, r, start, cOutcomeValid)
+		return
+	}
+
+	enforcement := p.defaultEnforcement
+	if model.Enforcement != nil {
+		enforcement = model.Enforcement
+	}
+
+	logAnomalous(r, enforcement.Action, result, err)
+
+	switch enforcement.Action {
+	case models.EnforcementBlock:
+		p.block(w, start, enforcement)
+
+	case models.EnforcementAnnotate:
+		annotate(r.Header, result, err)
+		p.forward(w, r, start, cOutcomeAnnotated)
+
+	default:
+		p.forward(w, r, start, cOutcomePassed)
+	}
+}
+
+// validate parses a request and validates it against its model, naming the proxy tenant on the anomalies.
+// The body is buffered so that it can still be forwarded, and a body over capture.MaxBodySize fails the parsing.
+func (p *proxy) validate(r *http.Request, model *models.APIModel) (*models.ValidationResult, error) {
+	ctx := r.Context()
+
+	parsed := r.Clone(ctx)
+	parsed.Body = http.NoBody
+
+	if r.Body != nil && r.Body != http.NoBody {
+		data, err := io.ReadAll(io.LimitReader(r.Body, capture.MaxBodySize+1))
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+
+		parsed.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	req, err := capture.FromHTTPRequest(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	result := p.validator.Validate(ctx, req, model)
+	result.SetTenant(tenant.FromContext(ctx))
+
+	return result, nil
+}
+
+// readCloser replays the buffered start of a body before its remainder, closing the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (p *proxy) forward(w http.ResponseWriter, r *http.Request, start time.Time, outcome string) {
+	p.report(w, start, outcome)
+	p.reverseProxy.ServeHTTP(w, r)
+}
+
+func (p *proxy) block(w http.ResponseWriter, start time.Time, enforcement *models.Enforcement) {
+	p.report(w, start, cOutcomeBlocked)
+
+	status := enforcement.BlockStatus
+	if status == 0 {
+		status = p.blockStatus
+	}
+
+	if enforcement.BlockBody != nil {
+		api.RespondJSON(w, status, enforcement.BlockBody)
+		return
+	}
+
+	api.RespondError(w, status, cBlockedMessage)
+}
+
+// report records the overhead of a request and adds it to the Server-Timing header of the response,
+// which the reverse proxy completes with the header values of the upstream
+func (p *proxy) report(w http.ResponseWriter, start time.Time, outcome string) {
+	overhead := time.Since(start)
+	p.stats.record(outcome, overhead)
+
+	w.Header().Add(cHeaderServerTiming, fmt.Sprintf("%s;dur=%.3f", cServerTimingMetric, milliseconds(overhead)))
+}
+
+// annotate sets the risk score and the anomaly codes of a request, or the parsing failure, on its headers
+func annotate(header http.Header, result *models.ValidationResult, err error) {
+	if err != nil {
+		header.Set(HeaderError, cUnparsableMessage)
+		return
+	}
+
+	header.Set(HeaderRiskScore, strconv.FormatFloat(result.RiskScore, 'f', -1, 64))
+	header.Set(HeaderCodes, strings.Join(anomalyCodes(result), ","))
+}
+
+// anomalyCodes lists the distinct codes of the anomalies of a result, in the order they were found
+func anomalyCodes(result *models.ValidationResult) []string {
+	codes := []string{}
+
+	for _, anomaly := range result.Anomalies {
+		if code := string(anomaly.Code); !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+func logAnomalous(r *http.Request, action models.EnforcementAction, result *models.ValidationResult, err error) {
+	attrs := []any{"method", r.Method, "path", r.URL.Path, "action", action}
+
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	} else {
+		attrs = append(attrs, "risk_score", result.RiskScore, "codes", anomalyCodes(result))
+	}
+
+	slog.WarnContext(r.Context(), "Anomalous proxied request", attrs...)
+}
+
+func (p *proxy) HandleStats(w http.ResponseWriter, _ *http.Request) {
+	api.RespondJSON(w, http.StatusOK, p.stats.snapshot())
+}
+
+func isErrorStatus(status int) bool {
+	return status >= http.StatusBadRequest && status <= 599
+}
@@ -0,0 +1,259 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tTenant = "shop"
+
+// tUpstreamRequest is what the upstream received
+type tUpstreamRequest struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+func newTestProxy(t *testing.T, action models.EnforcementAction, apiModels ...*models.APIModel) (
+	IProxy, chan *tUpstreamRequest,
+) {
+	received := make(chan *tUpstreamRequest, 1)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- &tUpstreamRequest{path: r.URL.Path, header: r.Header, body: string(body)}
+
+		w.Header().Set("Server-Timing", "db;dur=2")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(upstream.Close)
+
+	modelStore := store.NewModelStore()
+	ok, err := modelStore.StoreAll(tenant.WithTenant(context.Background(), tTenant), apiModels)
+	require.NoError(t, err, ok)
+
+	tProxy, err := NewProxy(&config.InitConfig{
+		ProxyUpstream:      upstream.URL,
+		ProxyAPIPrefix:     "/_detector",
+		ProxyTenant:        tTenant,
+		ProxyDefaultAction: string(action),
+		ProxyBlockStatus:   http.StatusForbidden,
+	}, modelStore)
+	require.NoError(t, err)
+
+	return tProxy, received
+}
+
+func serve(tProxy IProxy, method, target, contentType, body string) *httptest.ResponseRecorder {
+	httpRequest := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+
+	httpRequest.Header.Set(HeaderRiskScore, "0")
+
+	tRecorder := httptest.NewRecorder()
+	tProxy.ServeHTTP(tRecorder, httpRequest)
+
+	return tRecorder
+}
+
+func TestProxy(t *testing.T) {
+	tModel := &models.APIModel{
+		Path:   "/users",
+		Method: http.MethodPost,
+		Body: []*models.Parameter{
+			{Name: "email", Types: []models.ParamType{models.TypeEmail}, Required: true},
+		},
+	}
+
+	t.Run("forward valid requests with their body", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementBlock, tModel)
+
+		tRecorder := serve(tProxy, http.MethodPost, "/users", "application/json", `{"email": "a@example.com"}`)
+
+		assert.Equal(t, http.StatusTeapot, tRecorder.Code)
+		assert.Len(t, tRecorder.Header().Values("Server-Timing"), 2)
+		assert.Regexp(t, `^anomaly-detector;dur=\d+\.\d{3}$`, tRecorder.Header().Values("Server-Timing")[0])
+
+		upstreamRequest := <-received
+		assert.Equal(t, "/users", upstreamRequest.path)
+		assert.Equal(t, `{"email": "a@example.com"}`, upstreamRequest.body)
+		assert.Empty(t, upstreamRequest.header.Get(HeaderRiskScore), "clients cannot forge annotations")
+	})
+
+	t.Run("forward requests without a model", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementBlock, tModel)
+
+		tRecorder := serve(tProxy, http.MethodGet, "/health", "", "")
+
+		assert.Equal(t, http.StatusTeapot, tRecorder.Code)
+		assert.Equal(t, "/health", (<-received).path)
+	})
+
+	t.Run("pass invalid requests", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementPass, tModel)
+
+		tRecorder := serve(tProxy, http.MethodPost, "/users", "application/json", `{"email": 5}`)
+
+		assert.Equal(t, http.StatusTeapot, tRecorder.Code)
+		assert.Empty(t, (<-received).header.Get(HeaderCodes))
+	})
+
+	t.Run("annotate invalid requests", func(t *testing.T) {
+		tRejecting := *tModel
+		tRejecting.UnexpectedParams = models.UnexpectedParamsReject
+
+		tProxy, received := newTestProxy(t, models.EnforcementAnnotate, &tRejecting)
+
+		tRecorder := serve(tProxy, http.MethodPost, "/users", "application/json", `{"name": "x"}`)
+		assert.Equal(t, http.StatusTeapot, tRecorder.Code)
+
+		upstreamRequest := <-received
+		assert.Equal(t, "75", upstreamRequest.header.Get(HeaderRiskScore))
+		assert.Equal(t, "MISSING_REQUIRED,UNEXPECTED_PARAM", upstreamRequest.header.Get(HeaderCodes))
+		assert.Equal(t, `{"name": "x"}`, upstreamRequest.body)
+	})
+
+	t.Run("anomalies name the proxy tenant", func(t *testing.T) {
+		tProxy, _ := newTestProxy(t, models.EnforcementPass, tModel)
+
+		httpRequest := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"email": 5}`))
+		httpRequest.Header.Set("Content-Type", "application/json")
+		httpRequest = httpRequest.WithContext(tenant.WithTenant(httpRequest.Context(), tTenant))
+
+		result, err := tProxy.(*proxy).validate(httpRequest, tModel)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Anomalies)
+
+		for _, anomaly := range result.Anomalies {
+			assert.Equal(t, tTenant, anomaly.Tenant)
+		}
+	})
+
+	t.Run("annotate requests that cannot be parsed", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementAnnotate, tModel)
+
+		serve(tProxy, http.MethodPost, "/users", "text/plain", "hello")
+
+		upstreamRequest := <-received
+		assert.Equal(t, cUnparsableMessage, upstreamRequest.header.Get(HeaderError))
+		assert.Equal(t, "hello", upstreamRequest.body)
+	})
+
+	t.Run("block invalid requests with the default status", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementBlock, tModel)
+
+		tRecorder := serve(tProxy, http.MethodPost, "/users", "application/json", `{"email": 5}`)
+
+		assert.Equal(t, http.StatusForbidden, tRecorder.Code)
+		assert.JSONEq(t, `{"error": "request blocked"}`, tRecorder.Body.String())
+		assert.NotEmpty(t, tRecorder.Header().Get("Server-Timing"))
+		assert.Empty(t, received)
+	})
+
+	t.Run("the enforcement of the model overrides the default", func(t *testing.T) {
+		tBlocking := *tModel
+		tBlocking.Enforcement = &models.Enforcement{
+			Action:      models.EnforcementBlock,
+			BlockStatus: http.StatusUnprocessableEntity,
+			BlockBody:   map[string]any{"code": "rejected"},
+		}
+
+		tProxy, received := newTestProxy(t, models.EnforcementPass, &tBlocking)
+
+		tRecorder := serve(tProxy, http.MethodPost, "/users", "application/json", `{}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, tRecorder.Code)
+		assert.JSONEq(t, `{"code": "rejected"}`, tRecorder.Body.String())
+		assert.Empty(t, received)
+	})
+
+	t.Run("report stats", func(t *testing.T) {
+		tProxy, received := newTestProxy(t, models.EnforcementBlock, tModel)
+
+		serve(tProxy, http.MethodPost, "/users", "application/json", `{"email": "a@example.com"}`)
+		<-received
+		serve(tProxy, http.MethodPost, "/users", "application/json", `{}`)
+		serve(tProxy, http.MethodGet, "/other", "", "")
+		<-received
+
+		tRecorder := httptest.NewRecorder()
+		tProxy.HandleStats(tRecorder, httptest.NewRequest(http.MethodGet, "/_detector/proxy/stats", nil))
+
+		var stats Stats
+		require.NoError(t, json.NewDecoder(tRecorder.Body).Decode(&stats))
+
+		assert.Equal(t, 3, stats.Requests)
+		assert.Equal(t, map[string]int{cOutcomeValid: 1, cOutcomeBlocked: 1, cOutcomeUnmatched: 1}, stats.Outcomes)
+		assert.Equal(t, 3, stats.Overhead.Samples)
+		assert.LessOrEqual(t, stats.Overhead.P50, stats.Overhead.Max)
+	})
+}
+
+func TestNewProxy(t *testing.T) {
+	valid := config.InitConfig{
+		ProxyUpstream:      "http://localhost:9000",
+		ProxyAPIPrefix:     "/_detector",
+		ProxyTenant:        tenant.Default,
+		ProxyDefaultAction: string(models.EnforcementPass),
+		ProxyBlockStatus:   http.StatusForbidden,
+	}
+
+	t.Run("no proxy without an upstream", func(t *testing.T) {
+		tProxy, err := NewProxy(&config.InitConfig{}, store.NewModelStore())
+		assert.NoError(t, err)
+		assert.Nil(t, tProxy)
+	})
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.InitConfig)
+		err    string
+	}{
+		{name: "upstream without a scheme", modify: func(cfg *config.InitConfig) { cfg.ProxyUpstream = "localhost" },
+			err: "invalid proxy upstream"},
+		{name: "root API prefix", modify: func(cfg *config.InitConfig) { cfg.ProxyAPIPrefix = "/" },
+			err: "invalid proxy API prefix"},
+		{name: "invalid tenant", modify: func(cfg *config.InitConfig) { cfg.ProxyTenant = "Shop" },
+			err: "invalid proxy tenant"},
+		{name: "unknown action", modify: func(cfg *config.InitConfig) { cfg.ProxyDefaultAction = "drop" },
+			err: `invalid proxy default action "drop"`},
+		{name: "block status of a success", modify: func(cfg *config.InitConfig) { cfg.ProxyBlockStatus = http.StatusOK },
+			err: "invalid proxy block status 200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			_, err := NewProxy(&cfg, store.NewModelStore())
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	samples := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, &OverheadSummary{Samples: 100, Mean: 50.5, P50: 50, P95: 95, P99: 99, Max: 100}, summarize(samples))
+	assert.Equal(t, &OverheadSummary{}, summarize(nil))
+}
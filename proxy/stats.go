@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// cStatsWindow is the number of most recent requests the overhead percentiles are computed on
+const cStatsWindow = 1000
+
+// Outcomes of proxied requests
+const (
+	// cOutcomeUnmatched requests have no model and are forwarded unchecked
+	cOutcomeUnmatched = "unmatched"
+	cOutcomeValid     = "valid"
+	// cOutcomePassed, cOutcomeAnnotated and cOutcomeBlocked requests are not valid, and the enforcement of their
+	// model passed, annotated or blocked them
+	cOutcomePassed    = "passed"
+	cOutcomeAnnotated = "annotated"
+	cOutcomeBlocked   = "blocked"
+)
+
+// Stats counts the proxied requests by outcome and summarizes the overhead of the proxy
+type Stats struct {
+	Requests int            `json:"requests"`
+	Outcomes map[string]int `json:"outcomes"`
+	// Overhead is computed on the most recent requests, in milliseconds
+	Overhead *OverheadSummary `json:"overhead_ms"`
+}
+
+// OverheadSummary describes the time spent validating requests before forwarding or blocking them
+type OverheadSummary struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean"`
+	P50     float64 `json:"p50"`
+	P95     float64 `json:"p95"`
+	P99     float64 `json:"p99"`
+	Max     float64 `json:"max"`
+}
+
+// overheadStats keeps the outcome counts and, in a ring buffer, the overheads of the most recent requests
+type overheadStats struct {
+	mu       sync.Mutex
+	requests int
+	outcomes map[string]int
+	samples  []time.Duration
+	next     int
+}
+
+func newOverheadStats() *overheadStats {
+	return &overheadStats{
+		outcomes: map[string]int{},
+		samples:  make([]time.Duration, 0, cStatsWindow),
+	}
+}
+
+func (s *overheadStats) record(outcome string, overhead time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.outcomes[outcome]++
+
+	if len(s.samples) < cStatsWindow {
+		s.samples = append(s.samples, overhead)
+		return
+	}
+
+	s.samples[s.next] = overhead
+	s.next = (s.next + 1) % cStatsWindow
+}
+
+func (s *overheadStats) snapshot() *Stats {
+	s.mu.Lock()
+
+	stats := &Stats{Requests: s.requests, Outcomes: make(map[string]int, len(s.outcomes))}
+	for outcome, count := range s.outcomes {
+		stats.Outcomes[outcome] = count
+	}
+
+	samples := slices.Clone(s.samples)
+	s.mu.Unlock()
+
+	stats.Overhead = summarize(samples)
+
+	return stats
+}
+
+// summarize computes the mean, the maximum and the nearest-rank percentiles of overheads
+func summarize(samples []time.Duration) *OverheadSummary {
+	summary := &OverheadSummary{Samples: len(samples)}
+	if len(samples) == 0 {
+		return summary
+	}
+
+	slices.Sort(samples)
+
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p/100*float64(len(samples)))) - 1
+		return milliseconds(samples[max(rank, 0)])
+	}
+
+	summary.Mean = milliseconds(total / time.Duration(len(samples)))
+	summary.P50 = percentile(50)
+	summary.P95 = percentile(95)
+	summary.P99 = percentile(99)
+	summary.Max = milliseconds(samples[len(samples)-1])
+
+	return summary
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
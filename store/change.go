@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	cHeaderAuthor        = "X-Author"
+	cHeaderChangeComment = "X-Change-Comment"
+
+	cUnknownAuthor = "unknown"
+)
+
+// Change describes who made a change to the store and why. It travels in the request context so that
+// every write path (model API, OpenAPI import, learner commit) records it without extra parameters.
+type Change struct {
+	Author  string
+	Comment string
+	// At is the time of the change, set by the store when it is applied unless given (e.g. on journal replay)
+	At time.Time
+}
+
+type changeKey struct{}
+
+// WithChange returns a context carrying the change metadata of the writes made with it
+func WithChange(ctx context.Context, change Change) context.Context {
+	return context.WithValue(ctx, changeKey{}, change)
+}
+
+// changeFromContext returns the change metadata of a write, defaulting the author and the time
+func changeFromContext(ctx context.Context, now func() time.Time) Change {
+	change, _ := ctx.Value(changeKey{}).(Change)
+
+	if change.Author == "" {
+		change.Author = cUnknownAuthor
+	}
+
+	if change.At.IsZero() {
+		change.At = now().UTC()
+	}
+
+	return change
+}
+
+// ChangeMiddleware reads the author and comment of a change from the X-Author and X-Change-Comment headers
+func ChangeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		change := Change{
+			Author:  r.Header.Get(cHeaderAuthor),
+			Comment: r.Header.Get(cHeaderChangeComment),
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithChange(r.Context(), change)))
+	})
+}
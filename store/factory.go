@@ -0,0 +1,26 @@
+package store
+
+import (
+	"fmt"
+
+	"anomaly_detector/config"
+)
+
+const (
+	cStoreTypeMemory = "memory"
+	cStoreTypeFile   = "file"
+)
+
+// NewConfiguredModelStore creates the IModelStore implementation selected by the STORE_TYPE configuration
+func NewConfiguredModelStore(cfg *config.InitConfig) (IModelStore, error) {
+	switch cfg.StoreType {
+	case cStoreTypeMemory, "":
+		return NewModelStore(), nil
+
+	case cStoreTypeFile:
+		return NewFileModelStore(cfg.StoreDataDir, cfg.StoreSnapshotEvery)
+
+	default:
+		return nil, fmt.Errorf("unknown store type %q", cfg.StoreType)
+	}
+}
@@ -0,0 +1,502 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"anomaly_detector/models"
+	"anomaly_detector/tenant"
+)
+
+const (
+	cJournalFileName  = "journal.jsonl"
+	cSnapshotFileName = "snapshot.json"
+
+	cDataDirPerm  = 0o750
+	cDataFilePerm = 0o600
+
+	cOpStoreAll = "store_all"
+	cOpReplace  = "replace"
+	cOpDelete   = "delete"
+	cOpRollback = "rollback"
+
+	cOpDeleteTenant = "delete_tenant"
+	cOpDefineType   = "define_type"
+)
+
+// journalEntry is a single line of the append-only journal.
+// A whole StoreAll batch is one entry, so a batch is either fully replayed or not at all.
+// The change metadata is journaled so that replaying an entry records the same version again.
+type journalEntry struct {
+	Sequence uint64                 `json:"sequence"`
+	Op       string                 `json:"op"`
+	Tenant   string                 `json:"tenant"`
+	Models   []*models.APIModel     `json:"models,omitempty"`
+	Type     *models.TypeDefinition `json:"type,omitempty"`
+	Path     string                 `json:"path,omitempty"`
+	Method   string                 `json:"method,omitempty"`
+	Version  int                    `json:"version,omitempty"`
+	Author   string                 `json:"author,omitempty"`
+	Comment  string                 `json:"comment,omitempty"`
+	At       time.Time              `json:"at,omitzero"`
+}
+
+// snapshotFile holds every model version and the custom types of every tenant as of Sequence.
+// Journal entries up to Sequence are already included.
+type snapshotFile struct {
+	Sequence uint64                              `json:"sequence"`
+	Versions []*models.ModelVersion              `json:"versions"`
+	Types    map[string][]*models.TypeDefinition `json:"types,omitempty"`
+}
+
+// fileModelStore is an IModelStore that keeps the in-memory store as the read path and persists
+// every change to an append-only journal on local disk, compacted into a snapshot every snapshotEvery entries.
+type fileModelStore struct {
+	*modelStore
+
+	// writeMu serializes writers so that the check, the journal append and the in-memory apply happen atomically
+	writeMu sync.Mutex
+
+	dir           string
+	journal       *os.File
+	sequence      uint64
+	sinceSnapshot int
+	snapshotEvery int
+}
+
+// NewFileModelStore opens (or creates) a file-backed model store in dir and recovers its content
+func NewFileModelStore(dir string, snapshotEvery int) (IModelStore, error) {
+	if err := os.MkdirAll(dir, cDataDirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create data directory %s: %w", dir, err)
+	}
+
+	s := &fileModelStore{
+		modelStore:    newModelStore(),
+		dir:           dir,
+		snapshotEvery: snapshotEvery,
+	}
+
+	if err := s.recover(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *fileModelStore) StoreAll(ctx context.Context, apiModels []*models.APIModel) (bool, error) {
+	normalizeMethods(apiModels...)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	err := s.namespaceOf(ctx).checkStoreAll(apiModels)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return true, err
+	}
+
+	return s.commit(ctx, &journalEntry{Op: cOpStoreAll, Models: apiModels}, func(ctx context.Context) (bool, error) {
+		return s.modelStore.StoreAll(ctx, apiModels)
+	})
+}
+
+func (s *fileModelStore) Replace(ctx context.Context, model *models.APIModel, revision int) (int, bool, error) {
+	normalizeMethods(model)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	err := s.namespaceOf(ctx).checkReplace(model, revision)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return 0, true, err
+	}
+
+	entry := &journalEntry{Op: cOpReplace, Models: []*models.APIModel{model}}
+
+	var replaced int
+
+	ok, err := s.commit(ctx, entry, func(ctx context.Context) (ok bool, err error) {
+		replaced, ok, err = s.modelStore.Replace(ctx, model, revision)
+		return ok, err
+	})
+
+	return replaced, ok, err
+}
+
+func (s *fileModelStore) Delete(ctx context.Context, path, method string, revision int) (bool, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	err := s.namespaceOf(ctx).checkRevision(path, method, revision)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return true, err
+	}
+
+	entry := &journalEntry{Op: cOpDelete, Path: path, Method: method}
+
+	return s.commit(ctx, entry, func(ctx context.Context) (bool, error) {
+		return s.modelStore.Delete(ctx, path, method, revision)
+	})
+}
+
+func (s *fileModelStore) Rollback(ctx context.Context, path, method string, version, revision int) (int, bool, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	_, err := s.namespaceOf(ctx).checkRollback(path, method, version, revision)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return 0, true, err
+	}
+
+	entry := &journalEntry{Op: cOpRollback, Path: path, Method: method, Version: version}
+
+	var rolledBack int
+
+	ok, err := s.commit(ctx, entry, func(ctx context.Context) (ok bool, err error) {
+		rolledBack, ok, err = s.modelStore.Rollback(ctx, path, method, version, revision)
+		return ok, err
+	})
+
+	return rolledBack, ok, err
+}
+
+func (s *fileModelStore) DeleteTenant(ctx context.Context) (bool, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	err := s.checkTenantExists(tenant.FromContext(ctx))
+	s.mu.RUnlock()
+
+	if err != nil {
+		return true, err
+	}
+
+	return s.commit(ctx, &journalEntry{Op: cOpDeleteTenant}, s.modelStore.DeleteTenant)
+}
+
+func (s *fileModelStore) DefineType(ctx context.Context, definition *models.TypeDefinition) (bool, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	err := s.namespaceOf(ctx).checkDefineType(definition)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return true, err
+	}
+
+	return s.commit(ctx, &journalEntry{Op: cOpDefineType, Type: definition}, func(ctx context.Context) (bool, error) {
+		return s.modelStore.DefineType(ctx, definition)
+	})
+}
+
+// Close flushes the journal to disk and releases the file handle
+func (s *fileModelStore) Close() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.journal.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	return s.journal.Close()
+}
+
+// commit journals an entry, applies it in memory and compacts the journal when due. The caller must hold writeMu.
+// The change metadata is resolved once, so that the journal and the recorded version agree.
+func (s *fileModelStore) commit(
+	ctx context.Context, entry *journalEntry, apply func(ctx context.Context) (bool, error),
+) (bool, error) {
+	change := changeFromContext(ctx, s.now)
+	entry.Tenant = tenant.FromContext(ctx)
+	entry.Author, entry.Comment, entry.At = change.Author, change.Comment, change.At
+
+	if err := s.appendEntry(entry); err != nil {
+		return false, err
+	}
+
+	ok, err := apply(WithChange(ctx, change))
+	if err != nil {
+		return ok, err
+	}
+
+	s.compactIfDue(ctx)
+
+	return ok, nil
+}
+
+// appendEntry durably writes an entry to the journal. The caller must hold writeMu.
+func (s *fileModelStore) appendEntry(entry *journalEntry) error {
+	entry.Sequence = s.sequence + 1
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	offset, err := s.journal.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to locate journal end: %w", err)
+	}
+
+	_, err = s.journal.Write(append(line, '\n'))
+	if err == nil {
+		err = s.journal.Sync()
+	}
+
+	if err != nil {
+		// Roll back a partial write so that later entries are not appended after garbage
+		return errors.Join(fmt.Errorf("failed to write journal entry: %w", err), s.truncateJournal(offset))
+	}
+
+	s.sequence = entry.Sequence
+	s.sinceSnapshot++
+
+	return nil
+}
+
+// truncateJournal cuts the journal to size and positions the next write at its end
+func (s *fileModelStore) truncateJournal(size int64) error {
+	if err := s.journal.Truncate(size); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+
+	if _, err := s.journal.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek journal: %w", err)
+	}
+
+	return nil
+}
+
+// compactIfDue writes a snapshot once snapshotEvery entries were appended since the last one.
+// It must run after the entry was applied in memory. The caller must hold writeMu.
+func (s *fileModelStore) compactIfDue(ctx context.Context) {
+	if s.snapshotEvery <= 0 || s.sinceSnapshot < s.snapshotEvery {
+		return
+	}
+
+	// A failed snapshot is not fatal: the journal still holds every change
+	if err := s.writeSnapshot(); err != nil {
+		slog.ErrorContext(ctx, "failed to write model store snapshot", "error", err)
+	}
+}
+
+// writeSnapshot atomically replaces the snapshot file and truncates the journal. The caller must hold writeMu.
+func (s *fileModelStore) writeSnapshot() error {
+	s.mu.RLock()
+	snapshot := snapshotFile{Sequence: s.sequence, Versions: s.snapshot(), Types: s.typeSnapshot()}
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	snapshotPath := filepath.Join(s.dir, cSnapshotFileName)
+	tmpPath := snapshotPath + ".tmp"
+
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, snapshotPath); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	// Entries up to snapshot.Sequence are skipped on recovery, so a crash before truncation is harmless
+	if err := s.truncateJournal(0); err != nil {
+		return err
+	}
+
+	s.sinceSnapshot = 0
+
+	return nil
+}
+
+// recover loads the snapshot, replays the journal on top of it and opens the journal for appending
+func (s *fileModelStore) recover() error {
+	snapshot, err := readSnapshot(filepath.Join(s.dir, cSnapshotFileName))
+	if err != nil {
+		return err
+	}
+
+	s.restore(snapshot.Versions, snapshot.Types)
+	s.sequence = snapshot.Sequence
+
+	journalPath := filepath.Join(s.dir, cJournalFileName)
+
+	s.journal, err = os.OpenFile(filepath.Clean(journalPath), os.O_RDWR|os.O_CREATE, cDataFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	validSize, err := s.replayJournal()
+	if err != nil {
+		return errors.Join(err, s.journal.Close())
+	}
+
+	// Drop a torn trailing entry left by a crash mid-write, then continue appending after the last good one
+	if err := s.truncateJournal(validSize); err != nil {
+		return errors.Join(err, s.journal.Close())
+	}
+
+	slog.Info("Model store recovered", "dir", s.dir, "models", s.modelCount(), "sequence", s.sequence)
+
+	return nil
+}
+
+// replayJournal applies every journal entry newer than the snapshot and returns the size of the valid prefix
+func (s *fileModelStore) replayJournal() (int64, error) {
+	ctx := context.Background()
+	reader := bufio.NewReader(s.journal)
+
+	var validSize int64
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return 0, fmt.Errorf("failed to read journal: %w", readErr)
+		}
+
+		// A line without its newline terminator was not fully written
+		if errors.Is(readErr, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				slog.Warn("Discarding incomplete journal entry", "offset", validSize)
+			}
+
+			return validSize, nil
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return 0, fmt.Errorf("corrupted journal entry at offset %d: %w", validSize, err)
+		}
+
+		if entry.Sequence > s.sequence {
+			if err := s.apply(ctx, &entry); err != nil {
+				return 0, fmt.Errorf("failed to replay journal entry %d: %w", entry.Sequence, err)
+			}
+
+			s.sequence = entry.Sequence
+			s.sinceSnapshot++
+		}
+
+		validSize += int64(len(line))
+	}
+}
+
+// apply replays a single journal entry against the in-memory store. The entry was checked before it was
+// journaled, and the checks may have become stricter since, so replay applies it unchecked like restore does.
+func (s *fileModelStore) apply(ctx context.Context, entry *journalEntry) error {
+	if entry.Tenant == "" {
+		return fmt.Errorf("%s entry has no tenant", entry.Op)
+	}
+
+	ctx = tenant.WithTenant(ctx, entry.Tenant)
+	change := changeFromContext(WithChange(ctx, Change{Author: entry.Author, Comment: entry.Comment, At: entry.At}), s.now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch entry.Op {
+	case cOpStoreAll:
+		if slices.ContainsFunc(entry.Models, func(model *models.APIModel) bool { return !isValidModel(model) }) {
+			return fmt.Errorf("store_all entry holds an invalid model")
+		}
+
+		s.writableNamespaceOf(ctx).storeAll(ctx, entry.Models, change)
+	case cOpReplace:
+		if len(entry.Models) != 1 || !isValidModel(entry.Models[0]) {
+			return fmt.Errorf("replace entry must hold exactly one model")
+		}
+
+		s.writableNamespaceOf(ctx).replace(ctx, entry.Models[0], change)
+	case cOpDelete:
+		s.writableNamespaceOf(ctx).remove(ctx, entry.Path, entry.Method, change)
+	case cOpRollback:
+		ns := s.writableNamespaceOf(ctx)
+
+		target, err := ns.findVersion(entry.Path, entry.Method, entry.Version)
+		if err != nil {
+			return err
+		}
+
+		if target.Model == nil {
+			return fmt.Errorf("version %d records a deletion and cannot be restored", entry.Version)
+		}
+
+		ns.rollback(ctx, entry.Path, entry.Method, target.Model, entry.Version, change)
+	case cOpDeleteTenant:
+		delete(s.namespaces, tenant.FromContext(ctx))
+	case cOpDefineType:
+		if entry.Type == nil {
+			return fmt.Errorf("define_type entry must hold a type definition")
+		}
+
+		s.writableNamespaceOf(ctx).defineType(ctx, entry.Type)
+	default:
+		return fmt.Errorf("unknown journal operation %q", entry.Op)
+	}
+
+	return nil
+}
+
+func readSnapshot(path string) (*snapshotFile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return &snapshotFile{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot snapshotFile
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("corrupted snapshot %s: %w", path, err)
+	}
+
+	return &snapshot, nil
+}
+
+// writeFileSync writes data to path and fsyncs it before returning
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, cDataFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return errors.Join(fmt.Errorf("failed to write %s: %w", path, err), file.Close())
+	}
+
+	if err := file.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("failed to sync %s: %w", path, err), file.Close())
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,277 @@
+package store
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openFileStore(t *testing.T, dir string, snapshotEvery int) IModelStore {
+	t.Helper()
+
+	tStore, err := NewFileModelStore(dir, snapshotEvery)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = tStore.(io.Closer).Close() })
+
+	return tStore
+}
+
+func TestFileModelStore(t *testing.T) {
+	tModels := []*models.APIModel{
+		{
+			Path:   "/users",
+			Method: "GET",
+			QueryParams: []*models.Parameter{
+				{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+			},
+		},
+		{Path: "/users", Method: "POST"},
+	}
+
+	t.Run("recovers models after reopening", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		tStore := openFileStore(t, dir, 0)
+		ok, err := tStore.StoreAll(ctx, tModels)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		replacement := &models.APIModel{Path: "/users", Method: "GET"}
+		_, _, err = tStore.Replace(ctx, replacement, AnyRevision)
+		assert.NoError(t, err)
+
+		_, err = tStore.Delete(ctx, "/users", "POST", AnyRevision)
+		assert.NoError(t, err)
+
+		reopened := openFileStore(t, dir, 0)
+
+		result, err := reopened.List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.APIModel{replacement}, result)
+	})
+
+	t.Run("recovers custom types after reopening", func(t *testing.T) {
+		tAcme := tenant.WithTenant(context.Background(), "acme")
+		tMobile := &models.TypeDefinition{Name: "Mobile", Pattern: `^\+[0-9]+$`}
+		tCountry := &models.TypeDefinition{Name: "ISO-Country", Enum: []any{"IL", "US"}}
+		dir := t.TempDir()
+
+		// The first definition ends up in the snapshot and the second one in the journal
+		tStore := openFileStore(t, dir, 1)
+		_, err := tStore.DefineType(tAcme, tMobile)
+		require.NoError(t, err)
+		_, err = tStore.DefineType(tAcme, tCountry)
+		require.NoError(t, err)
+
+		_, err = tStore.DefineType(context.Background(), &models.TypeDefinition{Name: "Mobile"})
+		assert.Error(t, err)
+
+		reopened := openFileStore(t, dir, 1)
+
+		definitions, err := reopened.Types(tAcme)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.TypeDefinition{tCountry, tMobile}, definitions)
+
+		definitions, err = reopened.Types(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, definitions)
+	})
+
+	t.Run("recovers from snapshot and journal", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		tStore := openFileStore(t, dir, 1)
+		_, err := tStore.StoreAll(ctx, tModels[:1])
+		assert.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, cSnapshotFileName))
+
+		_, err = tStore.StoreAll(ctx, tModels[1:])
+		assert.NoError(t, err)
+
+		reopened := openFileStore(t, dir, 1)
+
+		result, err := reopened.List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, tModels, result)
+	})
+
+	t.Run("recovers history and rollbacks after reopening", func(t *testing.T) {
+		for _, snapshotEvery := range []int{0, 1} {
+			ctx := WithChange(context.Background(), Change{Author: "alice", Comment: "initial"})
+			dir := t.TempDir()
+
+			tStore := openFileStore(t, dir, snapshotEvery)
+			_, err := tStore.StoreAll(ctx, tModels[:1])
+			assert.NoError(t, err)
+
+			replacement := &models.APIModel{Path: "/users", Method: "GET"}
+			_, _, err = tStore.Replace(context.Background(), replacement, AnyRevision)
+			assert.NoError(t, err)
+
+			_, _, err = tStore.Rollback(WithChange(context.Background(), Change{Author: "bob"}), "/users", "GET", 1, AnyRevision)
+			assert.NoError(t, err)
+
+			versions, err := tStore.Versions(ctx, "/users", "GET")
+			assert.NoError(t, err)
+
+			reopened := openFileStore(t, dir, snapshotEvery)
+
+			recovered, err := reopened.Versions(ctx, "/users", "GET")
+			assert.NoError(t, err)
+			assert.Equal(t, versions, recovered)
+			assert.Equal(t, "alice", recovered[0].Author)
+			assert.Equal(t, "bob", recovered[2].Author)
+
+			active, err := reopened.Get(ctx, "/users", "GET")
+			assert.NoError(t, err)
+			assert.Equal(t, tModels[0], active)
+		}
+	})
+
+	t.Run("file store rejects a stale revision without journaling it", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		tStore := openFileStore(t, dir, 0)
+		_, err := tStore.StoreAll(ctx, tModels[:1])
+		assert.NoError(t, err)
+
+		_, _, err = tStore.Replace(ctx, tModels[0], 2)
+		assert.ErrorIs(t, err, ErrRevisionMismatch)
+
+		versions, err := openFileStore(t, dir, 0).Versions(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		assert.Len(t, versions, 1)
+	})
+
+	t.Run("recovers tenants after reopening", func(t *testing.T) {
+		tAcme := tenant.WithTenant(context.Background(), "acme")
+		tGlobex := tenant.WithTenant(context.Background(), "globex")
+
+		for _, snapshotEvery := range []int{0, 1} {
+			dir := t.TempDir()
+
+			tStore := openFileStore(t, dir, snapshotEvery)
+			_, err := tStore.StoreAll(tAcme, tModels)
+			assert.NoError(t, err)
+			_, err = tStore.StoreAll(tGlobex, tModels[:1])
+			assert.NoError(t, err)
+			_, err = tStore.DeleteTenant(tGlobex)
+			assert.NoError(t, err)
+
+			reopened := openFileStore(t, dir, snapshotEvery)
+
+			result, err := reopened.List(tAcme, "", "")
+			assert.NoError(t, err)
+			assert.Equal(t, tModels, result)
+
+			result, err = reopened.List(context.Background(), "", "")
+			assert.NoError(t, err)
+			assert.Empty(t, result)
+
+			_, err = reopened.DeleteTenant(tGlobex)
+			assert.ErrorIs(t, err, ErrTenantNotFound)
+		}
+	})
+
+	t.Run("rejected batch is not persisted", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		tStore := openFileStore(t, dir, 0)
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{tModels[0], nil})
+		assert.Error(t, err)
+		assert.True(t, ok)
+
+		reopened := openFileStore(t, dir, 0)
+
+		result, err := reopened.List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("discards a torn trailing journal entry", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		tStore := openFileStore(t, dir, 0)
+		_, err := tStore.StoreAll(ctx, tModels[:1])
+		assert.NoError(t, err)
+
+		// Simulate a crash in the middle of writing the second batch
+		journal, err := os.OpenFile(filepath.Join(dir, cJournalFileName), os.O_APPEND|os.O_WRONLY, cDataFilePerm)
+		require.NoError(t, err)
+		_, err = journal.WriteString(`{"sequence":2,"op":"store_all","tenant":"default","models":[{"path":"/users","met`)
+		require.NoError(t, err)
+		require.NoError(t, journal.Close())
+
+		reopened := openFileStore(t, dir, 0)
+
+		result, err := reopened.List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, tModels[:1], result)
+
+		// Appending after recovery must produce a readable journal
+		_, err = reopened.StoreAll(ctx, tModels[1:])
+		assert.NoError(t, err)
+
+		result, err = openFileStore(t, dir, 0).List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, tModels, result)
+	})
+
+	t.Run("replays entries accepted before the checks became stricter", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		journal := `{"sequence":1,"op":"store_all","tenant":"default","models":[{"path":"/users","method":"GET",` +
+			`"query_params":[{"name":"id","types":["Strnig"]}]}]}` + "\n" +
+			`{"sequence":2,"op":"replace","tenant":"default","models":[{"path":"/users","method":"GET",` +
+			`"headers":[{"name":"X-Id","types":["String"]},{"name":"x-id","types":["String"]}]}]}` + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, cJournalFileName), []byte(journal), cDataFilePerm))
+
+		reopened := openFileStore(t, dir, 0)
+
+		model, revision, err := reopened.GetRevision(ctx, "/users", "GET")
+		require.NoError(t, err)
+		assert.Equal(t, 2, revision)
+		assert.Len(t, model.Headers, 2)
+
+		// New writes are still checked
+		_, err = reopened.StoreAll(ctx, []*models.APIModel{{Path: "/orders", Method: "GET", QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{"Strnig"}},
+		}}})
+		assert.ErrorContains(t, err, `unknown type "Strnig"`)
+	})
+
+	t.Run("fail on journal entry without a tenant", func(t *testing.T) {
+		dir := t.TempDir()
+
+		journal := `{"sequence":1,"op":"store_all","models":[{"path":"/users","method":"GET"}]}` + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, cJournalFileName), []byte(journal), cDataFilePerm))
+
+		_, err := NewFileModelStore(dir, 0)
+		assert.ErrorContains(t, err, "store_all entry has no tenant")
+	})
+
+	t.Run("fail on corrupted journal entry", func(t *testing.T) {
+		dir := t.TempDir()
+
+		err := os.WriteFile(filepath.Join(dir, cJournalFileName), []byte("not json\n"), cDataFilePerm)
+		require.NoError(t, err)
+
+		_, err = NewFileModelStore(dir, 0)
+		assert.Error(t, err)
+	})
+}
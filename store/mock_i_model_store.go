@@ -22,6 +22,178 @@ func (_m *MockIModelStore) EXPECT() *MockIModelStore_Expecter {
 	return &MockIModelStore_Expecter{mock: &_m.Mock}
 }
 
+// DefineType provides a mock function with given fields: ctx, definition
+func (_m *MockIModelStore) DefineType(ctx context.Context, definition *models.TypeDefinition) (bool, error) {
+	ret := _m.Called(ctx, definition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DefineType")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TypeDefinition) (bool, error)); ok {
+		return rf(ctx, definition)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TypeDefinition) bool); ok {
+		r0 = rf(ctx, definition)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.TypeDefinition) error); ok {
+		r1 = rf(ctx, definition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_DefineType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DefineType'
+type MockIModelStore_DefineType_Call struct {
+	*mock.Call
+}
+
+// DefineType is a helper method to define mock.On call
+//   - ctx context.Context
+//   - definition *models.TypeDefinition
+func (_e *MockIModelStore_Expecter) DefineType(ctx interface{}, definition interface{}) *MockIModelStore_DefineType_Call {
+	return &MockIModelStore_DefineType_Call{Call: _e.mock.On("DefineType", ctx, definition)}
+}
+
+func (_c *MockIModelStore_DefineType_Call) Run(run func(ctx context.Context, definition *models.TypeDefinition)) *MockIModelStore_DefineType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.TypeDefinition))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_DefineType_Call) Return(_a0 bool, _a1 error) *MockIModelStore_DefineType_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_DefineType_Call) RunAndReturn(run func(context.Context, *models.TypeDefinition) (bool, error)) *MockIModelStore_DefineType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, path, method, revision
+func (_m *MockIModelStore) Delete(ctx context.Context, path string, method string, revision int) (bool, error) {
+	ret := _m.Called(ctx, path, method, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (bool, error)); ok {
+		return rf(ctx, path, method, revision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) bool); ok {
+		r0 = rf(ctx, path, method, revision)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, path, method, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockIModelStore_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+//   - revision int
+func (_e *MockIModelStore_Expecter) Delete(ctx interface{}, path interface{}, method interface{}, revision interface{}) *MockIModelStore_Delete_Call {
+	return &MockIModelStore_Delete_Call{Call: _e.mock.On("Delete", ctx, path, method, revision)}
+}
+
+func (_c *MockIModelStore_Delete_Call) Run(run func(ctx context.Context, path string, method string, revision int)) *MockIModelStore_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Delete_Call) Return(_a0 bool, _a1 error) *MockIModelStore_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_Delete_Call) RunAndReturn(run func(context.Context, string, string, int) (bool, error)) *MockIModelStore_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteTenant provides a mock function with given fields: ctx
+func (_m *MockIModelStore) DeleteTenant(ctx context.Context) (bool, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTenant")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (bool, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_DeleteTenant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteTenant'
+type MockIModelStore_DeleteTenant_Call struct {
+	*mock.Call
+}
+
+// DeleteTenant is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockIModelStore_Expecter) DeleteTenant(ctx interface{}) *MockIModelStore_DeleteTenant_Call {
+	return &MockIModelStore_DeleteTenant_Call{Call: _e.mock.On("DeleteTenant", ctx)}
+}
+
+func (_c *MockIModelStore_DeleteTenant_Call) Run(run func(ctx context.Context)) *MockIModelStore_DeleteTenant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_DeleteTenant_Call) Return(_a0 bool, _a1 error) *MockIModelStore_DeleteTenant_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_DeleteTenant_Call) RunAndReturn(run func(context.Context) (bool, error)) *MockIModelStore_DeleteTenant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function with given fields: ctx, path, method
 func (_m *MockIModelStore) Get(ctx context.Context, path string, method string) (*models.APIModel, error) {
 	ret := _m.Called(ctx, path, method)
@@ -82,6 +254,325 @@ func (_c *MockIModelStore_Get_Call) RunAndReturn(run func(context.Context, strin
 	return _c
 }
 
+// GetRevision provides a mock function with given fields: ctx, path, method
+func (_m *MockIModelStore) GetRevision(ctx context.Context, path string, method string) (*models.APIModel, int, error) {
+	ret := _m.Called(ctx, path, method)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevision")
+	}
+
+	var r0 *models.APIModel
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.APIModel, int, error)); ok {
+		return rf(ctx, path, method)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.APIModel); ok {
+		r0 = rf(ctx, path, method)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIModel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) int); ok {
+		r1 = rf(ctx, path, method)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, path, method)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockIModelStore_GetRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRevision'
+type MockIModelStore_GetRevision_Call struct {
+	*mock.Call
+}
+
+// GetRevision is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+func (_e *MockIModelStore_Expecter) GetRevision(ctx interface{}, path interface{}, method interface{}) *MockIModelStore_GetRevision_Call {
+	return &MockIModelStore_GetRevision_Call{Call: _e.mock.On("GetRevision", ctx, path, method)}
+}
+
+func (_c *MockIModelStore_GetRevision_Call) Run(run func(ctx context.Context, path string, method string)) *MockIModelStore_GetRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_GetRevision_Call) Return(_a0 *models.APIModel, _a1 int, _a2 error) *MockIModelStore_GetRevision_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockIModelStore_GetRevision_Call) RunAndReturn(run func(context.Context, string, string) (*models.APIModel, int, error)) *MockIModelStore_GetRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, pathPrefix, method
+func (_m *MockIModelStore) List(ctx context.Context, pathPrefix string, method string) ([]*models.APIModel, error) {
+	ret := _m.Called(ctx, pathPrefix, method)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*models.APIModel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*models.APIModel, error)); ok {
+		return rf(ctx, pathPrefix, method)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.APIModel); ok {
+		r0 = rf(ctx, pathPrefix, method)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.APIModel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, pathPrefix, method)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockIModelStore_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pathPrefix string
+//   - method string
+func (_e *MockIModelStore_Expecter) List(ctx interface{}, pathPrefix interface{}, method interface{}) *MockIModelStore_List_Call {
+	return &MockIModelStore_List_Call{Call: _e.mock.On("List", ctx, pathPrefix, method)}
+}
+
+func (_c *MockIModelStore_List_Call) Run(run func(ctx context.Context, pathPrefix string, method string)) *MockIModelStore_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_List_Call) Return(_a0 []*models.APIModel, _a1 error) *MockIModelStore_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_List_Call) RunAndReturn(run func(context.Context, string, string) ([]*models.APIModel, error)) *MockIModelStore_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Match provides a mock function with given fields: ctx, path, method
+func (_m *MockIModelStore) Match(ctx context.Context, path string, method string) (*models.APIModel, error) {
+	ret := _m.Called(ctx, path, method)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Match")
+	}
+
+	var r0 *models.APIModel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.APIModel, error)); ok {
+		return rf(ctx, path, method)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.APIModel); ok {
+		r0 = rf(ctx, path, method)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIModel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, path, method)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_Match_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Match'
+type MockIModelStore_Match_Call struct {
+	*mock.Call
+}
+
+// Match is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+func (_e *MockIModelStore_Expecter) Match(ctx interface{}, path interface{}, method interface{}) *MockIModelStore_Match_Call {
+	return &MockIModelStore_Match_Call{Call: _e.mock.On("Match", ctx, path, method)}
+}
+
+func (_c *MockIModelStore_Match_Call) Run(run func(ctx context.Context, path string, method string)) *MockIModelStore_Match_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Match_Call) Return(_a0 *models.APIModel, _a1 error) *MockIModelStore_Match_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_Match_Call) RunAndReturn(run func(context.Context, string, string) (*models.APIModel, error)) *MockIModelStore_Match_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Replace provides a mock function with given fields: ctx, model, revision
+func (_m *MockIModelStore) Replace(ctx context.Context, model *models.APIModel, revision int) (int, bool, error) {
+	ret := _m.Called(ctx, model, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replace")
+	}
+
+	var r0 int
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.APIModel, int) (int, bool, error)); ok {
+		return rf(ctx, model, revision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.APIModel, int) int); ok {
+		r0 = rf(ctx, model, revision)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.APIModel, int) bool); ok {
+		r1 = rf(ctx, model, revision)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.APIModel, int) error); ok {
+		r2 = rf(ctx, model, revision)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockIModelStore_Replace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replace'
+type MockIModelStore_Replace_Call struct {
+	*mock.Call
+}
+
+// Replace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - model *models.APIModel
+//   - revision int
+func (_e *MockIModelStore_Expecter) Replace(ctx interface{}, model interface{}, revision interface{}) *MockIModelStore_Replace_Call {
+	return &MockIModelStore_Replace_Call{Call: _e.mock.On("Replace", ctx, model, revision)}
+}
+
+func (_c *MockIModelStore_Replace_Call) Run(run func(ctx context.Context, model *models.APIModel, revision int)) *MockIModelStore_Replace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.APIModel), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Replace_Call) Return(_a0 int, _a1 bool, _a2 error) *MockIModelStore_Replace_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockIModelStore_Replace_Call) RunAndReturn(run func(context.Context, *models.APIModel, int) (int, bool, error)) *MockIModelStore_Replace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Rollback provides a mock function with given fields: ctx, path, method, version, revision
+func (_m *MockIModelStore) Rollback(ctx context.Context, path string, method string, version int, revision int) (int, bool, error) {
+	ret := _m.Called(ctx, path, method, version, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 int
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) (int, bool, error)); ok {
+		return rf(ctx, path, method, version, revision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) int); ok {
+		r0 = rf(ctx, path, method, version, revision)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, int) bool); ok {
+		r1 = rf(ctx, path, method, version, revision)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int, int) error); ok {
+		r2 = rf(ctx, path, method, version, revision)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockIModelStore_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
+type MockIModelStore_Rollback_Call struct {
+	*mock.Call
+}
+
+// Rollback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+//   - version int
+//   - revision int
+func (_e *MockIModelStore_Expecter) Rollback(ctx interface{}, path interface{}, method interface{}, version interface{}, revision interface{}) *MockIModelStore_Rollback_Call {
+	return &MockIModelStore_Rollback_Call{Call: _e.mock.On("Rollback", ctx, path, method, version, revision)}
+}
+
+func (_c *MockIModelStore_Rollback_Call) Run(run func(ctx context.Context, path string, method string, version int, revision int)) *MockIModelStore_Rollback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Rollback_Call) Return(_a0 int, _a1 bool, _a2 error) *MockIModelStore_Rollback_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockIModelStore_Rollback_Call) RunAndReturn(run func(context.Context, string, string, int, int) (int, bool, error)) *MockIModelStore_Rollback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StoreAll provides a mock function with given fields: ctx, _a1
 func (_m *MockIModelStore) StoreAll(ctx context.Context, _a1 []*models.APIModel) (bool, error) {
 	ret := _m.Called(ctx, _a1)
@@ -139,6 +630,185 @@ func (_c *MockIModelStore_StoreAll_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// Types provides a mock function with given fields: ctx
+func (_m *MockIModelStore) Types(ctx context.Context) ([]*models.TypeDefinition, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Types")
+	}
+
+	var r0 []*models.TypeDefinition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.TypeDefinition, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.TypeDefinition); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.TypeDefinition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_Types_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Types'
+type MockIModelStore_Types_Call struct {
+	*mock.Call
+}
+
+// Types is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockIModelStore_Expecter) Types(ctx interface{}) *MockIModelStore_Types_Call {
+	return &MockIModelStore_Types_Call{Call: _e.mock.On("Types", ctx)}
+}
+
+func (_c *MockIModelStore_Types_Call) Run(run func(ctx context.Context)) *MockIModelStore_Types_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Types_Call) Return(_a0 []*models.TypeDefinition, _a1 error) *MockIModelStore_Types_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_Types_Call) RunAndReturn(run func(context.Context) ([]*models.TypeDefinition, error)) *MockIModelStore_Types_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Version provides a mock function with given fields: ctx, path, method, version
+func (_m *MockIModelStore) Version(ctx context.Context, path string, method string, version int) (*models.ModelVersion, error) {
+	ret := _m.Called(ctx, path, method, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Version")
+	}
+
+	var r0 *models.ModelVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (*models.ModelVersion, error)); ok {
+		return rf(ctx, path, method, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) *models.ModelVersion); ok {
+		r0 = rf(ctx, path, method, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ModelVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, path, method, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_Version_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Version'
+type MockIModelStore_Version_Call struct {
+	*mock.Call
+}
+
+// Version is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+//   - version int
+func (_e *MockIModelStore_Expecter) Version(ctx interface{}, path interface{}, method interface{}, version interface{}) *MockIModelStore_Version_Call {
+	return &MockIModelStore_Version_Call{Call: _e.mock.On("Version", ctx, path, method, version)}
+}
+
+func (_c *MockIModelStore_Version_Call) Run(run func(ctx context.Context, path string, method string, version int)) *MockIModelStore_Version_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Version_Call) Return(_a0 *models.ModelVersion, _a1 error) *MockIModelStore_Version_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_Version_Call) RunAndReturn(run func(context.Context, string, string, int) (*models.ModelVersion, error)) *MockIModelStore_Version_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Versions provides a mock function with given fields: ctx, path, method
+func (_m *MockIModelStore) Versions(ctx context.Context, path string, method string) ([]*models.ModelVersion, error) {
+	ret := _m.Called(ctx, path, method)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Versions")
+	}
+
+	var r0 []*models.ModelVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*models.ModelVersion, error)); ok {
+		return rf(ctx, path, method)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.ModelVersion); ok {
+		r0 = rf(ctx, path, method)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ModelVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, path, method)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIModelStore_Versions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Versions'
+type MockIModelStore_Versions_Call struct {
+	*mock.Call
+}
+
+// Versions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - method string
+func (_e *MockIModelStore_Expecter) Versions(ctx interface{}, path interface{}, method interface{}) *MockIModelStore_Versions_Call {
+	return &MockIModelStore_Versions_Call{Call: _e.mock.On("Versions", ctx, path, method)}
+}
+
+func (_c *MockIModelStore_Versions_Call) Run(run func(ctx context.Context, path string, method string)) *MockIModelStore_Versions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockIModelStore_Versions_Call) Return(_a0 []*models.ModelVersion, _a1 error) *MockIModelStore_Versions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIModelStore_Versions_Call) RunAndReturn(run func(context.Context, string, string) ([]*models.ModelVersion, error)) *MockIModelStore_Versions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockIModelStore creates a new instance of MockIModelStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockIModelStore(t interface {
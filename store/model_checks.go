@@ -0,0 +1,266 @@
+package store
+
+import (
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"slices"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+)
+
+// checkModelParameters validates the parameter schemas of every section of a model.
+// isKnownType tells whether a type referenced by a parameter exists for the tenant of the model.
+func checkModelParameters(model *models.APIModel, isKnownType func(models.ParamType) bool) error {
+	switch model.UnexpectedParams {
+	case "", models.UnexpectedParamsAllow, models.UnexpectedParamsReport, models.UnexpectedParamsReject:
+	default:
+		return fmt.Errorf("invalid unexpected_params policy %q in model for path %s and method %s",
+			model.UnexpectedParams, model.Path, model.Method)
+	}
+
+	if err := checkEnforcement(model.Enforcement); err != nil {
+		return fmt.Errorf("invalid enforcement in model for path %s and method %s: %w", model.Path, model.Method, err)
+	}
+
+	for _, section := range model.Coerce {
+		if section != cSectionQueryParams && section != cSectionHeaders && section != cSectionCookies {
+			return fmt.Errorf("invalid coerce section %q in model for path %s and method %s: "+
+				"only query_params, headers and cookies can be coerced", section, model.Path, model.Method)
+		}
+	}
+
+	for _, section := range modelSections(model) {
+		err := checkNamedParameters(section.params, "", isKnownType)
+		if err == nil {
+			err = checkSectionNames(section)
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid %s in model for path %s and method %s: %w",
+				section.name, model.Path, model.Method, err)
+		}
+	}
+
+	return nil
+}
+
+// checkEnforcement rejects unknown actions, statuses that are not errors, and block settings of other actions
+func checkEnforcement(enforcement *models.Enforcement) error {
+	if enforcement == nil {
+		return nil
+	}
+
+	if !enforcement.Action.IsValid() {
+		return fmt.Errorf("unknown action %q", enforcement.Action)
+	}
+
+	if enforcement.Action != models.EnforcementBlock && (enforcement.BlockStatus != 0 || enforcement.BlockBody != nil) {
+		return fmt.Errorf("block_status and block_body only apply to the %s action", models.EnforcementBlock)
+	}
+
+	if enforcement.BlockStatus != 0 && (enforcement.BlockStatus < 400 || enforcement.BlockStatus > 599) {
+		return fmt.Errorf("block_status %d is not a 4xx or 5xx status", enforcement.BlockStatus)
+	}
+
+	return nil
+}
+
+// checkSectionNames rejects headers declared twice, as header names are case-insensitive, and repetition
+// outside of query params, headers and cookies, the only names a request may send more than once
+func checkSectionNames(section modelSection) error {
+	headers := make(map[string]struct{}, len(section.params))
+
+	for _, param := range section.params {
+		if param.Repeated && (section.name == cSectionPathParams || section.name == cSectionBody) {
+			return fmt.Errorf("parameter %q is repeated but only query params, headers and cookies can repeat",
+				param.Name)
+		}
+
+		if section.name != cSectionHeaders {
+			continue
+		}
+
+		name := textproto.CanonicalMIMEHeaderKey(param.Name)
+		if _, exists := headers[name]; exists {
+			return fmt.Errorf("header %q is declared twice", name)
+		}
+
+		headers[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// Names of the model sections, as in their JSON form
+const (
+	cSectionPathParams  = "path_params"
+	cSectionQueryParams = "query_params"
+	cSectionHeaders     = "headers"
+	cSectionCookies     = "cookies"
+	cSectionBody        = "body"
+)
+
+// modelSection is one of the parameter sections of a model, named as in its JSON form
+type modelSection struct {
+	name   string
+	params []*models.Parameter
+}
+
+// modelSections returns the parameter sections of a model in their JSON order
+func modelSections(model *models.APIModel) []modelSection {
+	return []modelSection{
+		{name: cSectionPathParams, params: model.PathParams},
+		{name: cSectionQueryParams, params: model.QueryParams},
+		{name: cSectionHeaders, params: model.Headers},
+		{name: cSectionCookies, params: model.Cookies},
+		{name: cSectionBody, params: model.Body},
+	}
+}
+
+// checkNamedParameters validates the parameters of a section or the properties of an object
+func checkNamedParameters(params []*models.Parameter, prefix string, isKnownType func(models.ParamType) bool) error {
+	for _, param := range params {
+		if param == nil || param.Name == "" {
+			return fmt.Errorf("parameter without a name under %q", prefix)
+		}
+
+		name := param.Name
+		if prefix != "" {
+			name = prefix + "." + param.Name
+		}
+
+		if err := checkParameter(param, name, isKnownType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkParameter validates a parameter schema and, recursively, its Object properties and List items
+func checkParameter(param *models.Parameter, name string, isKnownType func(models.ParamType) bool) error {
+	for _, paramType := range param.Types {
+		if !isKnownType(paramType) {
+			return fmt.Errorf("parameter %q references the unknown type %q", name, paramType)
+		}
+	}
+
+	if len(param.Properties) > 0 && !slices.Contains(param.Types, models.TypeObject) {
+		return fmt.Errorf("parameter %q declares properties but is not of type %s", name, models.TypeObject)
+	}
+
+	if param.Items != nil && !slices.Contains(param.Types, models.TypeList) {
+		return fmt.Errorf("parameter %q declares items but is not of type %s", name, models.TypeList)
+	}
+
+	if param.Severity != "" && !param.Severity.IsValid() {
+		return fmt.Errorf("parameter %q has an invalid severity %q", name, param.Severity)
+	}
+
+	if param.RiskWeight != nil && *param.RiskWeight < 0 {
+		return fmt.Errorf("parameter %q has a negative risk weight %v", name, *param.RiskWeight)
+	}
+
+	if err := checkConstraints(param, name); err != nil {
+		return err
+	}
+
+	if err := checkDateRules(param, name); err != nil {
+		return err
+	}
+
+	if err := checkJWTRules(param, name); err != nil {
+		return err
+	}
+
+	if err := checkNamedParameters(param.Properties, name, isKnownType); err != nil {
+		return err
+	}
+
+	if param.Items != nil {
+		return checkParameter(param.Items, name+"[]", isKnownType)
+	}
+
+	return nil
+}
+
+// checkDateRules rejects date formats and ranges on parameters that cannot hold a time, unknown formats
+// and ranges that can never be satisfied
+func checkDateRules(param *models.Parameter, name string) error {
+	if len(param.Formats) == 0 && !param.NotFuture && !param.NotPast &&
+		param.WithinLastDays == nil && param.WithinNextDays == nil {
+		return nil
+	}
+
+	if !slices.ContainsFunc(param.Types, models.IsTimeType) {
+		return fmt.Errorf("parameter %q declares date formats or ranges but is not of type %s or %s",
+			name, models.TypeDate, models.TypeTimestamp)
+	}
+
+	for _, format := range param.Formats {
+		if err := dateformat.Check(format); err != nil {
+			return fmt.Errorf("parameter %q has an invalid date format: %w", name, err)
+		}
+	}
+
+	if param.NotFuture && param.NotPast {
+		return fmt.Errorf("parameter %q cannot be both not_future and not_past", name)
+	}
+
+	if (param.WithinLastDays != nil && *param.WithinLastDays < 0) ||
+		(param.WithinNextDays != nil && *param.WithinNextDays < 0) {
+		return fmt.Errorf("parameter %q has a negative number of days", name)
+	}
+
+	return nil
+}
+
+// checkJWTRules rejects JWT rules on parameters that cannot hold a token, and algorithms that are never verified
+func checkJWTRules(param *models.Parameter, name string) error {
+	if param.JWT == nil {
+		return nil
+	}
+
+	if !slices.Contains(param.Types, models.TypeAuthToken) && !slices.Contains(param.Types, models.TypeJWT) {
+		return fmt.Errorf("parameter %q declares JWT rules but is not of type %s or %s",
+			name, models.TypeAuthToken, models.TypeJWT)
+	}
+
+	for _, alg := range param.JWT.Algorithms {
+		if !models.IsJWTAlgorithm(alg) {
+			return fmt.Errorf("parameter %q allows the unsupported JWT algorithm %q", name, alg)
+		}
+	}
+
+	if param.JWT.LeewaySeconds < 0 {
+		return fmt.Errorf("parameter %q has a negative JWT leeway %d", name, param.JWT.LeewaySeconds)
+	}
+
+	return nil
+}
+
+// checkConstraints rejects constraints that can never be satisfied or cannot be evaluated
+func checkConstraints(param *models.Parameter, name string) error {
+	if param.Minimum != nil && param.Maximum != nil && *param.Minimum > *param.Maximum {
+		return fmt.Errorf("parameter %q has minimum %v greater than maximum %v", name, *param.Minimum, *param.Maximum)
+	}
+
+	if (param.MinLength != nil && *param.MinLength < 0) || (param.MaxLength != nil && *param.MaxLength < 0) {
+		return fmt.Errorf("parameter %q has a negative length constraint", name)
+	}
+
+	if param.MinLength != nil && param.MaxLength != nil && *param.MinLength > *param.MaxLength {
+		return fmt.Errorf("parameter %q has min_length %d greater than max_length %d",
+			name, *param.MinLength, *param.MaxLength)
+	}
+
+	if param.Pattern != "" {
+		if _, err := regexp.Compile(param.Pattern); err != nil {
+			return fmt.Errorf("parameter %q has an invalid pattern: %w", name, err)
+		}
+	}
+
+	return nil
+}
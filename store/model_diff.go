@@ -0,0 +1,166 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+
+	"anomaly_detector/models"
+)
+
+// DiffModels lists the differences between two models, section by section. Parameters are matched by name,
+// and Object properties and List items are compared recursively under dotted names (address.city, tags[]).
+// A nil model, e.g. the version recording a deletion, has no parameters.
+func DiffModels(from, to *models.APIModel) []*models.ModelChange {
+	if from == nil {
+		from = &models.APIModel{}
+	}
+
+	if to == nil {
+		to = &models.APIModel{}
+	}
+
+	changes := []*models.ModelChange{}
+
+	if from.UnexpectedParams != to.UnexpectedParams {
+		changes = append(changes, &models.ModelChange{
+			Change: models.DiffPolicyChanged, Attribute: "unexpected_params",
+			From: from.UnexpectedParams, To: to.UnexpectedParams,
+		})
+	}
+
+	if !slices.Equal(from.Coerce, to.Coerce) {
+		changes = append(changes, &models.ModelChange{
+			Change: models.DiffPolicyChanged, Attribute: "coerce", From: from.Coerce, To: to.Coerce,
+		})
+	}
+
+	if !reflect.DeepEqual(from.Enforcement, to.Enforcement) {
+		changes = append(changes, &models.ModelChange{
+			Change: models.DiffPolicyChanged, Attribute: "enforcement",
+			From: from.Enforcement, To: to.Enforcement,
+		})
+	}
+
+	toSections := modelSections(to)
+	for i, section := range modelSections(from) {
+		changes = diffParameters(changes, section.name, "", section.params, toSections[i].params)
+	}
+
+	return changes
+}
+
+// diffParameters appends the differences between two parameter lists, in the order of the old list
+// followed by the parameters only present in the new one
+func diffParameters(
+	changes []*models.ModelChange, field, prefix string, from, to []*models.Parameter,
+) []*models.ModelChange {
+	toByName := make(map[string]*models.Parameter, len(to))
+	for _, param := range to {
+		toByName[param.Name] = param
+	}
+
+	fromNames := make(map[string]struct{}, len(from))
+
+	for _, param := range from {
+		fromNames[param.Name] = struct{}{}
+		name := prefix + param.Name
+
+		newParam, exists := toByName[param.Name]
+		if !exists {
+			changes = append(changes, &models.ModelChange{Field: field, Name: name, Change: models.DiffRemoved, From: param})
+			continue
+		}
+
+		changes = diffParameter(changes, field, name, param, newParam)
+	}
+
+	for _, param := range to {
+		if _, exists := fromNames[param.Name]; !exists {
+			changes = append(changes, &models.ModelChange{
+				Field: field, Name: prefix + param.Name, Change: models.DiffAdded, To: param,
+			})
+		}
+	}
+
+	return changes
+}
+
+// diffParameter appends the differences between two versions of the same parameter
+func diffParameter(
+	changes []*models.ModelChange, field, name string, from, to *models.Parameter,
+) []*models.ModelChange {
+	if !sameTypes(from.Types, to.Types) {
+		changes = append(changes, &models.ModelChange{
+			Field: field, Name: name, Change: models.DiffTypesChanged, From: from.Types, To: to.Types,
+		})
+	}
+
+	if from.Required != to.Required {
+		changes = append(changes, &models.ModelChange{
+			Field: field, Name: name, Change: models.DiffRequiredChanged, From: from.Required, To: to.Required,
+		})
+	}
+
+	constraints := []struct {
+		attribute string
+		from, to  any
+	}{
+		{"minimum", from.Minimum, to.Minimum},
+		{"maximum", from.Maximum, to.Maximum},
+		{"min_length", from.MinLength, to.MinLength},
+		{"max_length", from.MaxLength, to.MaxLength},
+		{"pattern", from.Pattern, to.Pattern},
+		{"enum", from.Enum, to.Enum},
+		{"repeated", from.Repeated, to.Repeated},
+		{"nullable", from.Nullable, to.Nullable},
+		{"formats", from.Formats, to.Formats},
+		{"not_future", from.NotFuture, to.NotFuture},
+		{"not_past", from.NotPast, to.NotPast},
+		{"within_last_days", from.WithinLastDays, to.WithinLastDays},
+		{"within_next_days", from.WithinNextDays, to.WithinNextDays},
+		{"severity", from.Severity, to.Severity},
+		{"risk_weight", from.RiskWeight, to.RiskWeight},
+		{"jwt", from.JWT, to.JWT},
+	}
+
+	for _, constraint := range constraints {
+		if !reflect.DeepEqual(constraint.from, constraint.to) {
+			changes = append(changes, &models.ModelChange{
+				Field: field, Name: name, Change: models.DiffConstraintsChanged,
+				Attribute: constraint.attribute, From: constraint.from, To: constraint.to,
+			})
+		}
+	}
+
+	changes = diffParameters(changes, field, name+".", from.Properties, to.Properties)
+
+	switch {
+	case from.Items != nil && to.Items != nil:
+		changes = diffParameter(changes, field, name+"[]", from.Items, to.Items)
+	case from.Items != nil:
+		changes = append(changes, &models.ModelChange{
+			Field: field, Name: name + "[]", Change: models.DiffRemoved, From: from.Items,
+		})
+	case to.Items != nil:
+		changes = append(changes, &models.ModelChange{
+			Field: field, Name: name + "[]", Change: models.DiffAdded, To: to.Items,
+		})
+	}
+
+	return changes
+}
+
+// sameTypes compares type lists regardless of their order
+func sameTypes(from, to []models.ParamType) bool {
+	if len(from) != len(to) {
+		return false
+	}
+
+	for _, paramType := range from {
+		if !slices.Contains(to, paramType) {
+			return false
+		}
+	}
+
+	return true
+}
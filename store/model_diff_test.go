@@ -0,0 +1,108 @@
+package store
+
+import (
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffModels(t *testing.T) {
+	tMaxLength := 10
+
+	tFrom := &models.APIModel{
+		Path:   "/users",
+		Method: "POST",
+		QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+			{Name: "verbose", Types: []models.ParamType{models.TypeBoolean}},
+		},
+		Body: []*models.Parameter{
+			{
+				Name: "address", Types: []models.ParamType{models.TypeObject},
+				Properties: []*models.Parameter{{Name: "city", Types: []models.ParamType{models.TypeString}}},
+			},
+			{
+				Name: "tags", Types: []models.ParamType{models.TypeList},
+				Items: &models.Parameter{Types: []models.ParamType{models.TypeString}},
+			},
+		},
+	}
+
+	tTo := &models.APIModel{
+		Path:             "/users",
+		Method:           "POST",
+		UnexpectedParams: models.UnexpectedParamsReject,
+		QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeUUID, models.TypeInt}},
+			{Name: "page", Types: []models.ParamType{models.TypeInt}},
+		},
+		Body: []*models.Parameter{
+			{
+				Name: "address", Types: []models.ParamType{models.TypeObject},
+				Properties: []*models.Parameter{
+					{Name: "city", Types: []models.ParamType{models.TypeString}, MaxLength: &tMaxLength},
+				},
+			},
+			{Name: "tags", Types: []models.ParamType{models.TypeList}},
+		},
+	}
+
+	t.Run("list changes by section and dotted name", func(t *testing.T) {
+		changes := DiffModels(tFrom, tTo)
+
+		assert.Equal(t, []*models.ModelChange{
+			{
+				Change: models.DiffPolicyChanged, Attribute: "unexpected_params",
+				From: models.UnexpectedParamsPolicy(""), To: models.UnexpectedParamsReject,
+			},
+			{
+				Field: "query_params", Name: "id", Change: models.DiffTypesChanged,
+				From: tFrom.QueryParams[0].Types, To: tTo.QueryParams[0].Types,
+			},
+			{Field: "query_params", Name: "id", Change: models.DiffRequiredChanged, From: true, To: false},
+			{Field: "query_params", Name: "verbose", Change: models.DiffRemoved, From: tFrom.QueryParams[1]},
+			{Field: "query_params", Name: "page", Change: models.DiffAdded, To: tTo.QueryParams[1]},
+			{
+				Field: "body", Name: "address.city", Change: models.DiffConstraintsChanged,
+				Attribute: "max_length", From: (*int)(nil), To: &tMaxLength,
+			},
+			{Field: "body", Name: "tags[]", Change: models.DiffRemoved, From: tFrom.Body[1].Items},
+		}, changes)
+	})
+
+	t.Run("identical models and type order have no changes", func(t *testing.T) {
+		reordered := &models.APIModel{
+			QueryParams: []*models.Parameter{{Name: "id", Types: []models.ParamType{models.TypeInt, models.TypeUUID}}},
+		}
+		assert.Empty(t, DiffModels(tTo, tTo))
+		assert.Empty(t, DiffModels(&models.APIModel{QueryParams: tTo.QueryParams[:1]}, reordered))
+	})
+
+	t.Run("coerced sections change", func(t *testing.T) {
+		changes := DiffModels(&models.APIModel{Coerce: []string{"headers"}}, &models.APIModel{})
+		assert.Equal(t, []*models.ModelChange{
+			{Change: models.DiffPolicyChanged, Attribute: "coerce", From: []string{"headers"}, To: []string(nil)},
+		}, changes)
+	})
+
+	t.Run("enforcement change", func(t *testing.T) {
+		tBlock := &models.Enforcement{Action: models.EnforcementBlock}
+
+		changes := DiffModels(&models.APIModel{}, &models.APIModel{Enforcement: tBlock})
+		assert.Equal(t, []*models.ModelChange{
+			{
+				Change: models.DiffPolicyChanged, Attribute: "enforcement",
+				From: (*models.Enforcement)(nil), To: tBlock,
+			},
+		}, changes)
+	})
+
+	t.Run("a deletion removes every parameter", func(t *testing.T) {
+		changes := DiffModels(&models.APIModel{Headers: []*models.Parameter{{Name: "X-Id"}}}, nil)
+		assert.Equal(t, []*models.ModelChange{
+			{Field: "headers", Name: "X-Id", Change: models.DiffRemoved, From: &models.Parameter{Name: "X-Id"}},
+		}, changes)
+	})
+}
@@ -2,81 +2,643 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"anomaly_detector/models"
+	"anomaly_detector/pathtemplate"
+	"anomaly_detector/tenant"
 )
 
+var (
+	// ErrModelNotFound is returned (wrapped) when no model is registered for a path and method
+	ErrModelNotFound = errors.New("model not found")
+	// ErrVersionNotFound is returned (wrapped) when a path and method have no such version
+	ErrVersionNotFound = errors.New("model version not found")
+	// ErrRevisionMismatch is returned (wrapped) when a write expects a revision that is no longer active
+	ErrRevisionMismatch = errors.New("model revision mismatch")
+	// ErrTenantNotFound is returned (wrapped) when nothing was ever stored for a tenant
+	ErrTenantNotFound = errors.New("tenant not found")
+)
+
+// AnyRevision makes Replace and Delete skip the revision check
+const AnyRevision = 0
+
+// IModelStore holds the active model of every path and method, along with the immutable history of versions
+// that produced it. Every write records a new version with the Change carried by the context.
+// Every call is scoped to the tenant carried by the context: tenants have separate keyspaces and histories.
+// The revision of an active model is the number of the version that produced it; Replace and Delete
+// only apply if the given revision is still active (or AnyRevision), checked atomically with the write.
+// Models may only reference known types: built-in, registered in Go, or defined for the tenant with DefineType.
+// StoreAll and Replace upper-case the methods of the models, lookups expect the upper-case method.
 type IModelStore interface {
 	StoreAll(ctx context.Context, models []*models.APIModel) (bool, error)
 	Get(ctx context.Context, path, method string) (*models.APIModel, error)
+	GetRevision(ctx context.Context, path, method string) (*models.APIModel, int, error)
+	Match(ctx context.Context, path, method string) (*models.APIModel, error)
+	List(ctx context.Context, pathPrefix, method string) ([]*models.APIModel, error)
+	Replace(ctx context.Context, model *models.APIModel, revision int) (int, bool, error)
+	Delete(ctx context.Context, path, method string, revision int) (bool, error)
+	Versions(ctx context.Context, path, method string) ([]*models.ModelVersion, error)
+	Version(ctx context.Context, path, method string, version int) (*models.ModelVersion, error)
+	Rollback(ctx context.Context, path, method string, version, revision int) (int, bool, error)
+	DeleteTenant(ctx context.Context) (bool, error)
+	DefineType(ctx context.Context, definition *models.TypeDefinition) (bool, error)
+	Types(ctx context.Context) ([]*models.TypeDefinition, error)
 }
 
 type modelStore struct {
-	mu     sync.RWMutex
+	mu sync.RWMutex
+	// namespaces isolates the models of every tenant, no lookup ever crosses from one to another
+	namespaces map[string]*namespace
+	now        func() time.Time
+}
+
+// namespace holds the models, history and custom types of a single tenant
+type namespace struct {
+	tenant string
 	models map[string]*models.APIModel
+	routes *routeIndex
+	// versions holds the history of every path and method, including deleted ones
+	versions map[string][]*models.ModelVersion
+	types    map[models.ParamType]*models.TypeDefinition
 }
 
 func NewModelStore() IModelStore {
+	return newModelStore()
+}
+
+func newModelStore() *modelStore {
 	return &modelStore{
-		models: make(map[string]*models.APIModel),
+		namespaces: make(map[string]*namespace),
+		now:        time.Now,
 	}
 }
 
-func (s *modelStore) store(ctx context.Context, apiModel *models.APIModel) {
+func newNamespace(name string) *namespace {
+	return &namespace{
+		tenant:   name,
+		models:   make(map[string]*models.APIModel),
+		routes:   newRouteIndex(),
+		versions: make(map[string][]*models.ModelVersion),
+		types:    make(map[models.ParamType]*models.TypeDefinition),
+	}
+}
+
+// namespaceOf returns the namespace of the tenant of ctx, or an empty one that is not kept if the tenant
+// has nothing stored yet. The caller must hold the lock.
+func (s *modelStore) namespaceOf(ctx context.Context) *namespace {
+	name := tenant.FromContext(ctx)
+
+	if ns, exists := s.namespaces[name]; exists {
+		return ns
+	}
+
+	return newNamespace(name)
+}
+
+// writableNamespaceOf returns the namespace of the tenant of ctx, creating it. The caller must hold the write lock.
+func (s *modelStore) writableNamespaceOf(ctx context.Context) *namespace {
+	ns := s.namespaceOf(ctx)
+	s.namespaces[ns.tenant] = ns
+
+	return ns
+}
+
+func (ns *namespace) store(ctx context.Context, apiModel *models.APIModel) {
 	key := getKey(apiModel.Path, apiModel.Method)
 
-	s.models[key] = apiModel
+	ns.models[key] = apiModel
+	ns.routes.insert(apiModel.Path, apiModel.Method, key)
 
 	slog.InfoContext(ctx, "Model stored", "path", apiModel.Path, "method", apiModel.Method)
 }
 
+// record appends a version to the history of a path and method. The caller must hold the lock.
+func (ns *namespace) record(
+	path, method string, changeType models.ChangeType, model *models.APIModel, change Change, rolledBackFrom int,
+) int {
+	key := getKey(path, method)
+	version := len(ns.versions[key]) + 1
+
+	ns.versions[key] = append(ns.versions[key], &models.ModelVersion{
+		Tenant:         ns.tenant,
+		Path:           path,
+		Method:         method,
+		Version:        version,
+		Change:         changeType,
+		Author:         change.Author,
+		Comment:        change.Comment,
+		CreatedAt:      change.At,
+		RolledBackFrom: rolledBackFrom,
+		Model:          model,
+	})
+
+	return version
+}
+
 // StoreAll stores multiple API models and returns a bool indicating whether any error
 // was caused by user input (true) or an internal server error (false).
 // Currently, only user input errors are possible, but this may change in the future to support database storage.
 func (s *modelStore) StoreAll(ctx context.Context, apiModels []*models.APIModel) (bool, error) {
+	normalizeMethods(apiModels...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.namespaceOf(ctx).checkStoreAll(apiModels); err != nil {
+		return true, err
+	}
+
+	s.writableNamespaceOf(ctx).storeAll(ctx, apiModels, changeFromContext(ctx, s.now))
+
+	return true, nil
+}
+
+// storeAll stores a checked batch as new models. The caller must hold the lock.
+func (ns *namespace) storeAll(ctx context.Context, apiModels []*models.APIModel, change Change) {
+	for _, apiModel := range apiModels {
+		ns.store(ctx, apiModel)
+		ns.record(apiModel.Path, apiModel.Method, models.ChangeCreated, apiModel, change, 0)
+	}
+}
+
+// checkStoreAll validates a batch against the current state. The caller must hold the lock.
+func (ns *namespace) checkStoreAll(apiModels []*models.APIModel) error {
+	batchRoutes := make(map[string]struct{}, len(apiModels))
+
 	for _, model := range apiModels {
-		if model == nil || model.Path == "" || model.Method == "" {
-			return true, fmt.Errorf("invalid model in batch")
+		if !isValidModel(model) {
+			return fmt.Errorf("invalid model in batch")
+		}
+
+		if err := checkPathTemplate(model); err != nil {
+			return err
+		}
+
+		if err := checkModelParameters(model, ns.isKnownType); err != nil {
+			return err
 		}
 
 		key := getKey(model.Path, model.Method)
-		if _, exists := s.models[key]; exists {
-			return true, fmt.Errorf("model already exists for path %s and method %s", model.Path, model.Method)
+		if _, exists := ns.models[key]; exists {
+			return fmt.Errorf("model already exists for path %s and method %s", model.Path, model.Method)
 		}
-	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+		if existing := ns.routes.lookup(model.Path, model.Method); existing != "" {
+			return fmt.Errorf("path template %s conflicts with existing model %s", model.Path, existing)
+		}
 
-	for _, apiModel := range apiModels {
-		s.store(ctx, apiModel)
+		// Templates differing only by variable names (e.g. /users/{id} and /users/{user_id}) are the same route
+		route := getKey(routeShape(model.Path), model.Method)
+		if _, exists := batchRoutes[route]; exists {
+			return fmt.Errorf("duplicate model in batch for path %s and method %s", model.Path, model.Method)
+		}
+
+		batchRoutes[route] = struct{}{}
 	}
 
-	return true, nil
+	return nil
 }
 
 func (s *modelStore) Get(ctx context.Context, path, method string) (*models.APIModel, error) {
+	model, _, err := s.GetRevision(ctx, path, method)
+
+	return model, err
+}
+
+// GetRevision returns the active model of a path and method along with its revision
+func (s *modelStore) GetRevision(ctx context.Context, path, method string) (*models.APIModel, int, error) {
 	slog.InfoContext(ctx, "Getting model", "path", path, "method", method)
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	ns := s.namespaceOf(ctx)
 	key := getKey(path, method)
 
-	model, exists := s.models[key]
+	model, exists := ns.models[key]
 	if !exists {
-		return nil, fmt.Errorf("model not found for path %s and method %s", path, method)
+		return nil, 0, fmt.Errorf("%w for path %s and method %s", ErrModelNotFound, path, method)
 	}
 
 	slog.InfoContext(ctx, "Model retrieved", "path", path, "method", method)
 
+	return model, len(ns.versions[key]), nil
+}
+
+// Match resolves a concrete request path to the stored model with the most specific matching path template
+func (s *modelStore) Match(ctx context.Context, path, method string) (*models.APIModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ns := s.namespaceOf(ctx)
+
+	if model, exists := ns.models[getKey(path, method)]; exists {
+		return model, nil
+	}
+
+	key, found := ns.routes.match(path, method)
+	if !found {
+		return nil, fmt.Errorf("%w for path %s and method %s", ErrModelNotFound, path, method)
+	}
+
+	model := ns.models[key]
+
+	slog.DebugContext(ctx, "Model matched", "path", path, "method", method, "template", model.Path)
+
 	return model, nil
 }
 
+// List returns the stored models whose path starts with pathPrefix and whose method matches method.
+// Empty filters match everything. Results are sorted by path and then by method.
+func (s *modelStore) List(ctx context.Context, pathPrefix, method string) ([]*models.APIModel, error) {
+	slog.InfoContext(ctx, "Listing models", "path_prefix", pathPrefix, "method", method)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ns := s.namespaceOf(ctx)
+	result := make([]*models.APIModel, 0, len(ns.models))
+
+	for _, model := range ns.models {
+		if !strings.HasPrefix(model.Path, pathPrefix) {
+			continue
+		}
+
+		if method != "" && !strings.EqualFold(model.Method, method) {
+			continue
+		}
+
+		result = append(result, model)
+	}
+
+	sortModels(result)
+
+	return result, nil
+}
+
+// Replace overwrites an existing model identified by its path and method, if revision is still active,
+// and returns the revision of the replacement.
+// The returned bool follows the StoreAll convention: true for user errors, false for internal errors.
+func (s *modelStore) Replace(ctx context.Context, model *models.APIModel, revision int) (int, bool, error) {
+	normalizeMethods(model)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns := s.namespaceOf(ctx)
+
+	if err := ns.checkReplace(model, revision); err != nil {
+		return 0, true, err
+	}
+
+	return ns.replace(ctx, model, changeFromContext(ctx, s.now)), true, nil
+}
+
+// replace stores a checked replacement as a new version. The caller must hold the lock.
+func (ns *namespace) replace(ctx context.Context, model *models.APIModel, change Change) int {
+	ns.store(ctx, model)
+
+	return ns.record(model.Path, model.Method, models.ChangeReplaced, model, change, 0)
+}
+
+// checkReplace validates a replacement against the current state. The caller must hold the lock.
+func (ns *namespace) checkReplace(model *models.APIModel, revision int) error {
+	if !isValidModel(model) {
+		return fmt.Errorf("invalid model")
+	}
+
+	if err := checkPathTemplate(model); err != nil {
+		return err
+	}
+
+	if err := checkModelParameters(model, ns.isKnownType); err != nil {
+		return err
+	}
+
+	return ns.checkRevision(model.Path, model.Method, revision)
+}
+
+// Delete removes the model identified by path and method, if revision is still active.
+// The returned bool follows the StoreAll convention: true for user errors, false for internal errors.
+func (s *modelStore) Delete(ctx context.Context, path, method string, revision int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns := s.namespaceOf(ctx)
+
+	if err := ns.checkRevision(path, method, revision); err != nil {
+		return true, err
+	}
+
+	ns.remove(ctx, path, method, changeFromContext(ctx, s.now))
+
+	return true, nil
+}
+
+// remove deletes a model and records the deletion as a new version. The caller must hold the lock.
+func (ns *namespace) remove(ctx context.Context, path, method string, change Change) {
+	delete(ns.models, getKey(path, method))
+	ns.routes.remove(path, method)
+	ns.record(path, method, models.ChangeDeleted, nil, change, 0)
+
+	slog.InfoContext(ctx, "Model deleted", "path", path, "method", method)
+}
+
+// DeleteTenant removes every model of the tenant of ctx along with their history and its custom types.
+// The returned bool follows the StoreAll convention: true for user errors, false for internal errors.
+func (s *modelStore) DeleteTenant(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := tenant.FromContext(ctx)
+
+	if err := s.checkTenantExists(name); err != nil {
+		return true, err
+	}
+
+	delete(s.namespaces, name)
+
+	slog.InfoContext(ctx, "Tenant deleted")
+
+	return true, nil
+}
+
+// checkTenantExists returns ErrTenantNotFound (wrapped) if nothing was ever stored for a tenant.
+// The caller must hold the lock.
+func (s *modelStore) checkTenantExists(name string) error {
+	if _, exists := s.namespaces[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrTenantNotFound, name)
+	}
+
+	return nil
+}
+
+// Versions returns the history of a path and method, oldest first. Deleted models keep their history.
+func (s *modelStore) Versions(ctx context.Context, path, method string) ([]*models.ModelVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history, exists := s.namespaceOf(ctx).versions[getKey(path, method)]
+	if !exists {
+		return nil, fmt.Errorf("%w for path %s and method %s", ErrModelNotFound, path, method)
+	}
+
+	return append([]*models.ModelVersion{}, history...), nil
+}
+
+// Version returns a single version of a path and method
+func (s *modelStore) Version(ctx context.Context, path, method string, version int) (*models.ModelVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.namespaceOf(ctx).findVersion(path, method, version)
+}
+
+// findVersion looks up a version of a path and method. The caller must hold the lock.
+func (ns *namespace) findVersion(path, method string, version int) (*models.ModelVersion, error) {
+	history, exists := ns.versions[getKey(path, method)]
+	if !exists {
+		return nil, fmt.Errorf("%w for path %s and method %s", ErrModelNotFound, path, method)
+	}
+
+	if version < 1 || version > len(history) {
+		return nil, fmt.Errorf("%w: %d for path %s and method %s", ErrVersionNotFound, version, path, method)
+	}
+
+	return history[version-1], nil
+}
+
+// Rollback makes the model of an older version active again by recording it as a new version,
+// so that the history itself is never rewritten. A deleted model can be restored this way.
+// It returns the revision of the new version. Unless revision is AnyRevision, it fails with ErrRevisionMismatch
+// when the latest version, which may record a deletion, is not revision.
+// The returned bool follows the StoreAll convention: true for user errors, false for internal errors.
+func (s *modelStore) Rollback(ctx context.Context, path, method string, version, revision int) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns := s.namespaceOf(ctx)
+
+	model, err := ns.checkRollback(path, method, version, revision)
+	if err != nil {
+		return 0, true, err
+	}
+
+	return ns.rollback(ctx, path, method, model, version, changeFromContext(ctx, s.now)), true, nil
+}
+
+// rollback makes the checked model of an older version active again. The caller must hold the lock.
+func (ns *namespace) rollback(
+	ctx context.Context, path, method string, model *models.APIModel, version int, change Change,
+) int {
+	ns.store(ctx, model)
+
+	return ns.record(path, method, models.ChangeRolledBack, model, change, version)
+}
+
+// checkRollback validates a rollback and returns the model to restore. The caller must hold the lock.
+func (ns *namespace) checkRollback(path, method string, version, revision int) (*models.APIModel, error) {
+	target, err := ns.findVersion(path, method, version)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := len(ns.versions[getKey(path, method)])
+	if revision != AnyRevision && revision != latest {
+		return nil, fmt.Errorf("%w: model for path %s and method %s is at revision %d, not %d",
+			ErrRevisionMismatch, path, method, latest, revision)
+	}
+
+	if target.Model == nil {
+		return nil, fmt.Errorf("version %d records a deletion and cannot be restored", version)
+	}
+
+	if version == latest {
+		return nil, fmt.Errorf("version %d is already active", version)
+	}
+
+	// The checks may have become stricter since the version was stored
+	if err := checkModelParameters(target.Model, ns.isKnownType); err != nil {
+		return nil, err
+	}
+
+	// A deleted model's route may have been taken by another template in the meantime
+	if _, active := ns.models[getKey(path, method)]; !active {
+		if existing := ns.routes.lookup(path, method); existing != "" {
+			return nil, fmt.Errorf("path template %s conflicts with existing model %s", path, existing)
+		}
+	}
+
+	return target.Model, nil
+}
+
+// checkExists returns ErrModelNotFound (wrapped) if no model is stored for path and method.
+// The caller must hold the lock.
+func (ns *namespace) checkExists(path, method string) error {
+	if _, exists := ns.models[getKey(path, method)]; !exists {
+		return fmt.Errorf("%w for path %s and method %s", ErrModelNotFound, path, method)
+	}
+
+	return nil
+}
+
+// checkRevision returns ErrModelNotFound (wrapped) if no model is stored for path and method, and
+// ErrRevisionMismatch (wrapped) if revision is not the active one. The caller must hold the lock.
+func (ns *namespace) checkRevision(path, method string, revision int) error {
+	if err := ns.checkExists(path, method); err != nil {
+		return err
+	}
+
+	active := len(ns.versions[getKey(path, method)])
+	if revision != AnyRevision && revision != active {
+		return fmt.Errorf("%w: model for path %s and method %s is at revision %d, not %d",
+			ErrRevisionMismatch, path, method, active, revision)
+	}
+
+	return nil
+}
+
+// modelCount returns the number of active models across all tenants. The caller must hold the lock.
+func (s *modelStore) modelCount() int {
+	count := 0
+	for _, ns := range s.namespaces {
+		count += len(ns.models)
+	}
+
+	return count
+}
+
+// snapshot returns every version sorted by tenant, path, method and version. The caller must hold the lock.
+func (s *modelStore) snapshot() []*models.ModelVersion {
+	var result []*models.ModelVersion
+
+	for _, ns := range s.namespaces {
+		for _, history := range ns.versions {
+			result = append(result, history...)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Tenant != result[j].Tenant {
+			return result[i].Tenant < result[j].Tenant
+		}
+
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+
+		if result[i].Method != result[j].Method {
+			return result[i].Method < result[j].Method
+		}
+
+		return result[i].Version < result[j].Version
+	})
+
+	return result
+}
+
+// restore replaces the whole state with the given versions, sorted as returned by snapshot, and the custom
+// types of every tenant, as returned by typeSnapshot.
+// The latest version of every path and method is active unless it records a deletion.
+// Versions without a tenant were stored before tenants existed and belong to the default tenant.
+// The caller must hold the lock.
+func (s *modelStore) restore(versions []*models.ModelVersion, types map[string][]*models.TypeDefinition) {
+	s.namespaces = make(map[string]*namespace)
+
+	for name, definitions := range types {
+		ns := s.restoredNamespace(name)
+		for _, definition := range definitions {
+			ns.types[definition.Name] = definition
+		}
+	}
+
+	for _, version := range versions {
+		ns := s.restoredNamespace(version.Tenant)
+		key := getKey(version.Path, version.Method)
+		ns.versions[key] = append(ns.versions[key], version)
+	}
+
+	for _, ns := range s.namespaces {
+		for key, history := range ns.versions {
+			latest := history[len(history)-1]
+			if latest.Model == nil {
+				continue
+			}
+
+			ns.models[key] = latest.Model
+			ns.routes.insert(latest.Path, latest.Method, key)
+		}
+	}
+}
+
+// restoredNamespace returns the namespace of a tenant being restored, creating it. The caller must hold the lock.
+func (s *modelStore) restoredNamespace(name string) *namespace {
+	ns, exists := s.namespaces[name]
+	if !exists {
+		ns = newNamespace(name)
+		s.namespaces[name] = ns
+	}
+
+	return ns
+}
+
+func isValidModel(model *models.APIModel) bool {
+	return model != nil && model.Path != "" && model.Method != ""
+}
+
+// normalizeMethods upper-cases the methods of the models, the routes and lookups address models by the
+// upper-case method, so a model posted with "get" is stored as GET.
+func normalizeMethods(apiModels ...*models.APIModel) {
+	for _, model := range apiModels {
+		if model != nil {
+			model.Method = strings.ToUpper(model.Method)
+		}
+	}
+}
+
+// checkPathTemplate validates the path template and ensures every path parameter names a template variable
+func checkPathTemplate(model *models.APIModel) error {
+	if err := pathtemplate.Validate(model.Path); err != nil {
+		return err
+	}
+
+	variables := make(map[string]struct{})
+	for _, name := range pathtemplate.Params(model.Path) {
+		variables[name] = struct{}{}
+	}
+
+	for _, param := range model.PathParams {
+		if param == nil {
+			return fmt.Errorf("invalid path parameter in model for path %s", model.Path)
+		}
+
+		if _, exists := variables[param.Name]; !exists {
+			return fmt.Errorf("path parameter %q is not part of the path template %s", param.Name, model.Path)
+		}
+	}
+
+	return nil
+}
+
 // getKey returns a unique identifier for this API model
 func getKey(path, method string) string {
 	return fmt.Sprintf("%s:%s", path, method)
 }
+
+// sortModels orders models by path and then by method
+func sortModels(apiModels []*models.APIModel) {
+	sort.Slice(apiModels, func(i, j int) bool {
+		if apiModels[i].Path != apiModels[j].Path {
+			return apiModels[i].Path < apiModels[j].Path
+		}
+
+		return apiModels[i].Method < apiModels[j].Method
+	})
+}
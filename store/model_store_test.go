@@ -2,11 +2,15 @@ package store
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"anomaly_detector/models"
+	"anomaly_detector/tenant"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGet(t *testing.T) {
@@ -124,4 +128,822 @@ func TestStoreAll(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, ok)
 	})
+
+	t.Run("fail on unknown unexpected params policy", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "GET", UnexpectedParams: "block"}})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on coercing the body", func(t *testing.T) {
+		ok, err := NewModelStore().StoreAll(context.Background(), []*models.APIModel{
+			{Path: "/users", Method: "GET", Coerce: []string{"query_params", "body"}},
+		})
+		assert.ErrorContains(t, err, `invalid coerce section "body"`)
+		assert.True(t, ok)
+	})
+
+	t.Run("check enforcement", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			enforcement *models.Enforcement
+			err         string
+		}{
+			{name: "block with status and body", enforcement: &models.Enforcement{
+				Action: models.EnforcementBlock, BlockStatus: http.StatusTooManyRequests, BlockBody: map[string]any{"e": 1},
+			}},
+			{name: "annotate", enforcement: &models.Enforcement{Action: models.EnforcementAnnotate}},
+			{name: "unknown action", enforcement: &models.Enforcement{Action: "drop"}, err: `unknown action "drop"`},
+			{
+				name:        "block status of another action",
+				enforcement: &models.Enforcement{Action: models.EnforcementPass, BlockStatus: http.StatusForbidden},
+				err:         "only apply to the block action",
+			},
+			{
+				name:        "block status that is not an error",
+				enforcement: &models.Enforcement{Action: models.EnforcementBlock, BlockStatus: http.StatusOK},
+				err:         "block_status 200 is not a 4xx or 5xx status",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ok, err := NewModelStore().StoreAll(context.Background(), []*models.APIModel{
+					{Path: "/users", Method: "GET", Enforcement: tt.enforcement},
+				})
+
+				if tt.err == "" {
+					assert.NoError(t, err)
+					return
+				}
+
+				assert.ErrorContains(t, err, tt.err)
+				assert.True(t, ok)
+			})
+		}
+	})
+
+	t.Run("fail on headers differing only in case", func(t *testing.T) {
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(context.Background(), []*models.APIModel{{
+			Path: "/users", Method: "GET",
+			Headers: []*models.Parameter{
+				{Name: "X-Request-Id", Types: []models.ParamType{models.TypeUUID}},
+				{Name: "x-request-id", Types: []models.ParamType{models.TypeString}},
+			},
+		}})
+		assert.ErrorContains(t, err, `header "X-Request-Id" is declared twice`)
+		assert.True(t, ok)
+	})
+
+	t.Run("repetition is limited to query params and headers", func(t *testing.T) {
+		tStore := NewModelStore()
+		tRepeated := []*models.Parameter{{Name: "tag", Types: []models.ParamType{models.TypeString}, Repeated: true}}
+
+		ok, err := tStore.StoreAll(context.Background(), []*models.APIModel{
+			{Path: "/users", Method: "GET", QueryParams: tRepeated, Headers: tRepeated},
+		})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = tStore.StoreAll(context.Background(), []*models.APIModel{
+			{Path: "/orders", Method: "POST", Body: tRepeated},
+		})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("methods are stored in upper case", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "get"}})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		retrieved, err := tStore.Get(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		assert.Equal(t, "GET", retrieved.Method)
+
+		ok, err = tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "GET"}})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on duplicate model within batch", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		batch := []*models.APIModel{
+			{Path: "/users", Method: "GET"},
+			{Path: "/users", Method: "GET"},
+		}
+		ok, err := tStore.StoreAll(ctx, batch)
+		assert.Error(t, err)
+		assert.True(t, ok)
+
+		_, err = tStore.Get(ctx, "/users", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+}
+
+func TestList(t *testing.T) {
+	tModels := []*models.APIModel{
+		{Path: "/users", Method: "POST"},
+		{Path: "/users", Method: "GET"},
+		{Path: "/users/info", Method: "GET"},
+		{Path: "/products", Method: "GET"},
+	}
+
+	ctx := context.Background()
+	tStore := NewModelStore()
+
+	ok, err := tStore.StoreAll(ctx, tModels)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	t.Run("no filters returns all models sorted", func(t *testing.T) {
+		result, err := tStore.List(ctx, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.APIModel{tModels[3], tModels[1], tModels[0], tModels[2]}, result)
+	})
+
+	t.Run("filter by path prefix", func(t *testing.T) {
+		result, err := tStore.List(ctx, "/users", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.APIModel{tModels[1], tModels[0], tModels[2]}, result)
+	})
+
+	t.Run("filter by method is case insensitive", func(t *testing.T) {
+		result, err := tStore.List(ctx, "/users", "get")
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.APIModel{tModels[1], tModels[2]}, result)
+	})
+
+	t.Run("no matches returns empty slice", func(t *testing.T) {
+		result, err := tStore.List(ctx, "/orders", "")
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestReplace(t *testing.T) {
+	t.Run("success replacing existing model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "GET"}})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		replacement := &models.APIModel{
+			Path:   "/users",
+			Method: "GET",
+			QueryParams: []*models.Parameter{
+				{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+			},
+		}
+
+		revision, ok, err := tStore.Replace(ctx, replacement, AnyRevision)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 2, revision)
+
+		retrieved, err := tStore.Get(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		assert.Equal(t, replacement, retrieved)
+	})
+
+	t.Run("success replacing with a lower case method", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "GET"}})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		_, ok, err = tStore.Replace(ctx, &models.APIModel{Path: "/users", Method: "get"}, AnyRevision)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		versions, err := tStore.Versions(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		assert.Len(t, versions, 2)
+	})
+
+	t.Run("fail on missing model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		_, ok, err := tStore.Replace(ctx, &models.APIModel{Path: "/users", Method: "GET"}, AnyRevision)
+		assert.ErrorIs(t, err, ErrModelNotFound)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on invalid model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		_, ok, err := tStore.Replace(ctx, &models.APIModel{Path: "/users"}, AnyRevision)
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("success deleting existing model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users", Method: "GET"}})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = tStore.Delete(ctx, "/users", "GET", AnyRevision)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = tStore.Get(ctx, "/users", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("fail on missing model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.Delete(ctx, "/users", "GET", AnyRevision)
+		assert.ErrorIs(t, err, ErrModelNotFound)
+		assert.True(t, ok)
+	})
+}
+
+func TestMatch(t *testing.T) {
+	tModels := []*models.APIModel{
+		{Path: "/users/{user_id}", Method: "GET"},
+		{Path: "/users/me", Method: "GET"},
+		{Path: "/users/{user_id}/orders/{order_id}", Method: "GET"},
+		{Path: "/users/{user_id}/orders/latest", Method: "GET"},
+	}
+
+	ctx := context.Background()
+	tStore := NewModelStore()
+
+	ok, err := tStore.StoreAll(ctx, tModels)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected *models.APIModel
+	}{
+		{name: "template variable", path: "/users/123", expected: tModels[0]},
+		{name: "literal wins over variable", path: "/users/me", expected: tModels[1]},
+		{name: "nested template", path: "/users/123/orders/456", expected: tModels[2]},
+		{name: "most specific nested template", path: "/users/123/orders/latest", expected: tModels[3]},
+		{name: "literal segment backtracks to variable", path: "/users/me/orders/456", expected: tModels[2]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retrieved, err := tStore.Match(ctx, tc.path, "GET")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, retrieved)
+		})
+	}
+
+	t.Run("no matching template", func(t *testing.T) {
+		_, err := tStore.Match(ctx, "/users/123/invoices", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("no matching method", func(t *testing.T) {
+		_, err := tStore.Match(ctx, "/users/123", "POST")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("deleted template no longer matches", func(t *testing.T) {
+		tStore := NewModelStore()
+
+		_, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/items/{id}", Method: "GET"}})
+		assert.NoError(t, err)
+
+		_, err = tStore.Delete(ctx, "/items/{id}", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		_, err = tStore.Match(ctx, "/items/1", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+}
+
+func TestStoreAllPathTemplates(t *testing.T) {
+	t.Run("fail on conflicting template with existing model", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		_, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users/{id}", Method: "GET"}})
+		assert.NoError(t, err)
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users/{user_id}", Method: "GET"}})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on conflicting templates within batch", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{
+			{Path: "/users/{id}", Method: "GET"},
+			{Path: "/users/{user_id}", Method: "GET"},
+		})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on invalid template", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/users/{id}/orders/{id}", Method: "GET"}})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("fail on path parameter missing from template", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		ok, err := tStore.StoreAll(ctx, []*models.APIModel{{
+			Path:   "/users/{user_id}",
+			Method: "GET",
+			PathParams: []*models.Parameter{
+				{Name: "order_id", Types: []models.ParamType{models.TypeInt}, Required: true},
+			},
+		}})
+		assert.Error(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestStoreAllNestedSchemas(t *testing.T) {
+	testCases := []struct {
+		name    string
+		body    []*models.Parameter
+		isValid bool
+	}{
+		{
+			name: "valid object and list schemas",
+			body: []*models.Parameter{
+				{
+					Name:  "address",
+					Types: []models.ParamType{models.TypeObject},
+					Properties: []*models.Parameter{
+						{Name: "zip", Types: []models.ParamType{models.TypeString}, Required: true},
+					},
+				},
+				{
+					Name:  "items",
+					Types: []models.ParamType{models.TypeList},
+					Items: &models.Parameter{
+						Types: []models.ParamType{models.TypeObject},
+						Properties: []*models.Parameter{
+							{Name: "sku", Types: []models.ParamType{models.TypeString}, Required: true},
+						},
+					},
+				},
+			},
+			isValid: true,
+		},
+		{
+			name:    "null parameter",
+			body:    []*models.Parameter{nil},
+			isValid: false,
+		},
+		{
+			name: "properties without Object type",
+			body: []*models.Parameter{
+				{
+					Name:       "address",
+					Types:      []models.ParamType{models.TypeString},
+					Properties: []*models.Parameter{{Name: "zip", Types: []models.ParamType{models.TypeString}}},
+				},
+			},
+			isValid: false,
+		},
+		{
+			name: "items without List type",
+			body: []*models.Parameter{
+				{
+					Name:  "items",
+					Types: []models.ParamType{models.TypeObject},
+					Items: &models.Parameter{Types: []models.ParamType{models.TypeString}},
+				},
+			},
+			isValid: false,
+		},
+		{
+			name: "nested property without a name",
+			body: []*models.Parameter{
+				{
+					Name:       "address",
+					Types:      []models.ParamType{models.TypeObject},
+					Properties: []*models.Parameter{{Types: []models.ParamType{models.TypeString}}},
+				},
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			tStore := NewModelStore()
+
+			ok, err := tStore.StoreAll(ctx, []*models.APIModel{{Path: "/orders", Method: "POST", Body: tc.body}})
+			assert.Equal(t, tc.isValid, err == nil)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestStoreAllConstraints(t *testing.T) {
+	minimum, maximum := 10.0, 1.0
+	minLength, maxLength, negativeLength := 5, 2, -1
+	riskWeight, negativeRiskWeight := 0.0, -0.5
+
+	testCases := []struct {
+		name    string
+		param   *models.Parameter
+		isValid bool
+	}{
+		{
+			name:    "valid constraints",
+			param:   &models.Parameter{Name: "p", Minimum: &maximum, Maximum: &minimum, Pattern: `^\d+$`},
+			isValid: true,
+		},
+		{
+			name:    "minimum greater than maximum",
+			param:   &models.Parameter{Name: "p", Minimum: &minimum, Maximum: &maximum},
+			isValid: false,
+		},
+		{
+			name:    "min_length greater than max_length",
+			param:   &models.Parameter{Name: "p", MinLength: &minLength, MaxLength: &maxLength},
+			isValid: false,
+		},
+		{
+			name:    "negative length",
+			param:   &models.Parameter{Name: "p", MinLength: &negativeLength},
+			isValid: false,
+		},
+		{
+			name:    "invalid pattern",
+			param:   &models.Parameter{Name: "p", Pattern: `^[a-z`},
+			isValid: false,
+		},
+		{
+			name:    "known severity",
+			param:   &models.Parameter{Name: "p", Severity: models.SeverityInfo},
+			isValid: true,
+		},
+		{
+			name:    "unknown severity",
+			param:   &models.Parameter{Name: "p", Severity: "urgent"},
+			isValid: false,
+		},
+		{
+			name:    "zero risk weight",
+			param:   &models.Parameter{Name: "p", RiskWeight: &riskWeight},
+			isValid: true,
+		},
+		{
+			name:    "negative risk weight",
+			param:   &models.Parameter{Name: "p", RiskWeight: &negativeRiskWeight},
+			isValid: false,
+		},
+		{
+			name: "date formats and ranges",
+			param: &models.Parameter{
+				Name: "p", Types: []models.ParamType{models.TypeDate, models.TypeTimestamp},
+				Formats: []string{"iso8601", "unix", "2006/01/02"}, NotFuture: true, WithinLastDays: &minLength,
+			},
+			isValid: true,
+		},
+		{
+			name:    "date formats on a string",
+			param:   &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeString}, Formats: []string{"iso8601"}},
+			isValid: false,
+		},
+		{
+			name:    "unknown date format",
+			param:   &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeDate}, Formats: []string{"yyyy-mm-dd"}},
+			isValid: false,
+		},
+		{
+			name:    "neither future nor past",
+			param:   &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeDate}, NotFuture: true, NotPast: true},
+			isValid: false,
+		},
+		{
+			name:    "negative number of days",
+			param:   &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeDate}, WithinNextDays: &negativeLength},
+			isValid: false,
+		},
+		{
+			name: "JWT rules on an auth token",
+			param: &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeAuthToken}, JWT: &models.JWTRules{
+				Algorithms: []string{"RS256", "EdDSA"}, RequiredClaims: []string{"sub"}, LeewaySeconds: 30,
+			}},
+			isValid: true,
+		},
+		{
+			name:    "JWT rules on a string",
+			param:   &models.Parameter{Name: "p", Types: []models.ParamType{models.TypeString}, JWT: &models.JWTRules{}},
+			isValid: false,
+		},
+		{
+			name: "JWT rules allowing none",
+			param: &models.Parameter{
+				Name: "p", Types: []models.ParamType{models.TypeJWT}, JWT: &models.JWTRules{Algorithms: []string{"none"}},
+			},
+			isValid: false,
+		},
+		{
+			name: "negative JWT leeway",
+			param: &models.Parameter{
+				Name: "p", Types: []models.ParamType{models.TypeJWT}, JWT: &models.JWTRules{LeewaySeconds: -1},
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			tStore := NewModelStore()
+
+			tModel := &models.APIModel{Path: "/orders", Method: "GET", QueryParams: []*models.Parameter{tc.param}}
+
+			ok, err := tStore.StoreAll(ctx, []*models.APIModel{tModel})
+			assert.Equal(t, tc.isValid, err == nil)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	tAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tOriginal := &models.APIModel{Path: "/users", Method: "GET"}
+	tReplacement := &models.APIModel{
+		Path:   "/users",
+		Method: "GET",
+		QueryParams: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+		},
+	}
+
+	t.Run("every change records a version with its metadata", func(t *testing.T) {
+		ctx := WithChange(context.Background(), Change{Author: "alice", Comment: "initial contract", At: tAt})
+		store := NewModelStore()
+
+		_, err := store.StoreAll(ctx, []*models.APIModel{tOriginal})
+		assert.NoError(t, err)
+
+		_, _, err = store.Replace(WithChange(ctx, Change{Author: "bob"}), tReplacement, AnyRevision)
+		assert.NoError(t, err)
+
+		_, err = store.Delete(context.Background(), "/users", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		versions, err := store.Versions(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		require.Len(t, versions, 3)
+
+		assert.Equal(t, &models.ModelVersion{
+			Tenant: tenant.Default, Path: "/users", Method: "GET", Version: 1, Change: models.ChangeCreated,
+			Author: "alice", Comment: "initial contract", CreatedAt: tAt, Model: tOriginal,
+		}, versions[0])
+		assert.Equal(t, models.ChangeReplaced, versions[1].Change)
+		assert.Equal(t, "bob", versions[1].Author)
+		assert.False(t, versions[1].CreatedAt.IsZero())
+		assert.Equal(t, models.ChangeDeleted, versions[2].Change)
+		assert.Equal(t, cUnknownAuthor, versions[2].Author)
+		assert.Nil(t, versions[2].Model)
+
+		version, err := store.Version(ctx, "/users", "GET", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, tReplacement, version.Model)
+	})
+
+	t.Run("error for unknown model or version", func(t *testing.T) {
+		ctx := context.Background()
+		store := NewModelStore()
+
+		_, err := store.Versions(ctx, "/users", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+
+		_, err = store.StoreAll(ctx, []*models.APIModel{tOriginal})
+		assert.NoError(t, err)
+
+		_, err = store.Version(ctx, "/users", "GET", 2)
+		assert.ErrorIs(t, err, ErrVersionNotFound)
+	})
+}
+
+func TestRollback(t *testing.T) {
+	tOriginal := &models.APIModel{Path: "/users/{id}", Method: "GET"}
+	tReplacement := &models.APIModel{
+		Path:       "/users/{id}",
+		Method:     "GET",
+		PathParams: []*models.Parameter{{Name: "id", Types: []models.ParamType{models.TypeInt}}},
+	}
+
+	setup := func(t *testing.T) IModelStore {
+		t.Helper()
+
+		store := NewModelStore()
+		_, err := store.StoreAll(context.Background(), []*models.APIModel{tOriginal})
+		require.NoError(t, err)
+		_, _, err = store.Replace(context.Background(), tReplacement, AnyRevision)
+		require.NoError(t, err)
+
+		return store
+	}
+
+	t.Run("rollback records the restored model as a new version", func(t *testing.T) {
+		ctx := context.Background()
+		store := setup(t)
+
+		revision, ok, err := store.Rollback(ctx, "/users/{id}", "GET", 1, AnyRevision)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 3, revision)
+
+		active, err := store.Match(ctx, "/users/7", "GET")
+		assert.NoError(t, err)
+		assert.Equal(t, tOriginal, active)
+
+		versions, err := store.Versions(ctx, "/users/{id}", "GET")
+		assert.NoError(t, err)
+		require.Len(t, versions, 3)
+		assert.Equal(t, models.ChangeRolledBack, versions[2].Change)
+		assert.Equal(t, 1, versions[2].RolledBackFrom)
+	})
+
+	t.Run("rollback restores a deleted model", func(t *testing.T) {
+		ctx := context.Background()
+		store := setup(t)
+
+		_, err := store.Delete(ctx, "/users/{id}", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		_, _, err = store.Rollback(ctx, "/users/{id}", "GET", 2, AnyRevision)
+		assert.NoError(t, err)
+
+		active, err := store.Get(ctx, "/users/{id}", "GET")
+		assert.NoError(t, err)
+		assert.Equal(t, tReplacement, active)
+	})
+
+	t.Run("fail on invalid rollbacks", func(t *testing.T) {
+		ctx := context.Background()
+		store := setup(t)
+
+		_, ok, err := store.Rollback(ctx, "/users/{id}", "GET", 2, AnyRevision)
+		assert.ErrorContains(t, err, "already active")
+		assert.True(t, ok)
+
+		_, _, err = store.Rollback(ctx, "/users/{id}", "GET", 5, AnyRevision)
+		assert.ErrorIs(t, err, ErrVersionNotFound)
+
+		_, err = store.Delete(ctx, "/users/{id}", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		_, _, err = store.Rollback(ctx, "/users/{id}", "GET", 3, AnyRevision)
+		assert.ErrorContains(t, err, "records a deletion")
+	})
+
+	t.Run("fail when the latest version is not the expected revision", func(t *testing.T) {
+		ctx := context.Background()
+		store := setup(t)
+
+		_, ok, err := store.Rollback(ctx, "/users/{id}", "GET", 1, 1)
+		assert.ErrorIs(t, err, ErrRevisionMismatch)
+		assert.True(t, ok)
+
+		_, err = store.Delete(ctx, "/users/{id}", "GET", 2)
+		assert.NoError(t, err)
+
+		revision, _, err := store.Rollback(ctx, "/users/{id}", "GET", 1, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, revision)
+	})
+
+	t.Run("fail restoring a deleted model whose route was taken", func(t *testing.T) {
+		ctx := context.Background()
+		store := setup(t)
+
+		_, err := store.Delete(ctx, "/users/{id}", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		_, err = store.StoreAll(ctx, []*models.APIModel{{Path: "/users/{user_id}", Method: "GET"}})
+		assert.NoError(t, err)
+
+		_, _, err = store.Rollback(ctx, "/users/{id}", "GET", 1, AnyRevision)
+		assert.ErrorContains(t, err, "conflicts with existing model")
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	tModel := &models.APIModel{Path: "/users", Method: "GET"}
+
+	t.Run("writes apply only to the active revision", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		_, err := tStore.StoreAll(ctx, []*models.APIModel{tModel})
+		assert.NoError(t, err)
+
+		_, revision, err := tStore.GetRevision(ctx, "/users", "GET")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, revision)
+
+		_, ok, err := tStore.Replace(ctx, tModel, 1)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// A writer still holding revision 1 must not clobber revision 2
+		_, ok, err = tStore.Replace(ctx, tModel, 1)
+		assert.ErrorIs(t, err, ErrRevisionMismatch)
+		assert.True(t, ok)
+
+		_, err = tStore.Delete(ctx, "/users", "GET", 1)
+		assert.ErrorIs(t, err, ErrRevisionMismatch)
+
+		_, err = tStore.Delete(ctx, "/users", "GET", 2)
+		assert.NoError(t, err)
+
+		_, _, err = tStore.GetRevision(ctx, "/users", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+}
+
+func TestTenants(t *testing.T) {
+	tModel := &models.APIModel{Path: "/health", Method: "GET"}
+	tAcme := tenant.WithTenant(context.Background(), "acme")
+	tGlobex := tenant.WithTenant(context.Background(), "globex")
+
+	t.Run("tenants have separate keyspaces", func(t *testing.T) {
+		tStore := NewModelStore()
+
+		_, err := tStore.StoreAll(tAcme, []*models.APIModel{tModel})
+		assert.NoError(t, err)
+
+		// The same path and method do not collide across tenants
+		_, err = tStore.StoreAll(tGlobex, []*models.APIModel{tModel})
+		assert.NoError(t, err)
+
+		_, err = tStore.Match(context.Background(), "/health", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+
+		_, err = tStore.Delete(tAcme, "/health", "GET", AnyRevision)
+		assert.NoError(t, err)
+
+		_, err = tStore.Match(tGlobex, "/health", "GET")
+		assert.NoError(t, err)
+
+		result, err := tStore.List(tAcme, "", "")
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+
+		_, err = tStore.Versions(context.Background(), "/health", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("delete a tenant with its history", func(t *testing.T) {
+		tStore := NewModelStore()
+
+		_, err := tStore.StoreAll(tAcme, []*models.APIModel{tModel})
+		assert.NoError(t, err)
+		_, err = tStore.StoreAll(tGlobex, []*models.APIModel{tModel})
+		assert.NoError(t, err)
+
+		ok, err := tStore.DeleteTenant(tAcme)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = tStore.Versions(tAcme, "/health", "GET")
+		assert.ErrorIs(t, err, ErrModelNotFound)
+
+		_, err = tStore.Get(tGlobex, "/health", "GET")
+		assert.NoError(t, err)
+
+		_, err = tStore.DeleteTenant(tAcme)
+		assert.ErrorIs(t, err, ErrTenantNotFound)
+	})
 }
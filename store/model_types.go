@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+
+	"anomaly_detector/models"
+	"anomaly_detector/typeregistry"
+)
+
+// DefineType defines a custom parameter type for the tenant of ctx, or redefines it if it already exists.
+// The returned bool follows the StoreAll convention: true for user errors, false for internal errors.
+func (s *modelStore) DefineType(ctx context.Context, definition *models.TypeDefinition) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.namespaceOf(ctx).checkDefineType(definition); err != nil {
+		return true, err
+	}
+
+	s.writableNamespaceOf(ctx).defineType(ctx, definition)
+
+	return true, nil
+}
+
+// defineType stores a checked type definition. The caller must hold the lock.
+func (ns *namespace) defineType(ctx context.Context, definition *models.TypeDefinition) {
+	ns.types[definition.Name] = definition
+
+	slog.InfoContext(ctx, "Type defined", "type", definition.Name)
+}
+
+// Types returns the custom types defined for the tenant of ctx, sorted by name
+func (s *modelStore) Types(ctx context.Context) ([]*models.TypeDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.namespaceOf(ctx).sortedTypes(), nil
+}
+
+// checkDefineType validates a type definition against the current state. The caller must hold the lock.
+func (ns *namespace) checkDefineType(definition *models.TypeDefinition) error {
+	if definition == nil {
+		return fmt.Errorf("invalid type definition")
+	}
+
+	if err := typeregistry.ValidateName(definition.Name); err != nil {
+		return err
+	}
+
+	if _, exists := typeregistry.Lookup(definition.Name); exists {
+		return fmt.Errorf("type %s is registered in Go and cannot be redefined", definition.Name)
+	}
+
+	if definition.Pattern == "" && len(definition.Enum) == 0 && len(definition.AnyOf) == 0 {
+		return fmt.Errorf("type %s must set a pattern, an enum or any_of", definition.Name)
+	}
+
+	if definition.Pattern != "" {
+		if _, err := regexp.Compile(definition.Pattern); err != nil {
+			return fmt.Errorf("type %s has an invalid pattern: %w", definition.Name, err)
+		}
+	}
+
+	for _, composed := range definition.AnyOf {
+		if !ns.isKnownType(composed) && composed != definition.Name {
+			return fmt.Errorf("type %s is composed of the unknown type %q", definition.Name, composed)
+		}
+
+		// A redefinition could otherwise make the type match itself
+		if ns.composes(composed, definition.Name) {
+			return fmt.Errorf("type %s cannot be composed of itself, directly or through %s", definition.Name, composed)
+		}
+	}
+
+	return nil
+}
+
+// composes reports whether paramType is target or is composed of it, directly or transitively.
+// Stored definitions never form a cycle, so the walk ends. The caller must hold the lock.
+func (ns *namespace) composes(paramType, target models.ParamType) bool {
+	if paramType == target {
+		return true
+	}
+
+	definition, exists := ns.types[paramType]
+	if !exists {
+		return false
+	}
+
+	for _, composed := range definition.AnyOf {
+		if ns.composes(composed, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isKnownType reports whether a type is built-in, registered in Go or defined for the tenant.
+// The caller must hold the lock.
+func (ns *namespace) isKnownType(paramType models.ParamType) bool {
+	if typeregistry.IsGlobal(paramType) {
+		return true
+	}
+
+	_, exists := ns.types[paramType]
+
+	return exists
+}
+
+// sortedTypes returns the custom types of the namespace sorted by name. The caller must hold the lock.
+func (ns *namespace) sortedTypes() []*models.TypeDefinition {
+	definitions := make([]*models.TypeDefinition, 0, len(ns.types))
+	for _, definition := range ns.types {
+		definitions = append(definitions, definition)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+
+	return definitions
+}
+
+// typeSnapshot returns the custom types of every tenant that defined some. The caller must hold the lock.
+func (s *modelStore) typeSnapshot() map[string][]*models.TypeDefinition {
+	snapshot := make(map[string][]*models.TypeDefinition)
+
+	for name, ns := range s.namespaces {
+		if len(ns.types) > 0 {
+			snapshot[name] = ns.sortedTypes()
+		}
+	}
+
+	return snapshot
+}
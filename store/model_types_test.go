@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/tenant"
+	"anomaly_detector/typeregistry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tMobileType models.ParamType = "Mobile"
+
+func init() {
+	typeregistry.Register("StoreTestIPv4", typeregistry.TypeFunc(func(any) bool { return true }))
+}
+
+func TestDefineType(t *testing.T) {
+	testCases := []struct {
+		name       string
+		definition *models.TypeDefinition
+		isValid    bool
+	}{
+		{
+			name:       "pattern",
+			definition: &models.TypeDefinition{Name: tMobileType, Pattern: `^\+[0-9]{8,15}$`},
+			isValid:    true,
+		},
+		{
+			name:       "enum",
+			definition: &models.TypeDefinition{Name: "ISO-Country", Enum: []any{"IL", "US"}},
+			isValid:    true,
+		},
+		{
+			name:       "composition of built-in and Go types",
+			definition: &models.TypeDefinition{Name: "Host", AnyOf: []models.ParamType{"StoreTestIPv4", models.TypeEmail}},
+			isValid:    true,
+		},
+		{
+			name:       "no rule",
+			definition: &models.TypeDefinition{Name: tMobileType},
+			isValid:    false,
+		},
+		{
+			name:       "invalid name",
+			definition: &models.TypeDefinition{Name: "my type", Pattern: "^a$"},
+			isValid:    false,
+		},
+		{
+			name:       "built-in name",
+			definition: &models.TypeDefinition{Name: models.TypeEmail, Pattern: "^a$"},
+			isValid:    false,
+		},
+		{
+			name:       "Go type name",
+			definition: &models.TypeDefinition{Name: "StoreTestIPv4", Pattern: "^a$"},
+			isValid:    false,
+		},
+		{
+			name:       "invalid pattern",
+			definition: &models.TypeDefinition{Name: tMobileType, Pattern: `^[0-9`},
+			isValid:    false,
+		},
+		{
+			name:       "unknown composed type",
+			definition: &models.TypeDefinition{Name: "Host", AnyOf: []models.ParamType{"IPv6"}},
+			isValid:    false,
+		},
+		{
+			name:       "composed of itself",
+			definition: &models.TypeDefinition{Name: "Host", AnyOf: []models.ParamType{"Host"}},
+			isValid:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tStore := NewModelStore()
+
+			ok, err := tStore.DefineType(context.Background(), tc.definition)
+			assert.Equal(t, tc.isValid, err == nil)
+			assert.True(t, ok)
+		})
+	}
+
+	t.Run("a redefinition cannot create a cycle", func(t *testing.T) {
+		ctx := context.Background()
+		tStore := NewModelStore()
+
+		_, err := tStore.DefineType(ctx, &models.TypeDefinition{Name: tMobileType, Pattern: `^\+[0-9]+$`})
+		require.NoError(t, err)
+		_, err = tStore.DefineType(ctx, &models.TypeDefinition{Name: "Contact", AnyOf: []models.ParamType{tMobileType}})
+		require.NoError(t, err)
+
+		_, err = tStore.DefineType(ctx, &models.TypeDefinition{Name: tMobileType, AnyOf: []models.ParamType{"Contact"}})
+		assert.Error(t, err)
+
+		// Redefining without a cycle is allowed
+		_, err = tStore.DefineType(ctx, &models.TypeDefinition{Name: tMobileType, Pattern: `^[0-9]+$`})
+		assert.NoError(t, err)
+
+		definitions, err := tStore.Types(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.TypeDefinition{
+			{Name: "Contact", AnyOf: []models.ParamType{tMobileType}},
+			{Name: tMobileType, Pattern: `^[0-9]+$`},
+		}, definitions)
+	})
+}
+
+func TestStoreAllTypes(t *testing.T) {
+	tModel := &models.APIModel{
+		Path:   "/contacts",
+		Method: "POST",
+		Body: []*models.Parameter{
+			{
+				Name:  "phones",
+				Types: []models.ParamType{models.TypeList},
+				Items: &models.Parameter{Types: []models.ParamType{tMobileType}},
+			},
+		},
+	}
+	tAcme := tenant.WithTenant(context.Background(), "acme")
+	tGlobex := tenant.WithTenant(context.Background(), "globex")
+
+	tStore := NewModelStore()
+
+	_, err := tStore.StoreAll(tAcme, []*models.APIModel{tModel})
+	assert.ErrorContains(t, err, `parameter "phones[]" references the unknown type "Mobile"`)
+
+	_, err = tStore.DefineType(tAcme, &models.TypeDefinition{Name: tMobileType, Pattern: `^\+[0-9]+$`})
+	require.NoError(t, err)
+
+	_, err = tStore.StoreAll(tAcme, []*models.APIModel{tModel})
+	assert.NoError(t, err)
+
+	// Defined types belong to their tenant, while Go types are known to all
+	_, err = tStore.StoreAll(tGlobex, []*models.APIModel{tModel})
+	assert.Error(t, err)
+
+	_, err = tStore.StoreAll(tGlobex, []*models.APIModel{{
+		Path: "/hosts", Method: "GET",
+		QueryParams: []*models.Parameter{{Name: "ip", Types: []models.ParamType{"StoreTestIPv4"}}},
+	}})
+	assert.NoError(t, err)
+
+	// Deleting a tenant deletes its types
+	_, err = tStore.DeleteTenant(tAcme)
+	require.NoError(t, err)
+
+	definitions, err := tStore.Types(tAcme)
+	assert.NoError(t, err)
+	assert.Empty(t, definitions)
+}
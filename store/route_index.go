@@ -0,0 +1,143 @@
+package store
+
+import (
+	"strings"
+
+	"anomaly_detector/pathtemplate"
+)
+
+const cRouteShapeVariable = "{}"
+
+// routeNode is a node of a segment trie. Literal children are preferred over the variable child when matching.
+type routeNode struct {
+	literals map[string]*routeNode
+	variable *routeNode
+	// key of the model whose template ends at this node, empty if none
+	key string
+}
+
+// routeIndex resolves concrete request paths to the most specific stored path template, per method
+type routeIndex struct {
+	roots map[string]*routeNode
+}
+
+func newRouteIndex() *routeIndex {
+	return &routeIndex{roots: make(map[string]*routeNode)}
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{literals: make(map[string]*routeNode)}
+}
+
+// insert registers a template for a method, pointing at the given model key
+func (ri *routeIndex) insert(template, method, key string) {
+	root, exists := ri.roots[method]
+	if !exists {
+		root = newRouteNode()
+		ri.roots[method] = root
+	}
+
+	node := root
+
+	for _, segment := range pathtemplate.Segments(template) {
+		if _, ok := pathtemplate.ParamName(segment); ok {
+			if node.variable == nil {
+				node.variable = newRouteNode()
+			}
+
+			node = node.variable
+
+			continue
+		}
+
+		child, exists := node.literals[segment]
+		if !exists {
+			child = newRouteNode()
+			node.literals[segment] = child
+		}
+
+		node = child
+	}
+
+	node.key = key
+}
+
+// remove unregisters a template for a method. Empty nodes are kept, they never match on their own.
+func (ri *routeIndex) remove(template, method string) {
+	if node := ri.find(template, method); node != nil {
+		node.key = ""
+	}
+}
+
+// lookup returns the key of the model registered for a template with the same shape, ignoring variable names
+func (ri *routeIndex) lookup(template, method string) string {
+	if node := ri.find(template, method); node != nil {
+		return node.key
+	}
+
+	return ""
+}
+
+func (ri *routeIndex) find(template, method string) *routeNode {
+	node, exists := ri.roots[method]
+	if !exists {
+		return nil
+	}
+
+	for _, segment := range pathtemplate.Segments(template) {
+		if _, ok := pathtemplate.ParamName(segment); ok {
+			node = node.variable
+		} else {
+			node = node.literals[segment]
+		}
+
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// match returns the key of the most specific template matching a concrete path.
+// At every segment a literal match wins over a variable, so /users/me beats /users/{user_id}.
+func (ri *routeIndex) match(path, method string) (string, bool) {
+	root, exists := ri.roots[method]
+	if !exists {
+		return "", false
+	}
+
+	key := matchSegments(root, pathtemplate.Segments(path))
+
+	return key, key != ""
+}
+
+func matchSegments(node *routeNode, segments []string) string {
+	if len(segments) == 0 {
+		return node.key
+	}
+
+	if child, exists := node.literals[segments[0]]; exists {
+		if key := matchSegments(child, segments[1:]); key != "" {
+			return key
+		}
+	}
+
+	if node.variable != nil {
+		return matchSegments(node.variable, segments[1:])
+	}
+
+	return ""
+}
+
+// routeShape returns the template with every variable name erased, so templates matching the same paths compare equal
+func routeShape(template string) string {
+	segments := pathtemplate.Segments(template)
+	for i, segment := range segments {
+		if _, ok := pathtemplate.ParamName(segment); ok {
+			segments[i] = cRouteShapeVariable
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
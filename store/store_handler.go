@@ -2,15 +2,50 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"anomaly_detector/api"
 	"anomaly_detector/models"
+
+	"github.com/gorilla/mux"
 )
 
+const (
+	cRouteVarMethod = "method"
+	cRouteVarPath   = "path"
+
+	cQueryPathPrefix = "path_prefix"
+	cQueryMethod     = "method"
+	cQueryVersion    = "version"
+	cQueryFrom       = "from"
+	cQueryTo         = "to"
+
+	cHeaderETag    = "ETag"
+	cHeaderIfMatch = "If-Match"
+	cAnyETag       = "*"
+)
+
+// errIfMatchRequired is returned when a replace or delete does not say which revision it expects
+var errIfMatchRequired = errors.New("missing If-Match header with the model ETag")
+
 type IStoreHandler interface {
 	api.IHandler
+	HandleList(w http.ResponseWriter, r *http.Request)
+	HandleGet(w http.ResponseWriter, r *http.Request)
+	HandleReplace(w http.ResponseWriter, r *http.Request)
+	HandleDelete(w http.ResponseWriter, r *http.Request)
+	HandleVersions(w http.ResponseWriter, r *http.Request)
+	HandleDiff(w http.ResponseWriter, r *http.Request)
+	HandleRollback(w http.ResponseWriter, r *http.Request)
+	HandleDeleteTenant(w http.ResponseWriter, r *http.Request)
+	HandleDefineType(w http.ResponseWriter, r *http.Request)
+	HandleTypes(w http.ResponseWriter, r *http.Request)
 }
 
 type storeHandler struct {
@@ -51,3 +86,377 @@ func (h *storeHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	}
 	api.RespondJSON(w, http.StatusOK, response)
 }
+
+// HandleList returns all stored models, optionally filtered by the path_prefix and method query parameters
+func (h *storeHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	apiModels, err := h.store.List(ctx, query.Get(cQueryPathPrefix), query.Get(cQueryMethod))
+	if err != nil {
+		slog.ErrorContext(ctx, "error listing models", "error", err)
+		api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, apiModels)
+}
+
+// HandleGet returns the single model addressed by the {method} and {path} route variables,
+// with its revision as the ETag to send back in If-Match when replacing or deleting it
+func (h *storeHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	model, revision, err := h.store.GetRevision(ctx, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	w.Header().Set(cHeaderETag, revisionETag(revision))
+	api.RespondJSON(w, http.StatusOK, model)
+}
+
+// HandleReplace overwrites the model addressed by the {method} and {path} route variables with the request body.
+// The If-Match header must hold the ETag of the model being replaced, or * to replace any revision.
+// The response carries the ETag of the replacement.
+func (h *storeHandler) HandleReplace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	revision, err := h.expectedRevision(r, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	var model models.APIModel
+	if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if model.Path == "" {
+		model.Path = path
+	}
+
+	if model.Method == "" {
+		model.Method = method
+	}
+
+	if model.Path != path || !strings.EqualFold(model.Method, method) {
+		api.RespondError(w, http.StatusBadRequest, "model path and method must match the URL")
+		return
+	}
+
+	replaced, ok, err := h.store.Replace(ctx, &model, revision)
+	if err != nil {
+		respondStoreError(w, r, ok, err)
+		return
+	}
+
+	w.Header().Set(cHeaderETag, revisionETag(replaced))
+
+	response := map[string]any{
+		"message": "model replaced successfully",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleDelete removes the model addressed by the {method} and {path} route variables.
+// Like HandleReplace, it requires the If-Match header.
+func (h *storeHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	revision, err := h.expectedRevision(r, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	ok, err := h.store.Delete(ctx, path, method, revision)
+	if err != nil {
+		respondStoreError(w, r, ok, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "model deleted successfully",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleVersions returns the history of the {method} and {path} route variables, or the single version
+// given by the version query parameter
+func (h *storeHandler) HandleVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	if r.URL.Query().Has(cQueryVersion) {
+		version, err := versionFromQuery(r, cQueryVersion)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		modelVersion, err := h.store.Version(ctx, path, method, version)
+		if err != nil {
+			respondStoreError(w, r, true, err)
+			return
+		}
+
+		api.RespondJSON(w, http.StatusOK, modelVersion)
+
+		return
+	}
+
+	versions, err := h.store.Versions(ctx, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, versions)
+}
+
+// HandleDiff compares two versions of the {method} and {path} route variables. The to query parameter
+// defaults to the latest version and from to the version before it.
+func (h *storeHandler) HandleDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	versions, err := h.store.Versions(ctx, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	to, from := len(versions), len(versions)-1
+
+	if r.URL.Query().Has(cQueryTo) {
+		if to, err = versionFromQuery(r, cQueryTo); err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		from = to - 1
+	}
+
+	if r.URL.Query().Has(cQueryFrom) {
+		if from, err = versionFromQuery(r, cQueryFrom); err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Version 0 is the empty model preceding the first version, so the first version diffs as all added
+	if from < 0 || from > len(versions) || to > len(versions) {
+		api.RespondError(w, http.StatusNotFound, "versions to compare do not exist")
+		return
+	}
+
+	var fromModel *models.APIModel
+	if from > 0 {
+		fromModel = versions[from-1].Model
+	}
+
+	diff := &models.ModelDiff{
+		Path:    path,
+		Method:  method,
+		From:    from,
+		To:      to,
+		Changes: DiffModels(fromModel, versions[to-1].Model),
+	}
+	api.RespondJSON(w, http.StatusOK, diff)
+}
+
+// HandleRollback makes the version given by the version query parameter active again, as a new version
+// whose revision is returned as the ETag. Like HandleReplace, it requires the If-Match header, holding the
+// ETag of the latest version even when it records a deletion.
+func (h *storeHandler) HandleRollback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, method := modelKeyFromRoute(r)
+
+	version, err := versionFromQuery(r, cQueryVersion)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	revision, err := h.expectedRevision(r, path, method)
+	if err != nil {
+		respondStoreError(w, r, true, err)
+		return
+	}
+
+	rolledBack, ok, err := h.store.Rollback(ctx, path, method, version, revision)
+	if err != nil {
+		respondStoreError(w, r, ok, err)
+		return
+	}
+
+	w.Header().Set(cHeaderETag, revisionETag(rolledBack))
+
+	response := map[string]any{
+		"message": "model rolled back successfully",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleDeleteTenant removes every model of the tenant of the request along with their history
+func (h *storeHandler) HandleDeleteTenant(w http.ResponseWriter, r *http.Request) {
+	ok, err := h.store.DeleteTenant(r.Context())
+	if err != nil {
+		respondStoreError(w, r, ok, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "tenant deleted successfully",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleDefineType defines the custom parameter type in the request body for the tenant of the request,
+// replacing any previous definition with the same name
+func (h *storeHandler) HandleDefineType(w http.ResponseWriter, r *http.Request) {
+	var definition models.TypeDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	ok, err := h.store.DefineType(r.Context(), &definition)
+	if err != nil {
+		respondStoreError(w, r, ok, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "type defined successfully",
+	}
+	api.RespondJSON(w, http.StatusOK, response)
+}
+
+// HandleTypes returns the custom parameter types defined for the tenant of the request
+func (h *storeHandler) HandleTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	definitions, err := h.store.Types(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error listing types", "error", err)
+		api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, definitions)
+}
+
+// versionFromQuery reads a version number from a query parameter
+func versionFromQuery(r *http.Request, name string) (int, error) {
+	version, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%s must be a positive version number", name)
+	}
+
+	return version, nil
+}
+
+// revisionETag formats a model revision as a strong ETag
+func revisionETag(revision int) string {
+	return strconv.Quote(strconv.Itoa(revision))
+}
+
+// expectedRevision reads the If-Match header of a write and returns the revision the write must find active,
+// AnyRevision for *. The header may list several ETags; weak ones never match, as If-Match uses the strong
+// comparison. Of a list, the latest revision is returned when it is listed, so that the store, which checks the
+// revision again atomically with the write, fails with ErrRevisionMismatch when none matches.
+func (h *storeHandler) expectedRevision(r *http.Request, path, method string) (int, error) {
+	ifMatch := strings.TrimSpace(strings.Join(r.Header.Values(cHeaderIfMatch), ","))
+	if ifMatch == "" {
+		return 0, errIfMatchRequired
+	}
+
+	var revisions []int
+
+	for _, etag := range strings.Split(ifMatch, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag == cAnyETag {
+			return AnyRevision, nil
+		}
+
+		if revision, ok := revisionFromETag(etag); ok {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	if len(revisions) == 0 {
+		return 0, fmt.Errorf("%w: If-Match %s holds no model ETag", ErrRevisionMismatch, ifMatch)
+	}
+
+	if len(revisions) == 1 {
+		return revisions[0], nil
+	}
+
+	versions, err := h.store.Versions(r.Context(), path, method)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := len(versions)
+	if !slices.Contains(revisions, latest) {
+		return 0, fmt.Errorf("%w: model for path %s and method %s is at revision %d, not %s",
+			ErrRevisionMismatch, path, method, latest, ifMatch)
+	}
+
+	return latest, nil
+}
+
+// revisionFromETag parses a strong ETag formatted by revisionETag
+func revisionFromETag(etag string) (int, bool) {
+	unquoted, err := strconv.Unquote(etag)
+	if err != nil || !strings.HasPrefix(etag, `"`) {
+		return 0, false
+	}
+
+	revision, err := strconv.Atoi(unquoted)
+
+	return revision, err == nil && revision >= 1
+}
+
+// respondStoreError maps a store error to an HTTP response, using the (bool, error) convention of IModelStore
+func respondStoreError(w http.ResponseWriter, r *http.Request, isUserError bool, err error) {
+	if !isUserError {
+		// Internal errors - log but don't expose details to prevent information leakage
+		slog.ErrorContext(r.Context(), "model store error", "error", err)
+		api.RespondError(w, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrModelNotFound) || errors.Is(err, ErrVersionNotFound) || errors.Is(err, ErrTenantNotFound):
+		api.RespondError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, ErrRevisionMismatch):
+		api.RespondError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	case errors.Is(err, errIfMatchRequired):
+		api.RespondError(w, http.StatusPreconditionRequired, err.Error())
+		return
+	}
+
+	api.RespondError(w, http.StatusBadRequest, err.Error())
+}
+
+// modelKeyFromRoute extracts the model path and method from the route variables.
+// The path variable is captured without its leading slash, so it is restored here.
+func modelKeyFromRoute(r *http.Request) (string, string) {
+	vars := mux.Vars(r)
+
+	return "/" + strings.TrimPrefix(vars[cRouteVarPath], "/"), strings.ToUpper(vars[cRouteVarMethod])
+}
@@ -7,15 +7,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"anomaly_detector/models"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
 	tModelsPath    = "/models"
 	tUsersInfoPath = "/users/info"
+	tUsersInfoURL  = "/models/GET/users/info"
 )
 
 var (
@@ -115,3 +119,637 @@ func TestStoreAllModels(t *testing.T) {
 		assert.Equal(t, "internal server error", response["error"])
 	})
 }
+
+// newRouteRequest builds a request with the {method} and {path} route variables set, as mux would
+func newRouteRequest(httpMethod, url string, body []byte) *http.Request {
+	httpRequest := httptest.NewRequest(httpMethod, url, bytes.NewReader(body))
+
+	return mux.SetURLVars(httpRequest, map[string]string{
+		cRouteVarMethod: "get",
+		cRouteVarPath:   "users/info",
+	})
+}
+
+func TestListModels(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success listing models with filters", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tModelsPath+"?path_prefix=/users&method=GET", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			List(mock.Anything, "/users", http.MethodGet).
+			Return(tApiModels, nil).Once()
+
+		tHandler.HandleList(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response []*models.APIModel
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, tApiModels, response)
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, tModelsPath, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			List(mock.Anything, "", "").
+			Return(nil, assert.AnError).Once()
+
+		tHandler.HandleList(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusInternalServerError, tRecorder.Code)
+	})
+}
+
+func TestGetModel(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success getting model", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, tUsersInfoURL, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			GetRevision(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tApiModels[0], 3, nil).Once()
+
+		tHandler.HandleGet(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, `"3"`, tRecorder.Header().Get(cHeaderETag))
+
+		var response models.APIModel
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, tApiModels[0], &response)
+	})
+
+	t.Run("error when model not found", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, tUsersInfoURL, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			GetRevision(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(nil, 0, ErrModelNotFound).Once()
+
+		tHandler.HandleGet(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+}
+
+func TestReplaceModel(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success replacing model", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 1).
+			Return(2, true, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, `"2"`, tRecorder.Header().Get(cHeaderETag))
+
+		var response map[string]any
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, "model replaced successfully", response["message"])
+	})
+
+	t.Run("path and method default to the URL", func(t *testing.T) {
+		body, _ := json.Marshal(&models.APIModel{QueryParams: tApiModels[0].QueryParams})
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 1).
+			Return(2, true, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("method of the body is case insensitive", func(t *testing.T) {
+		tModel := &models.APIModel{Path: tUsersInfoPath, Method: "get"}
+		body, _ := json.Marshal(tModel)
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tModel, 1).
+			Return(2, true, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("error when body does not match the URL", func(t *testing.T) {
+		body, _ := json.Marshal(&models.APIModel{Path: "/other", Method: http.MethodGet})
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("error with invalid JSON", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, []byte("invalid json"))
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("error when model not found", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 1).
+			Return(0, true, ErrModelNotFound).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+
+	t.Run("replace any revision with a wildcard", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, "*")
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], AnyRevision).
+			Return(2, true, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("replace the latest revision listed in If-Match", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1", W/"3", "2"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return([]*models.ModelVersion{{Version: 1}, {Version: 2}}, nil).Once()
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 2).
+			Return(3, true, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("error when no ETag listed in If-Match matches", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Add(cHeaderIfMatch, `"1"`)
+		httpRequest.Header.Add(cHeaderIfMatch, `"2", W/"3"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return([]*models.ModelVersion{{Version: 1}, {Version: 2}, {Version: 3}}, nil).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionFailed, tRecorder.Code)
+	})
+
+	t.Run("error when If-Match is missing", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionRequired, tRecorder.Code)
+	})
+
+	t.Run("error when revision does not match", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 1).
+			Return(0, true, ErrRevisionMismatch).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionFailed, tRecorder.Code)
+
+		httpRequest = newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `W/"1"`)
+		tRecorder = httptest.NewRecorder()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionFailed, tRecorder.Code)
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		body, _ := json.Marshal(tApiModels[0])
+		httpRequest := newRouteRequest(http.MethodPut, tUsersInfoURL, body)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Replace(mock.Anything, tApiModels[0], 1).
+			Return(0, false, assert.AnError).Once()
+
+		tHandler.HandleReplace(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusInternalServerError, tRecorder.Code)
+	})
+}
+
+func TestDeleteModel(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success deleting model", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodDelete, tUsersInfoURL, nil)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Delete(mock.Anything, tUsersInfoPath, http.MethodGet, 1).
+			Return(true, nil).Once()
+
+		tHandler.HandleDelete(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response map[string]any
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, "model deleted successfully", response["message"])
+	})
+
+	t.Run("error when model not found", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodDelete, tUsersInfoURL, nil)
+		httpRequest.Header.Set(cHeaderIfMatch, `"1"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Delete(mock.Anything, tUsersInfoPath, http.MethodGet, 1).
+			Return(true, ErrModelNotFound).Once()
+
+		tHandler.HandleDelete(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+
+	t.Run("error when If-Match is missing", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodDelete, tUsersInfoURL, nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleDelete(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionRequired, tRecorder.Code)
+	})
+}
+
+func TestModelVersions(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	tVersions := []*models.ModelVersion{
+		{Path: tUsersInfoPath, Method: http.MethodGet, Version: 1, Change: models.ChangeCreated, Model: tApiModels[0]},
+		{Path: tUsersInfoPath, Method: http.MethodGet, Version: 2, Change: models.ChangeDeleted},
+	}
+
+	t.Run("success listing versions", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/versions/GET/users/info", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tVersions, nil).Once()
+
+		tHandler.HandleVersions(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response []*models.ModelVersion
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Len(t, response, 2)
+	})
+
+	t.Run("success getting a single version", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/versions/GET/users/info?version=1", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Version(mock.Anything, tUsersInfoPath, http.MethodGet, 1).
+			Return(tVersions[0], nil).Once()
+
+		tHandler.HandleVersions(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response models.ModelVersion
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Version)
+	})
+
+	t.Run("error on invalid or unknown version", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/versions/GET/users/info?version=abc", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleVersions(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+
+		httpRequest = newRouteRequest(http.MethodGet, "/models/versions/GET/users/info?version=3", nil)
+		tRecorder = httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Version(mock.Anything, tUsersInfoPath, http.MethodGet, 3).
+			Return(nil, ErrVersionNotFound).Once()
+
+		tHandler.HandleVersions(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+}
+
+func TestDiffModel(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	tVersions := []*models.ModelVersion{
+		{Version: 1, Model: &models.APIModel{Path: tUsersInfoPath, Method: http.MethodGet}},
+		{Version: 2, Model: tApiModels[0]},
+	}
+
+	t.Run("success comparing the latest versions by default", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/diff/GET/users/info", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tVersions, nil).Once()
+
+		tHandler.HandleDiff(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response models.ModelDiff
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.From)
+		assert.Equal(t, 2, response.To)
+		assert.Len(t, response.Changes, 1)
+		assert.Equal(t, models.DiffAdded, response.Changes[0].Change)
+	})
+
+	t.Run("success comparing a single version with the empty model", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/diff/GET/users/info", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return([]*models.ModelVersion{{Version: 1, Model: tApiModels[0]}}, nil).Once()
+
+		tHandler.HandleDiff(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		var response models.ModelDiff
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, response.From)
+		assert.Equal(t, 1, response.To)
+		assert.Len(t, response.Changes, 1)
+		assert.Equal(t, models.DiffAdded, response.Changes[0].Change)
+		assert.Equal(t, "id", response.Changes[0].Name)
+	})
+
+	t.Run("error when versions do not exist", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodGet, "/models/diff/GET/users/info?from=1&to=5", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Versions(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tVersions, nil).Once()
+
+		tHandler.HandleDiff(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+}
+
+func TestRollbackModel(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success rolling back with the change author", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodPost, "/models/rollback/GET/users/info?version=1", nil)
+		httpRequest.Header.Set(cHeaderAuthor, "alice")
+		httpRequest.Header.Set(cHeaderIfMatch, `"2"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Rollback(mock.Anything, tUsersInfoPath, http.MethodGet, 1, 2).
+			RunAndReturn(func(ctx context.Context, _, _ string, _, _ int) (int, bool, error) {
+				assert.Equal(t, "alice", changeFromContext(ctx, time.Now).Author)
+				return 3, true, nil
+			}).Once()
+
+		ChangeMiddleware(http.HandlerFunc(tHandler.HandleRollback)).ServeHTTP(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, `"3"`, tRecorder.Header().Get(cHeaderETag))
+	})
+
+	t.Run("error when version is missing", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodPost, "/models/rollback/GET/users/info", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleRollback(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("error when If-Match is missing", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodPost, "/models/rollback/GET/users/info?version=1", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleRollback(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionRequired, tRecorder.Code)
+	})
+
+	t.Run("error when revision does not match", func(t *testing.T) {
+		httpRequest := newRouteRequest(http.MethodPost, "/models/rollback/GET/users/info?version=1", nil)
+		httpRequest.Header.Set(cHeaderIfMatch, `"2"`)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Rollback(mock.Anything, tUsersInfoPath, http.MethodGet, 1, 2).
+			Return(0, true, ErrRevisionMismatch).Once()
+
+		tHandler.HandleRollback(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusPreconditionFailed, tRecorder.Code)
+	})
+}
+
+func TestDeleteTenant(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	t.Run("success deleting tenant", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodDelete, "/tenants/acme", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			DeleteTenant(mock.Anything).
+			Return(true, nil).Once()
+
+		tHandler.HandleDeleteTenant(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("error when tenant not found", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodDelete, "/tenants/acme", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			DeleteTenant(mock.Anything).
+			Return(true, ErrTenantNotFound).Once()
+
+		tHandler.HandleDeleteTenant(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusNotFound, tRecorder.Code)
+	})
+}
+
+func TestTypes(t *testing.T) {
+	tStoreMock := NewMockIModelStore(t)
+
+	tHandler := &storeHandler{
+		store: tStoreMock,
+	}
+
+	tDefinition := &models.TypeDefinition{Name: "Mobile", Pattern: `^\+[0-9]+$`}
+
+	t.Run("success defining a type", func(t *testing.T) {
+		body, _ := json.Marshal(tDefinition)
+		httpRequest := httptest.NewRequest(http.MethodPost, "/types", bytes.NewReader(body))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			DefineType(mock.Anything, tDefinition).
+			Return(true, nil).Once()
+
+		tHandler.HandleDefineType(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+	})
+
+	t.Run("error with an invalid definition", func(t *testing.T) {
+		body, _ := json.Marshal(&models.TypeDefinition{Name: "Mobile"})
+		httpRequest := httptest.NewRequest(http.MethodPost, "/types", bytes.NewReader(body))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			DefineType(mock.Anything, mock.Anything).
+			Return(true, assert.AnError).Once()
+
+		tHandler.HandleDefineType(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("error with invalid JSON", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, "/types", bytes.NewBufferString("{"))
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleDefineType(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("success listing types", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, "/types", nil)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Types(mock.Anything).
+			Return([]*models.TypeDefinition{tDefinition}, nil).Once()
+
+		tHandler.HandleTypes(tRecorder, httpRequest)
+
+		var definitions []*models.TypeDefinition
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.NoError(t, json.Unmarshal(tRecorder.Body.Bytes(), &definitions))
+		assert.Equal(t, []*models.TypeDefinition{tDefinition}, definitions)
+	})
+}
@@ -0,0 +1,34 @@
+package tenant
+
+import (
+	"context"
+	"log/slog"
+)
+
+const cLogKeyTenant = "tenant"
+
+// logHandler adds the tenant of the context to every record logged with one
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps a handler so that records logged with a tenant scoped context carry a tenant attribute
+func NewLogHandler(handler slog.Handler) slog.Handler {
+	return &logHandler{Handler: handler}
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if name, ok := ctx.Value(tenantKey{}).(string); ok && name != "" {
+		record.AddAttrs(slog.String(cLogKeyTenant, name))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name)}
+}
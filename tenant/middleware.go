@@ -0,0 +1,44 @@
+package tenant
+
+import (
+	"net/http"
+
+	"anomaly_detector/api"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	cHeaderTenant   = "X-Tenant"
+	cRouteVarTenant = "tenant"
+)
+
+// Middleware scopes a request to the tenant named by the /tenants/{tenant} URL prefix or the X-Tenant header,
+// falling back to Default. A request naming two different tenants is rejected.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromURL := mux.Vars(r)[cRouteVarTenant]
+		fromHeader := r.Header.Get(cHeaderTenant)
+
+		if fromURL != "" && fromHeader != "" && fromURL != fromHeader {
+			api.RespondError(w, http.StatusBadRequest, "tenant in the URL and the X-Tenant header differ")
+			return
+		}
+
+		name := fromURL
+		if name == "" {
+			name = fromHeader
+		}
+
+		if name == "" {
+			name = Default
+		}
+
+		if err := Validate(name); err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), name)))
+	})
+}
@@ -0,0 +1,38 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Default is the tenant of requests that do not name one
+const Default = "default"
+
+// namePattern keeps tenant names safe to use in URLs, log lines and file names
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+type tenantKey struct{}
+
+// WithTenant returns a context scoping every store, validation and learning call made with it to a tenant
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, name)
+}
+
+// FromContext returns the tenant carried by a context, or Default if there is none
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(tenantKey{}).(string); ok && name != "" {
+		return name
+	}
+
+	return Default
+}
+
+// Validate checks that a tenant name is 1 to 63 lowercase letters, digits, dashes or underscores
+func Validate(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid tenant %q: must be 1 to 63 lowercase letters, digits, '-' or '_'", name)
+	}
+
+	return nil
+}
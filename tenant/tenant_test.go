@@ -0,0 +1,79 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	serve := func(httpRequest *http.Request) (*httptest.ResponseRecorder, string) {
+		var seen string
+
+		handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			seen = FromContext(r.Context())
+		}))
+
+		tRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(tRecorder, httpRequest)
+
+		return tRecorder, seen
+	}
+
+	t.Run("default tenant without URL prefix or header", func(t *testing.T) {
+		tRecorder, seen := serve(httptest.NewRequest(http.MethodGet, "/models", nil))
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, Default, seen)
+	})
+
+	t.Run("tenant from header or URL prefix", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, "/models", nil)
+		httpRequest.Header.Set(cHeaderTenant, "acme")
+
+		_, seen := serve(httpRequest)
+		assert.Equal(t, "acme", seen)
+
+		httpRequest = httptest.NewRequest(http.MethodGet, "/tenants/globex/models", nil)
+		httpRequest = mux.SetURLVars(httpRequest, map[string]string{cRouteVarTenant: "globex"})
+		httpRequest.Header.Set(cHeaderTenant, "globex")
+
+		_, seen = serve(httpRequest)
+		assert.Equal(t, "globex", seen)
+	})
+
+	t.Run("error on conflicting or invalid tenants", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodGet, "/tenants/globex/models", nil)
+		httpRequest = mux.SetURLVars(httpRequest, map[string]string{cRouteVarTenant: "globex"})
+		httpRequest.Header.Set(cHeaderTenant, "acme")
+
+		tRecorder, seen := serve(httpRequest)
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+		assert.Empty(t, seen)
+
+		httpRequest = httptest.NewRequest(http.MethodGet, "/models", nil)
+		httpRequest.Header.Set(cHeaderTenant, "../acme")
+
+		tRecorder, _ = serve(httpRequest)
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+}
+
+func TestLogHandler(t *testing.T) {
+	var buffer bytes.Buffer
+
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buffer, nil))).With("component", "test")
+
+	logger.InfoContext(WithTenant(context.Background(), "acme"), "scoped")
+	assert.Contains(t, buffer.String(), "msg=scoped component=test tenant=acme")
+
+	buffer.Reset()
+	logger.InfoContext(context.Background(), "unscoped")
+	assert.NotContains(t, buffer.String(), "tenant=")
+}
@@ -0,0 +1,136 @@
+// Package typeregistry holds the parameter types implemented in Go, next to the built-in ones.
+// Types defined at runtime through the API belong to a tenant and are kept by the model store instead.
+package typeregistry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"anomaly_detector/models"
+)
+
+// namePattern is what a custom type name may look like, e.g. IPv4, Phone or ISO-Country
+var namePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,62}$`)
+
+// builtinTypes are implemented by the validator itself and can be neither registered nor defined
+var builtinTypes = map[models.ParamType]struct{}{
+	models.TypeString:    {},
+	models.TypeInt:       {},
+	models.TypeBoolean:   {},
+	models.TypeList:      {},
+	models.TypeDate:      {},
+	models.TypeEmail:     {},
+	models.TypeUUID:      {},
+	models.TypeAuthToken: {},
+	models.TypeObject:    {},
+	models.TypeIP:        {},
+	models.TypeCIDR:      {},
+	models.TypeURL:       {},
+	models.TypeHostname:  {},
+	models.TypePhone:     {},
+	models.TypeTimestamp: {},
+	models.TypeBase64:    {},
+	models.TypeJWT:       {},
+	models.TypeNumber:    {},
+	models.TypeFloat:     {},
+	models.TypeNull:      {},
+}
+
+// Type is a parameter type implemented in Go, for checks a pattern, an enum or a composition cannot express.
+// Match receives the value as decoded from the request: path segments are always strings, while query,
+// header and body values keep their JSON type, numbers being json.Number.
+type Type interface {
+	Match(value any) bool
+}
+
+// TypeFunc adapts an ordinary function to the Type interface
+type TypeFunc func(value any) bool
+
+func (f TypeFunc) Match(value any) bool {
+	return f(value)
+}
+
+var (
+	mu         sync.RWMutex
+	registered = make(map[models.ParamType]Type)
+)
+
+// Register makes a Go type available to every tenant under name. It is meant to be called during
+// initialization and panics if the name is invalid, built-in or already registered.
+func Register(name models.ParamType, paramType Type) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := ValidateName(name); err != nil {
+		panic(err)
+	}
+
+	if paramType == nil {
+		panic(fmt.Sprintf("typeregistry: type %s is nil", name))
+	}
+
+	if _, exists := registered[name]; exists {
+		panic(fmt.Sprintf("typeregistry: type %s is already registered", name))
+	}
+
+	registered[name] = paramType
+}
+
+// Lookup returns the Go type registered under name
+func Lookup(name models.ParamType) (Type, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	paramType, exists := registered[name]
+
+	return paramType, exists
+}
+
+// Registered returns the names of the Go types, sorted
+func Registered() []models.ParamType {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]models.ParamType, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	return names
+}
+
+// IsBuiltin reports whether name is one of the types implemented by the validator itself
+func IsBuiltin(name models.ParamType) bool {
+	_, exists := builtinTypes[name]
+	return exists
+}
+
+// IsGlobal reports whether name is a built-in or a registered Go type, both known to every tenant
+func IsGlobal(name models.ParamType) bool {
+	if IsBuiltin(name) {
+		return true
+	}
+
+	_, exists := Lookup(name)
+
+	return exists
+}
+
+// ValidateName checks that name can be used for a custom type: it must look like IPv4 or ISO-Country
+// and must not shadow a built-in type
+func ValidateName(name models.ParamType) error {
+	if !namePattern.MatchString(string(name)) {
+		return fmt.Errorf("invalid type name %q: must start with a letter and hold at most 63 letters, digits, "+
+			"underscores or dashes", name)
+	}
+
+	if IsBuiltin(name) {
+		return fmt.Errorf("type %s is built-in and cannot be redefined", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package typeregistry
+
+import (
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	tIsEven := TypeFunc(func(value any) bool {
+		number, ok := value.(float64)
+		return ok && int(number)%2 == 0
+	})
+
+	Register("Even", tIsEven)
+
+	paramType, exists := Lookup("Even")
+	assert.True(t, exists)
+	assert.True(t, paramType.Match(float64(2)))
+	assert.False(t, paramType.Match("2"))
+	assert.Contains(t, Registered(), models.ParamType("Even"))
+	assert.True(t, IsGlobal("Even"))
+
+	assert.Panics(t, func() { Register("Even", tIsEven) }, "duplicate name")
+	assert.Panics(t, func() { Register(models.TypeEmail, tIsEven) }, "built-in name")
+	assert.Panics(t, func() { Register("not a name", tIsEven) }, "invalid name")
+	assert.Panics(t, func() { Register("Odd", nil) }, "nil type")
+
+	_, exists = Lookup("Odd")
+	assert.False(t, exists)
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []models.ParamType{"IPv4", "Mobile", "ISO-Country", "snake_case"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+
+	invalid := []models.ParamType{"", "4IP", "with space", "a.b", models.TypeInt, models.TypeAuthToken, models.TypePhone}
+	for _, name := range invalid {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
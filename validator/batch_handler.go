@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"anomaly_detector/api"
+	"anomaly_detector/models"
+)
+
+const cContentTypeNDJSON = "application/x-ndjson"
+
+// ndjsonContentTypes are the media types accepted for newline-delimited JSON batches
+var ndjsonContentTypes = map[string]struct{}{
+	cContentTypeNDJSON:        {},
+	"application/jsonl":       {},
+	"application/x-jsonlines": {},
+	"application/jsonlines":   {},
+}
+
+// HandleBatch validates a JSON array or an NDJSON stream of requests, chosen by Content-Type.
+// Requests are decoded and validated one at a time and each result is streamed back as one NDJSON line
+// carrying its input index, so large captures are processed with bounded memory.
+// A malformed item produces an error line and processing continues with the next one.
+func (h *validateHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "application/json"
+	}
+
+	if _, isNDJSON := ndjsonContentTypes[mediaType]; isNDJSON {
+		h.streamResults(ctx, w, func(emit func(req *models.Request, err error)) error {
+			return readNDJSON(r.Body, func(item []byte) { emit(decodeRequest(item)) })
+		})
+
+		return
+	}
+
+	decoder := newArrayDecoder(r.Body)
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		api.RespondError(w, http.StatusBadRequest, "invalid JSON provided: expected an array of requests")
+		return
+	}
+
+	h.streamResults(ctx, w, func(emit func(req *models.Request, err error)) error {
+		return readJSONArray(decoder, func(item []byte) { emit(decodeRequest(item)) })
+	})
+}
+
+// streamResults writes one result line per request produced by read, or an error line for a request that could
+// not be read. A read error ends the stream with an error line.
+func (h *validateHandler) streamResults(
+	ctx context.Context, w http.ResponseWriter, read func(emit func(req *models.Request, err error)) error) {
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	index := 0
+
+	w.Header().Set("Content-Type", cContentTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+
+	writeLine := func(line *models.BatchValidationResult) {
+		if err := encoder.Encode(line); err != nil {
+			slog.ErrorContext(ctx, "failed to write batch result", "error", err)
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	err := read(func(req *models.Request, err error) {
+		writeLine(h.validateItem(ctx, index, req, err))
+		index++
+	})
+	if err != nil {
+		writeLine(&models.BatchValidationResult{Index: index, Error: err.Error()})
+	}
+
+	slog.InfoContext(ctx, "Batch validation finished", "requests", index)
+}
+
+// decodeRequest decodes a single batch item
+func decodeRequest(item []byte) (*models.Request, error) {
+	var req models.Request
+	if err := json.Unmarshal(item, &req); err != nil {
+		return nil, errors.New("invalid JSON provided")
+	}
+
+	return &req, nil
+}
+
+// validateItem validates a single batch request, or reports the error reading it
+func (h *validateHandler) validateItem(
+	ctx context.Context, index int, req *models.Request, err error,
+) *models.BatchValidationResult {
+	if err != nil {
+		return &models.BatchValidationResult{Index: index, Error: err.Error()}
+	}
+
+	result, err := h.validate(ctx, req)
+	if err != nil {
+		return &models.BatchValidationResult{Index: index, Error: err.Error()}
+	}
+
+	return &models.BatchValidationResult{Index: index, Result: result}
+}
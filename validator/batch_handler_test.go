@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const tValidateBatchPath = "/validate/batch"
+
+func decodeBatchLines(t *testing.T, recorder *httptest.ResponseRecorder) []*models.BatchValidationResult {
+	t.Helper()
+
+	var lines []*models.BatchValidationResult
+
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		var line models.BatchValidationResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+
+		lines = append(lines, &line)
+	}
+
+	return lines
+}
+
+func TestValidateBatch(t *testing.T) {
+	tStoreMock := store.NewMockIModelStore(t)
+	tValidatorMock := NewMockIRequestValidator(t)
+
+	tHandler := &validateHandler{
+		store:     tStoreMock,
+		validator: tValidatorMock,
+	}
+
+	validRequest := `{"path":"/users/info","method":"GET","headers":[{"name":"Authorization","value":"Bearer abc"}]}`
+	unknownRequest := `{"path":"/nonexistent","method":"GET"}`
+	tResult := &models.ValidationResult{Valid: true}
+
+	expectedLines := []*models.BatchValidationResult{
+		{Index: 0, Result: tResult},
+		{Index: 1, Error: "invalid JSON provided"},
+		{Index: 2, Error: "no model found for endpoint GET /nonexistent"},
+		{Index: 3, Result: tResult},
+	}
+
+	setupMocks := func() {
+		tStoreMock.EXPECT().
+			Match(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tModel, nil).Twice()
+		tStoreMock.EXPECT().
+			Match(mock.Anything, "/nonexistent", http.MethodGet).
+			Return(nil, assert.AnError).Once()
+		tValidatorMock.EXPECT().
+			Validate(mock.Anything, mock.Anything, tModel).
+			Return(tResult).Twice()
+	}
+
+	t.Run("NDJSON stream continues past malformed lines", func(t *testing.T) {
+		body := strings.Join([]string{validRequest, `{"path": broken`, "", unknownRequest, validRequest}, "\n")
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidateBatchPath, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", "application/x-ndjson")
+		tRecorder := httptest.NewRecorder()
+
+		setupMocks()
+
+		tHandler.HandleBatch(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, "application/x-ndjson", tRecorder.Header().Get("Content-Type"))
+		assert.Equal(t, expectedLines, decodeBatchLines(t, tRecorder))
+	})
+
+	t.Run("JSON array continues past items of the wrong shape", func(t *testing.T) {
+		body := "[" + strings.Join([]string{validRequest, `"not a request"`, unknownRequest, validRequest}, ",") + "]"
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidateBatchPath, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", "application/json")
+		tRecorder := httptest.NewRecorder()
+
+		setupMocks()
+
+		tHandler.HandleBatch(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Equal(t, expectedLines, decodeBatchLines(t, tRecorder))
+	})
+
+	t.Run("JSON array syntax error ends the stream", func(t *testing.T) {
+		body := "[" + validRequest + `, {"path": broken}]`
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidateBatchPath, strings.NewReader(body))
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Match(mock.Anything, tUsersInfoPath, http.MethodGet).
+			Return(tModel, nil).Once()
+		tValidatorMock.EXPECT().
+			Validate(mock.Anything, mock.Anything, tModel).
+			Return(tResult).Once()
+
+		tHandler.HandleBatch(tRecorder, httpRequest)
+
+		lines := decodeBatchLines(t, tRecorder)
+		require.Len(t, lines, 2)
+		assert.Equal(t, tResult, lines[0].Result)
+		assert.Equal(t, 1, lines[1].Index)
+		assert.Contains(t, lines[1].Error, "invalid JSON provided")
+	})
+
+	t.Run("oversized request ends the stream in both formats", func(t *testing.T) {
+		oversized := `{"path":"/users/info","method":"GET","body":[{"name":"blob","value":"` +
+			strings.Repeat("a", cMaxBatchLineSize) + `"}]}`
+
+		bodies := map[string]string{
+			"application/json":     "[" + validRequest + "," + oversized + "," + validRequest + "]",
+			"application/x-ndjson": validRequest + "\n" + oversized + "\n" + validRequest,
+		}
+
+		for contentType, body := range bodies {
+			httpRequest := httptest.NewRequest(http.MethodPost, tValidateBatchPath, strings.NewReader(body))
+			httpRequest.Header.Set("Content-Type", contentType)
+			tRecorder := httptest.NewRecorder()
+
+			tStoreMock.EXPECT().
+				Match(mock.Anything, tUsersInfoPath, http.MethodGet).
+				Return(tModel, nil).Once()
+			tValidatorMock.EXPECT().
+				Validate(mock.Anything, mock.Anything, tModel).
+				Return(tResult).Once()
+
+			tHandler.HandleBatch(tRecorder, httpRequest)
+
+			assert.Equal(t, []*models.BatchValidationResult{
+				{Index: 0, Result: tResult},
+				{Index: 1, Error: errRequestTooLarge.Error()},
+			}, decodeBatchLines(t, tRecorder), contentType)
+		}
+	})
+
+	t.Run("error when body is not an array", func(t *testing.T) {
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidateBatchPath, strings.NewReader(validRequest))
+		httpRequest.Header.Set("Content-Type", "application/json")
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleBatch(tRecorder, httpRequest)
+
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+}
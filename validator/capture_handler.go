@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"anomaly_detector/api"
+	"anomaly_detector/capture"
+	"anomaly_detector/models"
+)
+
+const (
+	cContentTypeHAR = "application/har+json"
+
+	// cMaxCaptureSize bounds the memory used by a HAR document, which is decoded at once
+	cMaxCaptureSize = 64 * 1024 * 1024
+)
+
+// HandleCapture validates captured traffic, chosen by Content-Type. A HAR log or entry (application/json or
+// application/har+json) is answered like HandleBatch, one NDJSON line per entry. Any other body is a raw
+// HTTP/1.1 request as sent on the wire (e.g. message/http), answered like Handle.
+func (h *validateHandler) HandleCapture(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(w, r.Body, cMaxCaptureSize)
+
+	if mediaType != "application/json" && mediaType != cContentTypeHAR {
+		req, err := capture.ParseRaw(body)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		result, err := h.validate(ctx, req)
+		if err != nil {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		api.RespondJSON(w, http.StatusOK, result)
+
+		return
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "failed to read HAR document")
+		return
+	}
+
+	entries, err := capture.ParseHAR(data)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.streamResults(ctx, w, func(emit func(req *models.Request, err error)) error {
+		for _, entry := range entries {
+			emit(entry.ToRequest())
+		}
+
+		return nil
+	})
+}
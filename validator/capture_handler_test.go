@@ -0,0 +1,85 @@
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"anomaly_detector/models"
+	"anomaly_detector/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const tValidateCapturePath = "/validate/capture"
+
+func TestValidateCapture(t *testing.T) {
+	tStoreMock := store.NewMockIModelStore(t)
+
+	tHandler := &validateHandler{
+		store:     tStoreMock,
+		validator: NewRequestValidator(),
+	}
+
+	post := func(contentType, body string) *httptest.ResponseRecorder {
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidateCapturePath, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", contentType)
+		tRecorder := httptest.NewRecorder()
+
+		tHandler.HandleCapture(tRecorder, httpRequest)
+
+		return tRecorder
+	}
+
+	t.Run("raw HTTP request", func(t *testing.T) {
+		tStoreMock.EXPECT().Match(mock.Anything, tUsersInfoPath, http.MethodGet).Return(tModel, nil).Once()
+
+		raw := "GET /users/info HTTP/1.1\r\nHost: api.example.com\r\nauthorization: Bearer abc\r\n\r\n"
+		tRecorder := post("message/http", raw)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.JSONEq(t, `{"valid": true, "risk_score": 0}`, tRecorder.Body.String())
+	})
+
+	t.Run("raw HTTP request with anomalies", func(t *testing.T) {
+		tStoreMock.EXPECT().Match(mock.Anything, tUsersInfoPath, http.MethodGet).Return(tModel, nil).Once()
+
+		raw := "GET /users/info HTTP/1.1\r\nHost: api.example.com\r\nAuthorization: Basic abc\r\n\r\n"
+		tRecorder := post("message/http", raw)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+		assert.Contains(t, tRecorder.Body.String(), `"code":"TYPE_MISMATCH"`)
+	})
+
+	t.Run("malformed raw HTTP request", func(t *testing.T) {
+		tRecorder := post("message/http", "not an HTTP request")
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+
+	t.Run("HAR log is streamed entry by entry", func(t *testing.T) {
+		tStoreMock.EXPECT().Match(mock.Anything, tUsersInfoPath, http.MethodGet).Return(tModel, nil).Once()
+
+		har := `{"log": {"entries": [
+			{"request": {"method": "GET", "url": "https://api.example.com/users/info",
+				"headers": [{"name": ":authority", "value": "api.example.com"},
+					{"name": "Authorization", "value": "Bearer abc"}]}},
+			{"request": {"method": "GET", "url": "::"}}
+		]}}`
+		tRecorder := post("application/har+json", har)
+
+		assert.Equal(t, http.StatusOK, tRecorder.Code)
+
+		lines := decodeBatchLines(t, tRecorder)
+		assert.Len(t, lines, 2)
+		assert.Equal(t, &models.BatchValidationResult{Index: 0, Result: &models.ValidationResult{Valid: true}}, lines[0])
+		assert.Equal(t, 1, lines[1].Index)
+		assert.Contains(t, lines[1].Error, "invalid HAR request")
+	})
+
+	t.Run("malformed HAR document", func(t *testing.T) {
+		tRecorder := post("application/json", `{"entries": []}`)
+		assert.Equal(t, http.StatusBadRequest, tRecorder.Code)
+	})
+}
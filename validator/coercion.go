@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"anomaly_detector/models"
+)
+
+// cListSeparator separates the items of a List sent as a single string, e.g. ?ids=1,2,3
+const cListSeparator = ","
+
+// jsonNumberRegex matches the number grammar of JSON, which json.Number values must follow
+var jsonNumberRegex = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerceString parses a string as an Int, a Number or Float, a Boolean (true, false, 1 or 0) or a List of comma
+// separated items, the way URLs and headers carry them. ok is false for other types and for strings that do not
+// parse. Numbers are json.Number, as if they were decoded from a request.
+func coerceString(value string, typeName models.ParamType) (any, bool) {
+	switch typeName {
+	case models.TypeInt:
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(number, 10)), true
+		}
+
+		if number, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return json.Number(strconv.FormatUint(number, 10)), true
+		}
+
+		return nil, false
+
+	case models.TypeNumber, models.TypeFloat:
+		if !jsonNumberRegex.MatchString(value) {
+			return nil, false
+		}
+
+		return json.Number(value), true
+
+	case models.TypeBoolean:
+		switch value {
+		case "true", "1":
+			return true, true
+		case "false", "0":
+			return false, true
+		default:
+			return nil, false
+		}
+
+	case models.TypeList:
+		items := []any{}
+
+		if value != "" {
+			for item := range strings.SplitSeq(value, cListSeparator) {
+				items = append(items, item)
+			}
+		}
+
+		return items, true
+
+	default:
+		return nil, false
+	}
+}
+
+// coerceValues converts the string values of a parameter before they are matched against its types.
+// A name sent several times forms a single List when the parameter is a List that is not repeated.
+func (sv *sectionValidation) coerceValues(values []any, modelParam *models.Parameter) []any {
+	if len(values) > 1 && !modelParam.Repeated && slices.Contains(modelParam.Types, models.TypeList) {
+		return []any{sv.coerceItems(slices.Clone(values), modelParam.Items)}
+	}
+
+	coerced := make([]any, len(values))
+	for i, value := range values {
+		coerced[i] = sv.coerceValue(value, modelParam)
+	}
+
+	return coerced
+}
+
+// coerceValue converts a string to the first type of its parameter it is valid for. A string already valid
+// for an earlier type is kept, so that "42" stays a string for a String or Int parameter. Values that are not
+// strings were typed by the client and are matched as they are.
+func (sv *sectionValidation) coerceValue(value any, modelParam *models.Parameter) any {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	for _, typeName := range modelParam.Types {
+		if coerced, ok := coerceString(str, typeName); ok {
+			if items, isList := coerced.([]any); isList {
+				return sv.coerceItems(items, modelParam.Items)
+			}
+
+			return coerced
+		}
+
+		if sv.matchesParam(str, typeName, modelParam) {
+			return str
+		}
+	}
+
+	return value
+}
+
+// coerceItems converts the items of a List in place according to the items schema, if any
+func (sv *sectionValidation) coerceItems(items []any, itemsParam *models.Parameter) []any {
+	if itemsParam == nil {
+		return items
+	}
+
+	for i, item := range items {
+		items[i] = sv.coerceValue(item, itemsParam)
+	}
+
+	return items
+}
@@ -0,0 +1,217 @@
+package validator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"anomaly_detector/models"
+)
+
+const (
+	cConstraintMinimum   = "minimum"
+	cConstraintMaximum   = "maximum"
+	cConstraintMinLength = "min_length"
+	cConstraintMaxLength = "max_length"
+	cConstraintPattern   = "pattern"
+	cConstraintEnum      = "enum"
+)
+
+// validateConstraints checks a value that already matched matchedType against the optional constraints
+// of its parameter, producing one anomaly per violated constraint
+func (rv *requestValidator) validateConstraints(
+	value any, matchedType models.ParamType, modelParam *models.Parameter, field, name string, severity models.Severity,
+) []*models.FieldAnomaly {
+	var anomalies []*models.FieldAnomaly
+
+	violation := func(constraint string, expected, actual any, format string, args ...any) {
+		anomalies = append(anomalies, &models.FieldAnomaly{
+			Field:         field,
+			ParameterName: name,
+			Code:          models.CodeConstraintViolation,
+			Severity:      severity,
+			Reason:        fmt.Sprintf("constraint %q violated: %s", constraint, fmt.Sprintf(format, args...)),
+			Constraint:    constraint,
+			Expected:      expected,
+			Actual:        actual,
+		})
+	}
+
+	if text, ok := numberText(value); ok {
+		if modelParam.Minimum != nil && compareToBound(text, *modelParam.Minimum) < 0 {
+			violation(cConstraintMinimum, *modelParam.Minimum, value,
+				"value %v is less than %v", text, *modelParam.Minimum)
+		}
+
+		if modelParam.Maximum != nil && compareToBound(text, *modelParam.Maximum) > 0 {
+			violation(cConstraintMaximum, *modelParam.Maximum, value,
+				"value %v is greater than %v", text, *modelParam.Maximum)
+		}
+	}
+
+	if length, ok := valueLength(value); ok {
+		if modelParam.MinLength != nil && length < *modelParam.MinLength {
+			violation(cConstraintMinLength, *modelParam.MinLength, length,
+				"length %d is less than %d", length, *modelParam.MinLength)
+		}
+
+		if modelParam.MaxLength != nil && length > *modelParam.MaxLength {
+			violation(cConstraintMaxLength, *modelParam.MaxLength, length,
+				"length %d is greater than %d", length, *modelParam.MaxLength)
+		}
+	}
+
+	if str, ok := value.(string); ok && modelParam.Pattern != "" && !rv.matchesPattern(str, modelParam.Pattern) {
+		violation(cConstraintPattern, modelParam.Pattern, str, "value does not match %q", modelParam.Pattern)
+	}
+
+	if len(modelParam.Enum) > 0 && !enumContains(modelParam.Enum, value) {
+		violation(cConstraintEnum, modelParam.Enum, value, "value %v is not one of %v", value, modelParam.Enum)
+	}
+
+	rv.validateTimeRange(value, matchedType, modelParam, violation)
+
+	return anomalies
+}
+
+// matchesPattern compiles each pattern once. Patterns are checked when models are stored,
+// so a compile error here only happens for models built outside the store and is treated as a violation.
+func (rv *requestValidator) matchesPattern(value, pattern string) bool {
+	if cached, ok := rv.patterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp).MatchString(value)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	rv.patterns.Store(pattern, re)
+
+	return re.MatchString(value)
+}
+
+// cMaxExactExponent bounds the exponents of the numbers compared exactly. Numbers beyond it are out of the range
+// of float64 bounds anyway, and an exact rational would take as many digits as the exponent.
+const cMaxExactExponent = 1000
+
+// CompareNumbers compares two numbers exactly, returning -1, 0 or +1. ok is false unless both are numbers,
+// i.e. json.Number, float64 or a Go integer. Unlike a float64 conversion, it tells 64-bit integers apart.
+func CompareNumbers(a, b any) (int, bool) {
+	textA, okA := numberText(a)
+	textB, okB := numberText(b)
+
+	if !okA || !okB {
+		return 0, false
+	}
+
+	return compareNumberTexts(textA, textB), true
+}
+
+// numberText returns the decimal text of a number held by a value, so that it can be compared exactly.
+// Strings are never numbers: the strings of coerced sections were converted to json.Number before.
+func numberText(value any) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return string(v), jsonNumberRegex.MatchString(string(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	default:
+		return "", false
+	}
+}
+
+// compareToBound compares the decimal text of a number with a float64 bound of a model
+func compareToBound(text string, bound float64) int {
+	return compareNumberTexts(text, strconv.FormatFloat(bound, 'g', -1, 64))
+}
+
+// compareNumberTexts compares two decimal numbers following the JSON grammar: as 64-bit integers when both are,
+// else as rationals
+func compareNumberTexts(a, b string) int {
+	if x, err := strconv.ParseInt(a, 10, 64); err == nil {
+		if y, err := strconv.ParseInt(b, 10, 64); err == nil {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	if x, err := strconv.ParseUint(a, 10, 64); err == nil {
+		if y, err := strconv.ParseUint(b, 10, 64); err == nil {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	x, okX := exactRat(a)
+	y, okY := exactRat(b)
+
+	if !okX || !okY {
+		floatX, _ := strconv.ParseFloat(a, 64)
+		floatY, _ := strconv.ParseFloat(b, 64)
+
+		return cmp.Compare(floatX, floatY)
+	}
+
+	return x.Cmp(y)
+}
+
+// exactRat parses a decimal number as a rational, unless its exponent exceeds cMaxExactExponent
+func exactRat(text string) (*big.Rat, bool) {
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		exponent, err := strconv.Atoi(text[i+1:])
+		if err != nil || exponent > cMaxExactExponent || exponent < -cMaxExactExponent {
+			return nil, false
+		}
+	}
+
+	return new(big.Rat).SetString(text)
+}
+
+// valueLength returns the number of characters of a string or the number of items of a list
+func valueLength(value any) (int, bool) {
+	if str, ok := value.(string); ok {
+		return utf8.RuneCountInString(str), true
+	}
+
+	if items := listItems(value); items != nil {
+		return len(items), true
+	}
+
+	return 0, false
+}
+
+// enumContains compares numbers by value, so an enum of 1 accepts both the JSON number 1 and the Go int 1.
+// Other values must match exactly, so an enum of "5" does not accept the number 5, nor an enum of 5 the string "5".
+func enumContains(enum []any, value any) bool {
+	text, isNumber := numberText(value)
+
+	for _, allowed := range enum {
+		if isNumber {
+			if allowedText, ok := numberText(allowed); ok && compareNumberTexts(allowedText, text) == 0 {
+				return true
+			}
+		}
+
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,218 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestValidateConstraints(t *testing.T) {
+	testCases := []struct {
+		name        string
+		param       *models.Parameter
+		value       any
+		matchedType models.ParamType
+		reasons     []string
+	}{
+		{
+			name:        "number within range",
+			param:       &models.Parameter{Minimum: ptr(1.0), Maximum: ptr(100.0)},
+			value:       float64(50),
+			matchedType: models.TypeInt,
+		},
+		{
+			name:        "number below minimum",
+			param:       &models.Parameter{Minimum: ptr(1.0)},
+			value:       float64(-5000),
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "minimum" violated: value -5000 is less than 1`},
+		},
+		{
+			name:        "number above maximum",
+			param:       &models.Parameter{Maximum: ptr(100.0)},
+			value:       101,
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "maximum" violated: value 101 is greater than 100`},
+		},
+		{
+			name:        "64-bit integer above maximum is compared exactly",
+			param:       &models.Parameter{Maximum: ptr(9007199254740992.0)},
+			value:       json.Number("9007199254740993"),
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "maximum" violated: value 9007199254740993 is greater than 9.007199254740992e+15`},
+		},
+		{
+			name:        "decimal at the minimum",
+			param:       &models.Parameter{Minimum: ptr(0.1)},
+			value:       json.Number("0.1"),
+			matchedType: models.TypeNumber,
+		},
+		{
+			name:        "decimal just below the minimum",
+			param:       &models.Parameter{Minimum: ptr(0.1)},
+			value:       json.Number("0.09999999999999999999"),
+			matchedType: models.TypeNumber,
+			reasons:     []string{`constraint "minimum" violated: value 0.09999999999999999999 is less than 0.1`},
+		},
+		{
+			name:        "coerced path segment below minimum",
+			param:       &models.Parameter{Minimum: ptr(1.0)},
+			value:       json.Number("0"),
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "minimum" violated: value 0 is less than 1`},
+		},
+		{
+			name:        "string length within range",
+			param:       &models.Parameter{MinLength: ptr(2), MaxLength: ptr(4)},
+			value:       "abc",
+			matchedType: models.TypeString,
+		},
+		{
+			name:        "string too short and too long are counted in characters",
+			param:       &models.Parameter{MinLength: ptr(6), MaxLength: ptr(4)},
+			value:       "héllo",
+			matchedType: models.TypeString,
+			reasons: []string{
+				`constraint "min_length" violated: length 5 is less than 6`,
+				`constraint "max_length" violated: length 5 is greater than 4`,
+			},
+		},
+		{
+			name:        "list too long",
+			param:       &models.Parameter{MaxLength: ptr(2)},
+			value:       []any{1, 2, 3},
+			matchedType: models.TypeList,
+			reasons:     []string{`constraint "max_length" violated: length 3 is greater than 2`},
+		},
+		{
+			name:        "pattern match",
+			param:       &models.Parameter{Pattern: `^[A-Z]{3}-\d+$`},
+			value:       "SKU-123",
+			matchedType: models.TypeString,
+		},
+		{
+			name:        "pattern mismatch",
+			param:       &models.Parameter{Pattern: `^[A-Z]{3}-\d+$`},
+			value:       "sku-123",
+			matchedType: models.TypeString,
+			reasons:     []string{`constraint "pattern" violated: value does not match "^[A-Z]{3}-\\d+$"`},
+		},
+		{
+			name:        "enum string match",
+			param:       &models.Parameter{Enum: []any{"active", "inactive"}},
+			value:       "active",
+			matchedType: models.TypeString,
+		},
+		{
+			name:        "enum string mismatch",
+			param:       &models.Parameter{Enum: []any{"active", "inactive"}},
+			value:       "anything",
+			matchedType: models.TypeString,
+			reasons:     []string{`constraint "enum" violated: value anything is not one of [active inactive]`},
+		},
+		{
+			name:        "enum number compared by value",
+			param:       &models.Parameter{Enum: []any{float64(1), float64(2)}},
+			value:       2,
+			matchedType: models.TypeInt,
+		},
+		{
+			name:        "enum of 64-bit integers compared exactly",
+			param:       &models.Parameter{Enum: []any{json.Number("9007199254740993")}},
+			value:       json.Number("9007199254740992"),
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "enum" violated: value 9007199254740992 is not one of [9007199254740993]`},
+		},
+		{
+			name:        "enum string does not accept the number",
+			param:       &models.Parameter{Enum: []any{"5"}},
+			value:       json.Number("5"),
+			matchedType: models.TypeInt,
+			reasons:     []string{`constraint "enum" violated: value 5 is not one of [5]`},
+		},
+		{
+			name:        "enum number does not accept the string",
+			param:       &models.Parameter{Enum: []any{float64(5)}},
+			value:       "5",
+			matchedType: models.TypeString,
+			reasons:     []string{`constraint "enum" violated: value 5 is not one of [5]`},
+		},
+		{
+			name:        "constraints of other kinds are ignored",
+			param:       &models.Parameter{Minimum: ptr(10.0), Pattern: `^\d+$`},
+			value:       true,
+			matchedType: models.TypeBoolean,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rv := &requestValidator{}
+
+			anomalies := rv.validateConstraints(
+				tc.value, tc.matchedType, tc.param, cFieldBody, "param", models.SeverityWarning)
+
+			reasons := make([]string, 0, len(anomalies))
+			for _, anomaly := range anomalies {
+				assert.Equal(t, cFieldBody, anomaly.Field)
+				assert.Equal(t, "param", anomaly.ParameterName)
+				assert.Equal(t, models.CodeConstraintViolation, anomaly.Code)
+				assert.Equal(t, models.SeverityWarning, anomaly.Severity)
+
+				reasons = append(reasons, anomaly.Reason)
+			}
+
+			assert.ElementsMatch(t, tc.reasons, reasons)
+		})
+	}
+}
+
+func TestConstraintExpectedAndActual(t *testing.T) {
+	rv := &requestValidator{}
+	param := &models.Parameter{MaxLength: ptr(2), Enum: []any{"a", "b"}}
+
+	anomalies := rv.validateConstraints("abc", models.TypeString, param, cFieldBody, "param", models.SeverityCritical)
+
+	assert.Len(t, anomalies, 2)
+	assert.Equal(t, cConstraintMaxLength, anomalies[0].Constraint)
+	assert.Equal(t, 2, anomalies[0].Expected)
+	assert.Equal(t, 3, anomalies[0].Actual)
+	assert.Equal(t, cConstraintEnum, anomalies[1].Constraint)
+	assert.Equal(t, []any{"a", "b"}, anomalies[1].Expected)
+	assert.Equal(t, "abc", anomalies[1].Actual)
+}
+
+func TestCompareNumbers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     any
+		expected int
+	}{
+		{name: "integers beyond float64 precision", a: json.Number("9007199254740993"), b: 9007199254740992.0, expected: 1},
+		{
+			name: "unsigned 64-bit integers",
+			a:    json.Number("18446744073709551615"), b: json.Number("18446744073709551614"), expected: 1,
+		},
+		{name: "integer and float", a: 2, b: json.Number("2.0"), expected: 0},
+		{name: "exponents", a: json.Number("1e3"), b: json.Number("999.5"), expected: 1},
+		{name: "exponents beyond exact comparison", a: json.Number("-1e5000"), b: -1.0, expected: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := CompareNumbers(tc.a, tc.b)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, ok := CompareNumbers("1", 1)
+	assert.False(t, ok, "strings are not numbers")
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"time"
+
+	"anomaly_detector/dateformat"
+	"anomaly_detector/models"
+)
+
+const (
+	cConstraintNotFuture      = "not_future"
+	cConstraintNotPast        = "not_past"
+	cConstraintWithinLastDays = "within_last_days"
+	cConstraintWithinNextDays = "within_next_days"
+
+	cDay = 24 * time.Hour
+)
+
+// defaultFormats are the formats of the time types for parameters that do not declare their own
+var defaultFormats = map[models.ParamType][]string{
+	models.TypeDate:      {dateformat.DDMMYYYY},
+	models.TypeTimestamp: {dateformat.RFC3339},
+}
+
+// matchesParam reports whether a value is of one of the types of its parameter. Date and Timestamp parameters
+// that declare formats accept any of them instead of the default format of their type.
+func (sv *sectionValidation) matchesParam(
+	value any,
+	typeName models.ParamType,
+	modelParam *models.Parameter,
+) bool {
+	if len(modelParam.Formats) > 0 && models.IsTimeType(typeName) {
+		_, ok := dateformat.Parse(value, modelParam.Formats)
+		return ok
+	}
+
+	return sv.matches(value, typeName)
+}
+
+// timeValue returns the time held by a value that matched a Date or Timestamp type
+func timeValue(value any, matchedType models.ParamType, modelParam *models.Parameter) (time.Time, bool) {
+	if !models.IsTimeType(matchedType) {
+		return time.Time{}, false
+	}
+
+	formats := modelParam.Formats
+	if len(formats) == 0 {
+		formats = defaultFormats[matchedType]
+	}
+
+	return dateformat.Parse(value, formats)
+}
+
+// validateTimeRange checks a Date or Timestamp value against the range constraints of its parameter,
+// reporting each violation
+func (rv *requestValidator) validateTimeRange(
+	value any, matchedType models.ParamType, modelParam *models.Parameter,
+	violation func(constraint string, expected, actual any, format string, args ...any),
+) {
+	if !modelParam.NotFuture && !modelParam.NotPast && modelParam.WithinLastDays == nil &&
+		modelParam.WithinNextDays == nil {
+		return
+	}
+
+	at, ok := timeValue(value, matchedType, modelParam)
+	if !ok {
+		return
+	}
+
+	now := rv.now()
+
+	if modelParam.NotFuture && at.After(now) {
+		violation(cConstraintNotFuture, true, value, "%v is in the future", value)
+	}
+
+	if modelParam.NotPast && at.Before(now) {
+		violation(cConstraintNotPast, true, value, "%v is in the past", value)
+	}
+
+	if days := modelParam.WithinLastDays; days != nil && at.Before(now.Add(-time.Duration(*days)*cDay)) {
+		violation(cConstraintWithinLastDays, *days, value, "%v is more than %d days ago", value, *days)
+	}
+
+	if days := modelParam.WithinNextDays; days != nil && at.After(now.Add(time.Duration(*days)*cDay)) {
+		violation(cConstraintWithinNextDays, *days, value, "%v is more than %d days ahead", value, *days)
+	}
+}
@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDateFormats(t *testing.T) {
+	testCases := []struct {
+		name    string
+		param   *models.Parameter
+		value   any
+		isValid bool
+	}{
+		{
+			name:    "default date format",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}},
+			value:   "31-12-2024",
+			isValid: true,
+		},
+		{
+			name:    "iso date rejected by the default format",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}},
+			value:   "2024-12-31",
+			isValid: false,
+		},
+		{
+			name:    "iso date",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{"iso8601"}},
+			value:   "2024-12-31",
+			isValid: true,
+		},
+		{
+			name:    "declared formats replace the default",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{"iso8601"}},
+			value:   "31-12-2024",
+			isValid: false,
+		},
+		{
+			name:    "impossible iso date",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{"iso8601"}},
+			value:   "2024-02-30",
+			isValid: false,
+		},
+		{
+			name:    "unix epoch number",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeTimestamp}, Formats: []string{"rfc3339", "unix"}},
+			value:   float64(1735603200),
+			isValid: true,
+		},
+		{
+			name:    "rfc3339 among several formats",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeTimestamp}, Formats: []string{"rfc3339", "unix"}},
+			value:   "2024-12-31T23:59:59Z",
+			isValid: true,
+		},
+		{
+			name:    "custom layout",
+			param:   &models.Parameter{Types: []models.ParamType{models.TypeDate}, Formats: []string{"2006/01/02"}},
+			value:   "2024/12/31",
+			isValid: true,
+		},
+		{
+			name: "formats do not apply to other types",
+			param: &models.Parameter{
+				Types: []models.ParamType{models.TypeInt, models.TypeDate}, Formats: []string{"iso8601"},
+			},
+			value:   "1735603200",
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.param.Name = "at"
+
+			tModel := &models.APIModel{Path: tTestPath, Method: http.MethodPost, Body: []*models.Parameter{tc.param}}
+			tRequest := &models.Request{
+				Path: tTestPath, Method: http.MethodPost, Body: []*models.RequestParam{{Name: "at", Value: tc.value}},
+			}
+
+			result := NewRequestValidator().Validate(context.Background(), tRequest, tModel)
+			assert.Equal(t, tc.isValid, result.Valid, "anomalies: %v", result.Anomalies)
+		})
+	}
+
+	t.Run("epoch path segments", func(t *testing.T) {
+		tModel := &models.APIModel{
+			Path: "/events/{at}", Method: http.MethodGet,
+			PathParams: []*models.Parameter{
+				{Name: "at", Types: []models.ParamType{models.TypeTimestamp}, Formats: []string{"unix"}},
+			},
+		}
+
+		valid := NewRequestValidator().Validate(context.Background(),
+			&models.Request{Path: "/events/1735603200", Method: http.MethodGet}, tModel)
+		invalid := NewRequestValidator().Validate(context.Background(),
+			&models.Request{Path: "/events/yesterday", Method: http.MethodGet}, tModel)
+
+		assert.True(t, valid.Valid)
+		assert.False(t, invalid.Valid)
+	})
+}
+
+func TestValidateTimeRange(t *testing.T) {
+	tNow := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rv := &requestValidator{now: func() time.Time { return tNow }}
+
+	testCases := []struct {
+		name        string
+		param       *models.Parameter
+		value       any
+		matchedType models.ParamType
+		constraints []string
+	}{
+		{
+			name:        "past date is not in the future",
+			param:       &models.Parameter{NotFuture: true, Formats: []string{"iso8601"}},
+			value:       "2026-02-28",
+			matchedType: models.TypeDate,
+		},
+		{
+			name:        "today is not in the future",
+			param:       &models.Parameter{NotFuture: true, Formats: []string{"iso8601"}},
+			value:       "2026-03-01",
+			matchedType: models.TypeDate,
+		},
+		{
+			name:        "future timestamp",
+			param:       &models.Parameter{NotFuture: true},
+			value:       "2026-03-01T12:00:01Z",
+			matchedType: models.TypeTimestamp,
+			constraints: []string{cConstraintNotFuture},
+		},
+		{
+			name:        "past default date",
+			param:       &models.Parameter{NotPast: true},
+			value:       "28-02-2026",
+			matchedType: models.TypeDate,
+			constraints: []string{cConstraintNotPast},
+		},
+		{
+			name:        "within the last days",
+			param:       &models.Parameter{WithinLastDays: ptr(30)},
+			value:       "2026-02-01T12:00:00Z",
+			matchedType: models.TypeTimestamp,
+		},
+		{
+			name:        "older than the last days",
+			param:       &models.Parameter{WithinLastDays: ptr(30), NotFuture: true, Formats: []string{"unix"}},
+			value:       float64(tNow.AddDate(0, 0, -31).Unix()),
+			matchedType: models.TypeTimestamp,
+			constraints: []string{cConstraintWithinLastDays},
+		},
+		{
+			name:        "further than the next days",
+			param:       &models.Parameter{WithinNextDays: ptr(7)},
+			value:       "2026-03-09T12:00:00Z",
+			matchedType: models.TypeTimestamp,
+			constraints: []string{cConstraintWithinNextDays},
+		},
+		{
+			name:        "ranges only apply to times",
+			param:       &models.Parameter{NotFuture: true},
+			value:       "2099-01-01",
+			matchedType: models.TypeString,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			anomalies := rv.validateConstraints(tc.value, tc.matchedType, tc.param, cFieldBody, "at", models.SeverityCritical)
+
+			var constraints []string
+			for _, anomaly := range anomalies {
+				assert.Equal(t, models.CodeConstraintViolation, anomaly.Code)
+				assert.Equal(t, tc.value, anomaly.Actual)
+
+				constraints = append(constraints, anomaly.Constraint)
+			}
+
+			assert.Equal(t, tc.constraints, constraints)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const cJWTSegments = 3
+
+// jwtToken is a JWT in compact serialization, decoded but not verified
+type jwtToken struct {
+	header    map[string]any
+	claims    map[string]any
+	signature []byte
+	// signingInput is the encoded header and claims the signature is computed over
+	signingInput string
+}
+
+// parseJWT decodes the header and the claims of a compact JWT. It checks the structure only:
+// three base64url segments, a header naming its alg and a claims object.
+func parseJWT(token string) (*jwtToken, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != cJWTSegments {
+		return nil, fmt.Errorf("a JWT has %d dot separated segments, not %d", cJWTSegments, len(segments))
+	}
+
+	header, err := decodeJWTObject(segments[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+
+	if alg, ok := header["alg"].(string); !ok || alg == "" {
+		return nil, errors.New("invalid JWT header: missing alg")
+	}
+
+	claims, err := decodeJWTObject(segments[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT signature encoding: %w", err)
+	}
+
+	return &jwtToken{
+		header: header, claims: claims, signature: signature, signingInput: segments[0] + "." + segments[1],
+	}, nil
+}
+
+// decodeJWTObject decodes a base64url segment holding a JSON object
+func decodeJWTObject(segment string) (map[string]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, fmt.Errorf("not base64url: %w", err)
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, errors.New("not a JSON object")
+	}
+
+	return object, nil
+}
@@ -0,0 +1,333 @@
+package validator
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"anomaly_detector/config"
+)
+
+const (
+	cJWKUseSignature = "sig"
+
+	cPEMPublicKey   = "PUBLIC KEY"
+	cPEMCertificate = "CERTIFICATE"
+)
+
+// jwtKey is a signature verification key: an *rsa.PublicKey, an *ecdsa.PublicKey, an ed25519.PublicKey,
+// or the []byte secret of HMAC algorithms
+type jwtKey struct {
+	// id matches the kid header of tokens, keys without one are tried for every token
+	id string
+	// alg restricts the key to a single algorithm, as a JWK may
+	alg string
+	key any
+}
+
+// jwtKeySet holds the keys tokens are verified with. An empty set skips signature verification.
+type jwtKeySet struct {
+	keys []*jwtKey
+}
+
+// jsonWebKey is a single key of a JWKS, as of RFC 7517 and RFC 7518
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// loadJWTKeys reads the keys of the JWKS file and of the PEM files of the configuration
+func loadJWTKeys(cfg *config.InitConfig) (*jwtKeySet, error) {
+	keySet := &jwtKeySet{}
+
+	if cfg.JWTJWKSFile != "" {
+		keys, err := readJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keySet.keys = append(keySet.keys, keys...)
+	}
+
+	for _, path := range cfg.JWTKeyFiles {
+		keys, err := readPEMKeys(path)
+		if err != nil {
+			return nil, err
+		}
+
+		keySet.keys = append(keySet.keys, keys...)
+	}
+
+	return keySet, nil
+}
+
+// readJWKS reads the signature keys of a JWKS file, skipping encryption keys
+func readJWKS(path string) ([]*jwtKey, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var jwks struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+
+	keys := make([]*jwtKey, 0, len(jwks.Keys))
+
+	for i, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != cJWKUseSignature {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of JWKS file %s: %w", i, path, err)
+		}
+
+		keys = append(keys, &jwtKey{id: jwk.Kid, alg: jwk.Alg, key: key})
+	}
+
+	return keys, nil
+}
+
+// publicKey decodes the key material of a JWK
+func (jwk *jsonWebKey) publicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, errN := decodeBigInt(jwk.N)
+		e, errE := decodeBigInt(jwk.E)
+
+		if err := errors.Join(errN, errE); err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent is too large")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		return jwk.ecdsaKey()
+
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", jwk.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid HMAC secret")
+		}
+
+		return secret, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+// ecdsaKey decodes the point of an EC JWK, checking that it lies on its curve
+func (jwk *jsonWebKey) ecdsaKey() (*ecdsa.PublicKey, error) {
+	curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+
+	curve, exists := curves[jwk.Crv]
+	if !exists {
+		return nil, fmt.Errorf("unsupported EC curve %q", jwk.Crv)
+	}
+
+	x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+
+	size := (curve.Params().BitSize + 7) / 8
+	if errors.Join(errX, errY) != nil || len(x) != size || len(y) != size {
+		return nil, errors.New("invalid EC coordinates")
+	}
+
+	// The uncompressed SEC 1 form is 0x04 followed by both coordinates
+	return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+}
+
+// readPEMKeys reads the public keys and certificates of a PEM file. They have no kid.
+func readPEMKeys(path string) ([]*jwtKey, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+	}
+
+	var keys []*jwtKey
+
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		var key any
+
+		switch block.Type {
+		case cPEMPublicKey:
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case cPEMCertificate:
+			var certificate *x509.Certificate
+			if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = certificate.PublicKey
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in JWT key file %s: %w", strings.ToLower(block.Type), path, err)
+		}
+
+		keys = append(keys, &jwtKey{key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWT key file %s holds no public key or certificate", path)
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid RSA key parameter")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// isEmpty reports whether no key is configured, in which case signatures are not verified
+func (ks *jwtKeySet) isEmpty() bool {
+	return ks == nil || len(ks.keys) == 0
+}
+
+// verify checks the signature of a token against the keys matching its kid and alg
+func (ks *jwtKeySet) verify(token *jwtToken) error {
+	alg, _ := token.header["alg"].(string)
+	kid, _ := token.header["kid"].(string)
+
+	candidates := 0
+
+	for _, key := range ks.keys {
+		if (key.id != "" && key.id != kid) || (key.alg != "" && key.alg != alg) {
+			continue
+		}
+
+		candidates++
+
+		if verifySignature(alg, key.key, []byte(token.signingInput), token.signature) {
+			return nil
+		}
+	}
+
+	if candidates == 0 {
+		return fmt.Errorf("no verification key for kid %q and alg %s", kid, alg)
+	}
+
+	return errors.New("signature does not match any verification key")
+}
+
+// verifySignature verifies a JWS signature. A key of the wrong type for alg never verifies, which rules out
+// algorithm confusion such as an RSA public key used as an HMAC secret.
+func verifySignature(alg string, key any, signingInput, signature []byte) bool {
+	hashes := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+	if alg == "EdDSA" {
+		publicKey, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(publicKey, signingInput, signature)
+	}
+
+	if len(alg) != len("HS256") {
+		return false
+	}
+
+	hash, exists := hashes[alg[2:]]
+	if !exists {
+		return false
+	}
+
+	digester := hash.New()
+	digester.Write(signingInput)
+	digest := digester.Sum(nil)
+
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signingInput)
+
+		return hmac.Equal(mac.Sum(nil), signature)
+
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(publicKey, hash, digest, signature) == nil
+
+	case "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+
+		return ok && rsa.VerifyPSS(publicKey, hash, digest, signature, options) == nil
+
+	case "ES":
+		return verifyECDSA(alg, key, digest, signature)
+
+	default:
+		return false
+	}
+}
+
+// verifyECDSA checks a JWS ECDSA signature, the fixed size concatenation of r and s, on the curve of alg
+func verifyECDSA(alg string, key any, digest, signature []byte) bool {
+	curves := map[string]elliptic.Curve{"ES256": elliptic.P256(), "ES384": elliptic.P384(), "ES512": elliptic.P521()}
+
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != curves[alg] {
+		return false
+	}
+
+	size := (publicKey.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*size {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+
+	return ecdsa.Verify(publicKey, digest, r, s)
+}
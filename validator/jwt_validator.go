@@ -0,0 +1,134 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"anomaly_detector/models"
+)
+
+const (
+	cBearerPrefix = "Bearer "
+
+	cClaimExpiration = "exp"
+	cClaimNotBefore  = "nbf"
+	cClaimIssuedAt   = "iat"
+
+	cMillisPerSecond = 1000
+)
+
+// validateJWT checks the token of an Auth-Token or JWT parameter against its JWT rules: its structure,
+// its algorithm, its time claims and required claims, and its signature when verification keys are configured.
+// A token that cannot be decoded is reported once, as nothing else can be checked.
+func (rv *requestValidator) validateJWT(
+	value any, matchedType models.ParamType, rules *models.JWTRules, field, name string, severity models.Severity,
+) []*models.FieldAnomaly {
+	var anomalies []*models.FieldAnomaly
+
+	anomaly := func(code models.AnomalyCode, claim string, expected, actual any, format string, args ...any) {
+		anomalies = append(anomalies, &models.FieldAnomaly{
+			Field:         field,
+			ParameterName: name,
+			Code:          code,
+			Severity:      severity,
+			Reason:        fmt.Sprintf(format, args...),
+			Constraint:    claim,
+			Expected:      expected,
+			Actual:        actual,
+		})
+	}
+
+	raw, _ := value.(string)
+	if matchedType == models.TypeAuthToken {
+		raw = strings.TrimPrefix(raw, cBearerPrefix)
+	}
+
+	token, err := parseJWT(raw)
+	if err != nil {
+		anomaly(models.CodeInvalidToken, "", nil, nil, "invalid JWT: %v", err)
+		return anomalies
+	}
+
+	alg, _ := token.header["alg"].(string)
+
+	algAllowed := slices.Contains(rules.Algorithms, alg)
+	if len(rules.Algorithms) == 0 {
+		algAllowed = models.IsJWTAlgorithm(alg)
+	}
+
+	if !algAllowed {
+		anomaly(models.CodeDisallowedAlgorithm, "", rules.Algorithms, alg, "JWT algorithm %q is not allowed", alg)
+	}
+
+	now := float64(rv.now().UnixMilli()) / cMillisPerSecond
+	leeway := float64(rules.LeewaySeconds)
+
+	timeClaims := []struct {
+		claim string
+		code  models.AnomalyCode
+		// violated reports whether the claim rejects the token at now
+		violated func(claimTime float64) bool
+		reason   string
+	}{
+		{
+			claim: cClaimExpiration, code: models.CodeTokenExpired, reason: "JWT expired at",
+			violated: func(exp float64) bool { return now >= exp+leeway },
+		},
+		{
+			claim: cClaimNotBefore, code: models.CodeTokenNotYetValid, reason: "JWT is not valid before",
+			violated: func(nbf float64) bool { return now+leeway < nbf },
+		},
+		{
+			claim: cClaimIssuedAt, code: models.CodeTokenNotYetValid, reason: "JWT is issued in the future at",
+			violated: func(iat float64) bool { return now+leeway < iat },
+		},
+	}
+
+	for _, timeClaim := range timeClaims {
+		claimValue, exists := token.claims[timeClaim.claim]
+		if !exists {
+			continue
+		}
+
+		claimTime, ok := numericDate(claimValue)
+		if !ok {
+			anomaly(models.CodeInvalidToken, timeClaim.claim, nil, claimValue,
+				"JWT claim %q is not a numeric date", timeClaim.claim)
+
+			continue
+		}
+
+		if timeClaim.violated(claimTime) {
+			at := time.UnixMilli(int64(claimTime * cMillisPerSecond)).UTC().Format(time.RFC3339)
+			anomaly(timeClaim.code, timeClaim.claim, nil, at, "%s %s", timeClaim.reason, at)
+		}
+	}
+
+	for _, claim := range rules.RequiredClaims {
+		if _, exists := token.claims[claim]; !exists {
+			anomaly(models.CodeMissingClaim, claim, nil, nil, "required JWT claim %q is missing", claim)
+		}
+	}
+
+	// A disallowed algorithm is already reported, and is never trusted to verify a signature
+	if algAllowed && !rv.keys.isEmpty() {
+		if err := rv.keys.verify(token); err != nil {
+			anomaly(models.CodeInvalidSignature, "", nil, nil, "invalid JWT signature: %v", err)
+		}
+	}
+
+	return anomalies
+}
+
+// numericDate reads a NumericDate claim, seconds since the epoch which may have a fraction
+func numericDate(value any) (float64, bool) {
+	seconds, ok := value.(float64)
+	if !ok || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, false
+	}
+
+	return seconds, true
+}
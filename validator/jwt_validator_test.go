@@ -0,0 +1,494 @@
+package validator
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tJWTNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// signJWT encodes a compact JWT, signing its header and claims with sign
+func signJWT(t *testing.T, header, claims map[string]any, sign func(signingInput []byte) []byte) string {
+	t.Helper()
+
+	encode := func(object map[string]any) string {
+		data, err := json.Marshal(object)
+		require.NoError(t, err)
+
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signingInput := encode(header) + "." + encode(claims)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signingInput)))
+}
+
+func hmacSigner(secret []byte) func([]byte) []byte {
+	return func(signingInput []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+
+		return mac.Sum(nil)
+	}
+}
+
+// writeTestFile writes a key file to a temporary directory and returns its path
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]any) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+
+	return writeTestFile(t, "jwks.json", data)
+}
+
+// newJWTValidator returns a validator with the keys of cfg whose clock is stopped at tJWTNow
+func newJWTValidator(t *testing.T, cfg *config.InitConfig) IRequestValidator {
+	t.Helper()
+
+	validator, err := NewConfiguredRequestValidator(cfg, nil)
+	require.NoError(t, err)
+
+	validator.(*requestValidator).now = func() time.Time { return tJWTNow }
+
+	return validator
+}
+
+// validateToken validates an Authorization header holding token against rules, returning the anomaly codes
+func validateToken(
+	t *testing.T, validator IRequestValidator, paramType models.ParamType, rules *models.JWTRules, token string,
+) []models.AnomalyCode {
+	t.Helper()
+
+	tModel := &models.APIModel{
+		Path:   tTestPath,
+		Method: http.MethodGet,
+		Headers: []*models.Parameter{
+			{Name: "Authorization", Types: []models.ParamType{paramType}, Required: true, JWT: rules},
+		},
+	}
+
+	tRequest := &models.Request{
+		Path: tTestPath, Method: http.MethodGet, Headers: []*models.RequestParam{{Name: "Authorization", Value: token}},
+	}
+
+	result := validator.Validate(context.Background(), tRequest, tModel)
+
+	codes := []models.AnomalyCode{}
+	for _, anomaly := range result.Anomalies {
+		codes = append(codes, anomaly.Code)
+	}
+
+	return codes
+}
+
+func TestValidateJWTClaims(t *testing.T) {
+	tSecret := []byte("claims-secret")
+	tHeader := map[string]any{"alg": "HS256", "typ": "JWT"}
+	now := tJWTNow.Unix()
+
+	tValidator := newJWTValidator(t, &config.InitConfig{})
+
+	testCases := []struct {
+		name          string
+		header        map[string]any
+		claims        map[string]any
+		rules         *models.JWTRules
+		expectedCodes []models.AnomalyCode
+	}{
+		{
+			name:          "valid token",
+			claims:        map[string]any{"sub": "alice", "iat": now - 60, "nbf": now - 60, "exp": now + 60},
+			rules:         &models.JWTRules{RequiredClaims: []string{"sub", "exp"}},
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:          "expired token",
+			claims:        map[string]any{"exp": now - 10},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{models.CodeTokenExpired},
+		},
+		{
+			name:          "expired at the current second",
+			claims:        map[string]any{"exp": now},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{models.CodeTokenExpired},
+		},
+		{
+			name:          "expired within the leeway",
+			claims:        map[string]any{"exp": now - 10},
+			rules:         &models.JWTRules{LeewaySeconds: 30},
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:          "not valid yet",
+			claims:        map[string]any{"nbf": now + 120},
+			rules:         &models.JWTRules{LeewaySeconds: 30},
+			expectedCodes: []models.AnomalyCode{models.CodeTokenNotYetValid},
+		},
+		{
+			name:          "issued in the future",
+			claims:        map[string]any{"iat": now + 120},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{models.CodeTokenNotYetValid},
+		},
+		{
+			name:          "fractional numeric dates",
+			claims:        map[string]any{"iat": float64(now) - 0.5, "exp": float64(now) + 0.5},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:          "non numeric exp",
+			claims:        map[string]any{"exp": "tomorrow"},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{models.CodeInvalidToken},
+		},
+		{
+			name:          "missing required claims",
+			claims:        map[string]any{"sub": "alice"},
+			rules:         &models.JWTRules{RequiredClaims: []string{"sub", "aud", "scope"}},
+			expectedCodes: []models.AnomalyCode{models.CodeMissingClaim, models.CodeMissingClaim},
+		},
+		{
+			name:          "algorithm outside the allowed list",
+			claims:        map[string]any{},
+			rules:         &models.JWTRules{Algorithms: []string{"RS256"}},
+			expectedCodes: []models.AnomalyCode{models.CodeDisallowedAlgorithm},
+		},
+		{
+			name:          "unsecured token",
+			header:        map[string]any{"alg": "none"},
+			claims:        map[string]any{},
+			rules:         &models.JWTRules{},
+			expectedCodes: []models.AnomalyCode{models.CodeDisallowedAlgorithm},
+		},
+		{
+			name:   "every failure is reported",
+			header: map[string]any{"alg": "HS512"},
+			claims: map[string]any{"exp": now - 10, "nbf": now + 10},
+			rules:  &models.JWTRules{Algorithms: []string{"HS256"}, RequiredClaims: []string{"sub"}},
+			expectedCodes: []models.AnomalyCode{
+				models.CodeDisallowedAlgorithm, models.CodeTokenExpired, models.CodeTokenNotYetValid,
+				models.CodeMissingClaim,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := tHeader
+			if tc.header != nil {
+				header = tc.header
+			}
+
+			token := signJWT(t, header, tc.claims, hmacSigner(tSecret))
+
+			assert.Equal(t, tc.expectedCodes, validateToken(t, tValidator, models.TypeAuthToken, tc.rules, "Bearer "+token))
+			assert.Equal(t, tc.expectedCodes, validateToken(t, tValidator, models.TypeJWT, tc.rules, token))
+		})
+	}
+
+	t.Run("malformed tokens are invalid", func(t *testing.T) {
+		codes := validateToken(t, tValidator, models.TypeAuthToken, &models.JWTRules{}, "Bearer opaque-token")
+		assert.Equal(t, []models.AnomalyCode{models.CodeInvalidToken}, codes)
+	})
+
+	t.Run("tokens are only checked with JWT rules", func(t *testing.T) {
+		token := signJWT(t, tHeader, map[string]any{"exp": now - 10}, hmacSigner(tSecret))
+		assert.Empty(t, validateToken(t, tValidator, models.TypeAuthToken, nil, "Bearer "+token))
+		assert.Empty(t, validateToken(t, tValidator, models.TypeAuthToken, nil, "Bearer opaque-token"))
+	})
+
+	t.Run("anomalies name the claim and carry risk", func(t *testing.T) {
+		token := signJWT(t, tHeader, map[string]any{"exp": now - 10}, hmacSigner(tSecret))
+
+		tModel := &models.APIModel{
+			Path: tTestPath, Method: http.MethodGet,
+			Headers: []*models.Parameter{
+				{Name: "Authorization", Types: []models.ParamType{models.TypeAuthToken}, JWT: &models.JWTRules{}},
+			},
+		}
+
+		tRequest := &models.Request{
+			Path: tTestPath, Method: http.MethodGet,
+			Headers: []*models.RequestParam{{Name: "Authorization", Value: "Bearer " + token}},
+		}
+
+		result := tValidator.Validate(context.Background(), tRequest, tModel)
+
+		require.Len(t, result.Anomalies, 1)
+		assert.Equal(t, cClaimExpiration, result.Anomalies[0].Constraint)
+		assert.Equal(t, time.Unix(now-10, 0).UTC().Format(time.RFC3339), result.Anomalies[0].Actual)
+		assert.Equal(t, defaultCodeWeights[models.CodeTokenExpired], result.Anomalies[0].Risk)
+		assert.False(t, result.Valid)
+	})
+}
+
+func TestValidateJWTSignature(t *testing.T) {
+	tSecret := []byte("signature-secret")
+	tClaims := map[string]any{"sub": "alice"}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	require.NoError(t, err)
+
+	jwksPath := writeJWKS(t,
+		map[string]any{"kty": "oct", "kid": "hmac", "k": base64.RawURLEncoding.EncodeToString(tSecret)},
+		map[string]any{
+			"kty": "EC", "kid": "ec", "crv": "P-256", "alg": "ES256",
+			"x": base64.RawURLEncoding.EncodeToString(ecPoint[1:33]),
+			"y": base64.RawURLEncoding.EncodeToString(ecPoint[33:]),
+		},
+		map[string]any{
+			"kty": "RSA", "kid": "rsa",
+			"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		map[string]any{"kty": "oct", "kid": "encryption", "use": "enc", "k": "ZW5j"},
+	)
+
+	edDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	require.NoError(t, err)
+
+	pemPath := writeTestFile(t, "ed25519.pem", pem.EncodeToMemory(&pem.Block{Type: cPEMPublicKey, Bytes: edDER}))
+
+	tValidator := newJWTValidator(t, &config.InitConfig{JWTJWKSFile: jwksPath, JWTKeyFiles: []string{pemPath}})
+
+	rsaSigner := func(sign func(digest []byte) ([]byte, error)) func([]byte) []byte {
+		return func(signingInput []byte) []byte {
+			digest := sha256.Sum256(signingInput)
+
+			signature, err := sign(digest[:])
+			require.NoError(t, err)
+
+			return signature
+		}
+	}
+
+	ecSigner := func(signingInput []byte) []byte {
+		digest := sha256.Sum256(signingInput)
+
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+		return signature
+	}
+
+	testCases := []struct {
+		name          string
+		header        map[string]any
+		sign          func([]byte) []byte
+		expectedCodes []models.AnomalyCode
+	}{
+		{
+			name:          "HMAC from the JWKS",
+			header:        map[string]any{"alg": "HS256", "kid": "hmac"},
+			sign:          hmacSigner(tSecret),
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:          "HMAC with the wrong secret",
+			header:        map[string]any{"alg": "HS256", "kid": "hmac"},
+			sign:          hmacSigner([]byte("guessed")),
+			expectedCodes: []models.AnomalyCode{models.CodeInvalidSignature},
+		},
+		{
+			name:          "ECDSA from the JWKS",
+			header:        map[string]any{"alg": "ES256", "kid": "ec"},
+			sign:          ecSigner,
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:   "RSA PKCS #1 from the JWKS",
+			header: map[string]any{"alg": "RS256", "kid": "rsa"},
+			sign: rsaSigner(func(digest []byte) ([]byte, error) {
+				return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+			}),
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:   "RSA PSS from the JWKS",
+			header: map[string]any{"alg": "PS256", "kid": "rsa"},
+			sign: rsaSigner(func(digest []byte) ([]byte, error) {
+				options := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+				return rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest, options)
+			}),
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:   "EdDSA from a PEM file without kid",
+			header: map[string]any{"alg": "EdDSA"},
+			sign: func(signingInput []byte) []byte {
+				return ed25519.Sign(edPrivate, signingInput)
+			},
+			expectedCodes: []models.AnomalyCode{},
+		},
+		{
+			name:          "unknown kid",
+			header:        map[string]any{"alg": "HS256", "kid": "rotated"},
+			sign:          hmacSigner(tSecret),
+			expectedCodes: []models.AnomalyCode{models.CodeInvalidSignature},
+		},
+		{
+			name:          "key restricted to another algorithm",
+			header:        map[string]any{"alg": "ES384", "kid": "ec"},
+			sign:          ecSigner,
+			expectedCodes: []models.AnomalyCode{models.CodeInvalidSignature},
+		},
+		{
+			name:   "RSA public key used as an HMAC secret",
+			header: map[string]any{"alg": "HS256", "kid": "rsa"},
+			sign: hmacSigner(pem.EncodeToMemory(&pem.Block{
+				Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey),
+			})),
+			expectedCodes: []models.AnomalyCode{models.CodeInvalidSignature},
+		},
+		{
+			name:          "disallowed algorithm is not verified",
+			header:        map[string]any{"alg": "none"},
+			sign:          func([]byte) []byte { return nil },
+			expectedCodes: []models.AnomalyCode{models.CodeDisallowedAlgorithm},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := signJWT(t, tc.header, tClaims, tc.sign)
+			assert.Equal(t, tc.expectedCodes, validateToken(t, tValidator, models.TypeJWT, &models.JWTRules{}, token))
+		})
+	}
+
+	t.Run("signatures are not verified without keys", func(t *testing.T) {
+		token := signJWT(t, map[string]any{"alg": "HS256"}, tClaims, hmacSigner([]byte("guessed")))
+		codes := validateToken(t, newJWTValidator(t, &config.InitConfig{}), models.TypeJWT, &models.JWTRules{}, token)
+		assert.Empty(t, codes)
+	})
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         func(t *testing.T) *config.InitConfig
+		expectedLen int
+		expectError bool
+	}{
+		{
+			name:        "no keys configured",
+			cfg:         func(*testing.T) *config.InitConfig { return &config.InitConfig{} },
+			expectedLen: 0,
+		},
+		{
+			name: "missing JWKS file",
+			cfg: func(t *testing.T) *config.InitConfig {
+				return &config.InitConfig{JWTJWKSFile: filepath.Join(t.TempDir(), "missing.json")}
+			},
+			expectError: true,
+		},
+		{
+			name: "JWKS file that is not JSON",
+			cfg: func(t *testing.T) *config.InitConfig {
+				return &config.InitConfig{JWTJWKSFile: writeTestFile(t, "jwks.json", []byte("keys"))}
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported key type",
+			cfg: func(t *testing.T) *config.InitConfig {
+				return &config.InitConfig{JWTJWKSFile: writeJWKS(t, map[string]any{"kty": "DSA"})}
+			},
+			expectError: true,
+		},
+		{
+			name: "EC point outside its curve",
+			cfg: func(t *testing.T) *config.InitConfig {
+				coordinate := base64.RawURLEncoding.EncodeToString(make([]byte, 32))
+				return &config.InitConfig{JWTJWKSFile: writeJWKS(t, map[string]any{
+					"kty": "EC", "crv": "P-256", "x": coordinate, "y": coordinate,
+				})}
+			},
+			expectError: true,
+		},
+		{
+			name: "encryption keys are skipped",
+			cfg: func(t *testing.T) *config.InitConfig {
+				return &config.InitConfig{JWTJWKSFile: writeJWKS(t,
+					map[string]any{"kty": "oct", "k": "c2VjcmV0"},
+					map[string]any{"kty": "RSA", "use": "enc"},
+				)}
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "PEM file without a public key",
+			cfg: func(t *testing.T) *config.InitConfig {
+				data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("secret")})
+				return &config.InitConfig{JWTKeyFiles: []string{writeTestFile(t, "key.pem", data)}}
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := loadJWTKeys(tc.cfg(t))
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, keys.keys, tc.expectedLen)
+		})
+	}
+
+	t.Run("a bad key file fails the configured validator", func(t *testing.T) {
+		_, err := NewConfiguredRequestValidator(&config.InitConfig{JWTKeyFiles: []string{"missing.pem"}}, nil)
+		assert.ErrorContains(t, err, "invalid JWT key configuration")
+		assert.NotContains(t, err.Error(), "risk")
+	})
+}
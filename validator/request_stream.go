@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// cMaxBatchLineSize bounds the memory used by a single request, an NDJSON line or an element of a JSON array
+const cMaxBatchLineSize = 10 * 1024 * 1024
+
+var errRequestTooLarge = fmt.Errorf("request exceeds the maximum size of %d bytes", cMaxBatchLineSize)
+
+// ScanRequests emits the raw requests of a capture one at a time, without decoding the whole capture.
+// The format is sniffed from the first non-blank byte: a JSON array or newline-delimited JSON.
+func ScanRequests(reader io.Reader, emit func(item []byte)) error {
+	buffered := bufio.NewReader(reader)
+
+	for {
+		next, err := buffered.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read request stream: %w", err)
+		}
+
+		if next[0] == '[' {
+			decoder := newArrayDecoder(buffered)
+			if _, err := decoder.Token(); err != nil {
+				return fmt.Errorf("invalid JSON provided: %w", err)
+			}
+
+			return readJSONArray(decoder, emit)
+		}
+
+		if !isBlank(next[0]) {
+			return readNDJSON(buffered, emit)
+		}
+
+		if _, err := buffered.ReadByte(); err != nil {
+			return fmt.Errorf("failed to read request stream: %w", err)
+		}
+	}
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// arrayDecoder decodes a JSON array one element at a time, reading at most cMaxBatchLineSize bytes per element
+type arrayDecoder struct {
+	*json.Decoder
+	input *itemLimitReader
+}
+
+func newArrayDecoder(reader io.Reader) *arrayDecoder {
+	input := &itemLimitReader{reader: reader, limit: cMaxBatchLineSize}
+
+	return &arrayDecoder{Decoder: json.NewDecoder(input), input: input}
+}
+
+// nextItem grants the element starting at the current offset of the decoder its own cMaxBatchLineSize bytes
+func (d *arrayDecoder) nextItem() {
+	d.input.limit = d.InputOffset() + cMaxBatchLineSize
+}
+
+// itemLimitReader fails with errRequestTooLarge once the input is read past limit. The decoder reads ahead,
+// so reads are shortened to the limit rather than failed: the error only surfaces when the decoder cannot
+// complete the current element without reading further.
+type itemLimitReader struct {
+	reader io.Reader
+	read   int64
+	limit  int64
+}
+
+func (r *itemLimitReader) Read(p []byte) (int, error) {
+	remaining := r.limit - r.read
+	if remaining <= 0 {
+		return 0, errRequestTooLarge
+	}
+
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	return n, err
+}
+
+// readJSONArray emits the raw elements of a JSON array whose opening bracket was already consumed.
+// A syntax error cannot be recovered from inside an array, so it ends the batch, as does an oversized element.
+func readJSONArray(decoder *arrayDecoder, emit func(item []byte)) error {
+	for {
+		decoder.nextItem()
+
+		if !decoder.More() {
+			break
+		}
+
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return arrayError(err)
+		}
+
+		emit(item)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return arrayError(err)
+	}
+
+	return nil
+}
+
+func arrayError(err error) error {
+	if errors.Is(err, errRequestTooLarge) {
+		return errRequestTooLarge
+	}
+
+	return fmt.Errorf("invalid JSON provided: %w", err)
+}
+
+// readNDJSON emits every non-blank line of a newline-delimited JSON stream
+func readNDJSON(body io.Reader, emit func(item []byte)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), cMaxBatchLineSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		emit(line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return errRequestTooLarge
+		}
+
+		return fmt.Errorf("failed to read request stream: %w", err)
+	}
+
+	return nil
+}
@@ -2,100 +2,513 @@ package validator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/textproto"
+	"slices"
 	"sync"
+	"time"
 
+	"anomaly_detector/config"
 	"anomaly_detector/models"
+	"anomaly_detector/pathtemplate"
 )
 
 const (
+	cFieldPathParams  = "path_params"
 	cFieldQueryParams = "query_params"
 	cFieldHeaders     = "headers"
+	cFieldCookies     = "cookies"
 	cFieldBody        = "body"
 )
 
 type IRequestValidator interface {
-	Validate(ctx context.Context, req *models.Request, model *models.APIModel) []*models.FieldAnomaly
+	Validate(ctx context.Context, req *models.Request, model *models.APIModel) *models.ValidationResult
 }
 
-type requestValidator struct{}
+// ITypeProvider returns the custom types defined for the tenant of ctx. It is implemented by the model store.
+type ITypeProvider interface {
+	Types(ctx context.Context) ([]*models.TypeDefinition, error)
+}
+
+type requestValidator struct {
+	// patterns caches compiled constraint and type patterns by their source
+	patterns sync.Map
+	risk     *riskScorer
+	// types provides the custom types of each tenant, nil when only built-in and Go types are known
+	types ITypeProvider
+	// keys verify the signatures of JWT parameters, which are not verified when there are none
+	keys *jwtKeySet
+	now  func() time.Time
+}
 
+// NewRequestValidator returns a validator with the default risk weights, valid only without risky anomalies.
+// It knows the built-in types and the types registered in Go.
 func NewRequestValidator() IRequestValidator {
-	return &requestValidator{}
+	return &requestValidator{risk: defaultRiskScorer(), now: time.Now}
+}
+
+// NewConfiguredRequestValidator returns a validator scoring risk with the weights and threshold of cfg
+// and verifying JWT signatures with its keys, which also knows the custom types defined for each tenant
+func NewConfiguredRequestValidator(cfg *config.InitConfig, types ITypeProvider) (IRequestValidator, error) {
+	risk, err := newRiskScorer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid risk configuration: %w", err)
+	}
+
+	keys, err := loadJWTKeys(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT key configuration: %w", err)
+	}
+
+	return &requestValidator{risk: risk, types: types, keys: keys, now: time.Now}, nil
+}
+
+// sectionValidation holds the state of validating one section (path params, query params, headers, cookies or body)
+type sectionValidation struct {
+	rv    *requestValidator
+	field string
+	// coerce parses string values as the types they are matched against, see coerceValues
+	coerce bool
+	// definedTypes are the custom types of the tenant, shared read-only by every section
+	definedTypes map[models.ParamType]*models.TypeDefinition
+	// detectUnexpected enables reporting of parameters that are not declared in the model
+	detectUnexpected bool
+	// isAllowedUndeclared exempts well-known parameters from unexpected parameter detection
+	isAllowedUndeclared func(name string) bool
+	// canonicalName maps the names that refer to the same parameter onto one, nil when names are exact
+	canonicalName func(name string) string
+
+	anomalies  []*models.FieldAnomaly
+	unexpected []*models.FieldAnomaly
 }
 
 func (rv *requestValidator) Validate(
-	ctx context.Context, req *models.Request, model *models.APIModel) []*models.FieldAnomaly {
+	ctx context.Context, req *models.Request, model *models.APIModel) *models.ValidationResult {
 	slog.DebugContext(ctx, "Starting request validation",
 		"path", req.Path,
 		"method", req.Method,
 	)
 
-	var (
-		anomalies []*models.FieldAnomaly
-		wg        sync.WaitGroup
-	)
+	policy := model.UnexpectedParams
+	if policy == "" {
+		policy = models.UnexpectedParamsReport
+	}
 
-	wg.Go(func() {
-		anomalies = append(anomalies, rv.validateParameters(req.QueryParams, model.QueryParams, cFieldQueryParams)...)
-	})
-	wg.Go(func() {
-		anomalies = append(anomalies, rv.validateParameters(req.Headers, model.Headers, cFieldHeaders)...)
-	})
-	wg.Go(func() {
-		anomalies = append(anomalies, rv.validateParameters(req.Body, model.Body, cFieldBody)...)
-	})
+	detectUnexpected := policy != models.UnexpectedParamsAllow
+	definedTypes := rv.definedTypes(ctx)
+
+	// Path params are defined by the template itself, so they can never be unexpected, and as segments are
+	// always strings they are always coerced. The body is JSON, and is never coerced.
+	sections := []*sectionValidation{
+		rv.newSection(cFieldPathParams, true, false, nil, nil),
+		rv.newSection(cFieldQueryParams, coerces(model, cFieldQueryParams), detectUnexpected, nil, nil),
+		rv.newSection(cFieldHeaders, coerces(model, cFieldHeaders), detectUnexpected,
+			IsStandardHeader, textproto.CanonicalMIMEHeaderKey),
+		rv.newSection(cFieldCookies, coerces(model, cFieldCookies), detectUnexpected, nil, nil),
+		rv.newSection(cFieldBody, false, detectUnexpected, nil, nil),
+	}
+
+	requestParams := [][]*models.RequestParam{
+		pathRequestParams(req.Path, model.Path), req.QueryParams, req.Headers, req.Cookies, req.Body,
+	}
+
+	modelParams := [][]*models.Parameter{model.PathParams, model.QueryParams, model.Headers, model.Cookies, model.Body}
+
+	// Each section writes to its own state, keeping the result order stable and the goroutines race-free
+	var wg sync.WaitGroup
+
+	for i, section := range sections {
+		section.definedTypes = definedTypes
+
+		wg.Go(func() {
+			section.validateParameters(requestParams[i], modelParams[i])
+		})
+	}
 
 	wg.Wait()
 
-	return anomalies
+	result := &models.ValidationResult{}
+
+	// Undeclared parameters have no schema to configure their severity, it follows from the policy instead
+	unexpectedSeverity := models.SeverityWarning
+	if policy == models.UnexpectedParamsReject {
+		unexpectedSeverity = models.SeverityCritical
+	}
+
+	for _, section := range sections {
+		result.Anomalies = append(result.Anomalies, section.anomalies...)
+
+		for _, anomaly := range section.unexpected {
+			anomaly.Severity = unexpectedSeverity
+		}
+
+		if policy == models.UnexpectedParamsReject {
+			for _, anomaly := range section.unexpected {
+				anomaly.Risk = rv.risk.risk(anomaly.Code, anomaly.Field, 1)
+			}
+
+			result.Anomalies = append(result.Anomalies, section.unexpected...)
+		} else {
+			result.Warnings = append(result.Warnings, section.unexpected...)
+		}
+	}
+
+	rv.risk.score(result)
+	result.HighestSeverity = highestSeverity(result.Anomalies, result.Warnings)
+
+	return result
 }
 
-func (rv *requestValidator) validateParameters(
-	requestParams []*models.RequestParam,
-	modelParams []*models.Parameter,
+// coerces reports whether a model asks for the string values of a section to be coerced
+func coerces(model *models.APIModel, field string) bool {
+	return slices.Contains(model.Coerce, field)
+}
+
+func (rv *requestValidator) newSection(
 	field string,
-) []*models.FieldAnomaly {
-	var anomalies []*models.FieldAnomaly
+	coerce bool,
+	detectUnexpected bool,
+	isAllowedUndeclared func(name string) bool,
+	canonicalName func(name string) string,
+) *sectionValidation {
+	return &sectionValidation{
+		rv:                  rv,
+		field:               field,
+		coerce:              coerce,
+		detectUnexpected:    detectUnexpected,
+		isAllowedUndeclared: isAllowedUndeclared,
+		canonicalName:       canonicalName,
+	}
+}
+
+// definedTypes returns the custom types of the tenant of ctx by name. Without them, values never match
+// the custom types referenced by the model.
+func (rv *requestValidator) definedTypes(ctx context.Context) map[models.ParamType]*models.TypeDefinition {
+	if rv.types == nil {
+		return nil
+	}
+
+	definitions, err := rv.types.Types(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load custom types", "error", err)
+		return nil
+	}
+
+	definedTypes := make(map[models.ParamType]*models.TypeDefinition, len(definitions))
+	for _, definition := range definitions {
+		definedTypes[definition.Name] = definition
+	}
+
+	return definedTypes
+}
+
+// pathRequestParams extracts the values of the path template variables from the concrete request path
+func pathRequestParams(requestPath, template string) []*models.RequestParam {
+	values, ok := pathtemplate.Extract(template, requestPath)
+	if !ok {
+		return nil
+	}
+
+	params := make([]*models.RequestParam, 0, len(values))
+	for _, name := range pathtemplate.Params(template) {
+		params = append(params, &models.RequestParam{Name: name, Value: values[name]})
+	}
 
-	// Build map of request parameters for quick lookup
-	requestMap := make(map[string]any, len(requestParams))
+	return params
+}
+
+func (sv *sectionValidation) validateParameters(
+	requestParams []*models.RequestParam,
+	modelParams []*models.Parameter,
+) {
+	// Build map of request parameters for quick lookup, keeping every value of a repeated name in request order
+	requestMap := make(map[string][]any, len(requestParams))
 	for _, rp := range requestParams {
-		requestMap[rp.Name] = rp.Value
+		name := sv.canonical(rp.Name)
+		requestMap[name] = append(requestMap[name], rp.Value)
 	}
 
 	for _, modelParam := range modelParams {
-		value, exists := requestMap[modelParam.Name]
-		if !exists {
-			if modelParam.Required {
-				anomalies = append(anomalies, &models.FieldAnomaly{
-					Field:         field,
-					ParameterName: modelParam.Name,
-					Reason:        fmt.Sprintf("required parameter %q is missing", modelParam.Name),
-				})
-			}
+		values := requestMap[sv.canonical(modelParam.Name)]
+		if sv.coerce {
+			values = sv.coerceValues(values, modelParam)
+		}
+
+		sv.validateField(values, modelParam, modelParam.Name, models.SeverityCritical, 1)
+	}
 
-			continue
+	if sv.detectUnexpected {
+		declared := make(map[string]struct{}, len(modelParams))
+		for _, modelParam := range modelParams {
+			declared[sv.canonical(modelParam.Name)] = struct{}{}
 		}
 
-		typeMatch := false
+		// Iterate over the request list rather than the map to report in request order, once per name
+		reported := make(map[string]struct{}, len(requestParams))
 
-		for _, typeName := range modelParam.Types {
-			if validateType(value, typeName) {
-				typeMatch = true
-				break
+		for _, rp := range requestParams {
+			name := sv.canonical(rp.Name)
+			if _, exists := reported[name]; exists {
+				continue
 			}
+
+			reported[name] = struct{}{}
+
+			if sv.isAllowedUndeclared != nil && sv.isAllowedUndeclared(rp.Name) {
+				continue
+			}
+
+			sv.checkDeclared(declared, name, rp.Name)
 		}
+	}
+}
 
-		if !typeMatch {
-			anomalies = append(anomalies, &models.FieldAnomaly{
-				Field:         field,
-				ParameterName: modelParam.Name,
-				Reason:        fmt.Sprintf("type mismatch: expected one of %v types, but got the type %T", modelParam.Types, value),
+// canonical returns the name a request or model parameter is matched by
+func (sv *sectionValidation) canonical(name string) string {
+	if sv.canonicalName == nil {
+		return name
+	}
+
+	return sv.canonicalName(name)
+}
+
+// validateFields checks the values of an object (or of a whole section) against their parameter schemas.
+// prefix is the name of the enclosing object, used to report nested names such as address.zip,
+// and severity and riskWeight those of the enclosing object, inherited by parameters that do not set their own.
+func (sv *sectionValidation) validateFields(
+	values map[string]any,
+	modelParams []*models.Parameter,
+	prefix string,
+	severity models.Severity,
+	riskWeight float64,
+) {
+	for _, modelParam := range modelParams {
+		var occurrences []any
+		if value, exists := values[modelParam.Name]; exists {
+			occurrences = []any{value}
+		}
+
+		sv.validateField(occurrences, modelParam, joinName(prefix, modelParam.Name), severity, riskWeight)
+	}
+}
+
+// validateField checks the values a parameter was sent with: none, one, or several when its name is repeated.
+// Every value of a repeated name is validated, under its index such as tag[1], whether repetition is allowed or not.
+func (sv *sectionValidation) validateField(
+	values []any,
+	modelParam *models.Parameter,
+	name string,
+	severity models.Severity,
+	riskWeight float64,
+) {
+	if len(values) == 0 {
+		if modelParam.Required {
+			sv.anomalies = append(sv.anomalies, &models.FieldAnomaly{
+				Field:         sv.field,
+				ParameterName: name,
+				Code:          models.CodeMissingRequired,
+				Severity:      paramSeverity(modelParam, severity),
+				Reason:        fmt.Sprintf("required parameter %q is missing", name),
+				Expected:      modelParam.Types,
+				Risk:          sv.risk(models.CodeMissingRequired, paramRiskWeight(modelParam, riskWeight)),
 			})
 		}
+
+		return
+	}
+
+	if len(values) == 1 {
+		sv.validateValue(values[0], modelParam, name, severity, riskWeight)
+		return
+	}
+
+	if !modelParam.Repeated {
+		sv.anomalies = append(sv.anomalies, &models.FieldAnomaly{
+			Field:         sv.field,
+			ParameterName: name,
+			Code:          models.CodeDuplicateParam,
+			Severity:      paramSeverity(modelParam, severity),
+			Reason:        fmt.Sprintf("parameter %q is sent %d times but may only be sent once", name, len(values)),
+			Expected:      1,
+			Actual:        len(values),
+			Risk:          sv.risk(models.CodeDuplicateParam, paramRiskWeight(modelParam, riskWeight)),
+		})
+	}
+
+	for i, value := range values {
+		sv.validateValue(value, modelParam, fmt.Sprintf("%s[%d]", name, i), severity, riskWeight)
+	}
+}
+
+// validateValue checks a single value against its schema and recurses into Object properties and List items.
+// Values are matched with the strict validateType, the strings of coerced sections having been converted before.
+func (sv *sectionValidation) validateValue(
+	value any,
+	modelParam *models.Parameter,
+	name string,
+	severity models.Severity,
+	riskWeight float64,
+) {
+	if value == nil && modelParam.Nullable {
+		return
+	}
+
+	severity = paramSeverity(modelParam, severity)
+	riskWeight = paramRiskWeight(modelParam, riskWeight)
+
+	var (
+		matchedType models.ParamType
+		typeMatch   bool
+	)
+
+	for _, typeName := range modelParam.Types {
+		if sv.matchesParam(value, typeName, modelParam) {
+			matchedType, typeMatch = typeName, true
+			break
+		}
+	}
+
+	if !typeMatch {
+		sv.anomalies = append(sv.anomalies, &models.FieldAnomaly{
+			Field:         sv.field,
+			ParameterName: name,
+			Code:          models.CodeTypeMismatch,
+			Severity:      severity,
+			Reason:        fmt.Sprintf("type mismatch: expected one of %v types, but got the type %T", modelParam.Types, value),
+			Expected:      modelParam.Types,
+			Actual:        jsonTypeName(value),
+			Risk:          sv.risk(models.CodeTypeMismatch, riskWeight),
+		})
+
+		return
+	}
+
+	anomalies := sv.rv.validateConstraints(value, matchedType, modelParam, sv.field, name, severity)
+
+	if modelParam.JWT != nil && (matchedType == models.TypeAuthToken || matchedType == models.TypeJWT) {
+		anomalies = append(anomalies, sv.rv.validateJWT(value, matchedType, modelParam.JWT, sv.field, name, severity)...)
+	}
+
+	for _, anomaly := range anomalies {
+		anomaly.Risk = sv.risk(anomaly.Code, riskWeight)
+		sv.anomalies = append(sv.anomalies, anomaly)
+	}
+
+	switch matchedType {
+	case models.TypeObject:
+		object, ok := value.(map[string]any)
+		if !ok || len(modelParam.Properties) == 0 {
+			break
+		}
+
+		sv.validateFields(object, modelParam.Properties, name, severity, riskWeight)
+
+		// Only objects with declared properties have a closed set of fields
+		if sv.detectUnexpected {
+			declared := declaredNames(modelParam.Properties)
+			for _, key := range sortedKeys(object) {
+				sv.checkDeclared(declared, key, joinName(name, key))
+			}
+		}
+
+	case models.TypeList:
+		if modelParam.Items == nil {
+			break
+		}
+
+		for i, item := range listItems(value) {
+			sv.validateValue(item, modelParam.Items, fmt.Sprintf("%s[%d]", name, i), severity, riskWeight)
+		}
+	}
+}
+
+// checkDeclared records an unexpected parameter anomaly when key is not one of the declared names
+func (sv *sectionValidation) checkDeclared(declared map[string]struct{}, key, name string) {
+	if _, exists := declared[key]; exists {
+		return
+	}
+
+	sv.unexpected = append(sv.unexpected, &models.FieldAnomaly{
+		Field:         sv.field,
+		ParameterName: name,
+		Code:          models.CodeUnexpectedParam,
+		Reason:        fmt.Sprintf("unexpected parameter %q is not declared in the model", name),
+	})
+}
+
+func declaredNames(modelParams []*models.Parameter) map[string]struct{} {
+	names := make(map[string]struct{}, len(modelParams))
+	for _, modelParam := range modelParams {
+		names[modelParam.Name] = struct{}{}
+	}
+
+	return names
+}
+
+// paramSeverity returns the severity set on a parameter, or the one it inherits from its enclosing object
+func paramSeverity(modelParam *models.Parameter, inherited models.Severity) models.Severity {
+	if modelParam.Severity != "" {
+		return modelParam.Severity
+	}
+
+	return inherited
+}
+
+// paramRiskWeight returns the risk weight set on a parameter, or the one it inherits from its enclosing object
+func paramRiskWeight(modelParam *models.Parameter, inherited float64) float64 {
+	if modelParam.RiskWeight != nil {
+		return *modelParam.RiskWeight
+	}
+
+	return inherited
+}
+
+// risk returns the risk of an anomaly of this section
+func (sv *sectionValidation) risk(code models.AnomalyCode, paramWeight float64) float64 {
+	return sv.rv.risk.risk(code, sv.field, paramWeight)
+}
+
+// highestSeverity returns the highest severity of the given anomalies, or an empty severity if there are none
+func highestSeverity(lists ...[]*models.FieldAnomaly) models.Severity {
+	var highest models.Severity
+
+	for _, anomalies := range lists {
+		for _, anomaly := range anomalies {
+			if highest == "" || anomaly.Severity.Rank() > highest.Rank() {
+				highest = anomaly.Severity
+			}
+		}
+	}
+
+	return highest
+}
+
+// jsonTypeName names the JSON type of a value, for reporting what a request actually held
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64, float32, int, int32, int64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinName(prefix, name string) string {
+	if prefix == "" {
+		return name
 	}
 
-	return anomalies
+	return prefix + "." + name
 }
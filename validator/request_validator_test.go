@@ -2,12 +2,14 @@ package validator
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
 
 	"anomaly_detector/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -42,7 +44,8 @@ func TestRequestValidator_Validate(t *testing.T) {
 		}
 
 		result := validator.Validate(ctx, tRequest, tModel)
-		assert.Empty(t, result)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Anomalies)
 	})
 
 	t.Run("multiple anomalies", func(t *testing.T) {
@@ -73,16 +76,563 @@ func TestRequestValidator_Validate(t *testing.T) {
 			{
 				Field:         "headers",
 				ParameterName: "Authorization",
+				Code:          models.CodeMissingRequired,
+				Risk:          25,
+				Severity:      models.SeverityCritical,
 				Reason:        "required parameter \"Authorization\" is missing",
+				Expected:      []models.ParamType{models.TypeAuthToken},
 			},
 			{
 				Field:         "body",
 				ParameterName: "id",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
 				Reason:        "type mismatch: expected one of [Int] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeInt},
+				Actual:        "string",
 			},
 		}
 
 		result := validator.Validate(ctx, tRequest, tModel)
-		assert.ElementsMatch(t, expectedAnomalousFields, result)
+		assert.False(t, result.Valid)
+		assert.ElementsMatch(t, expectedAnomalousFields, result.Anomalies)
+	})
+
+	t.Run("path parameters", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:   "/users/{user_id}/orders/{order_id}",
+			Method: http.MethodGet,
+			PathParams: []*models.Parameter{
+				{Name: "user_id", Types: []models.ParamType{models.TypeInt}, Required: true},
+				{Name: "order_id", Types: []models.ParamType{models.TypeUUID}, Required: true},
+			},
+		}
+
+		validRequest := &models.Request{
+			Path:   "/users/42/orders/550e8400-e29b-41d4-a716-446655440000",
+			Method: http.MethodGet,
+		}
+		assert.Empty(t, validator.Validate(ctx, validRequest, tModel).Anomalies)
+
+		invalidRequest := &models.Request{
+			Path:   "/users/abc/orders/550e8400-e29b-41d4-a716-446655440000",
+			Method: http.MethodGet,
+		}
+
+		expectedAnomalousFields := []*models.FieldAnomaly{
+			{
+				Field:         "path_params",
+				ParameterName: "user_id",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [Int] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeInt},
+				Actual:        "string",
+			},
+		}
+
+		result := validator.Validate(ctx, invalidRequest, tModel)
+		assert.Equal(t, expectedAnomalousFields, result.Anomalies)
+	})
+
+	t.Run("coerced string values", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:   "/users/{active}",
+			Method: http.MethodGet,
+			Coerce: []string{"query_params", "headers"},
+			PathParams: []*models.Parameter{
+				{Name: "active", Types: []models.ParamType{models.TypeBoolean}, Required: true},
+			},
+			QueryParams: []*models.Parameter{
+				{Name: "user_id", Types: []models.ParamType{models.TypeInt}, Required: true},
+				{Name: "include_deleted", Types: []models.ParamType{models.TypeBoolean}},
+				{
+					Name: "ids", Types: []models.ParamType{models.TypeList},
+					Items: &models.Parameter{Types: []models.ParamType{models.TypeInt}},
+				},
+				{Name: "tags", Types: []models.ParamType{models.TypeList}, MaxLength: ptr(2)},
+				{Name: "ref", Types: []models.ParamType{models.TypeString, models.TypeInt}, Enum: []any{"7"}},
+			},
+			Headers: []*models.Parameter{
+				{Name: "X-Retry", Types: []models.ParamType{models.TypeInt}, Maximum: ptr(3.0)},
+			},
+			Body: []*models.Parameter{
+				{Name: "count", Types: []models.ParamType{models.TypeInt}},
+			},
+		}
+
+		validRequest := &models.Request{
+			Path:   "/users/1",
+			Method: http.MethodGet,
+			QueryParams: []*models.RequestParam{
+				{Name: "user_id", Value: "42"},
+				{Name: "include_deleted", Value: "0"},
+				{Name: "ids", Value: "1,2,3"},
+				{Name: "tags", Value: "a"},
+				{Name: "tags", Value: "b,c"},
+				{Name: "ref", Value: "7"},
+			},
+			Headers: []*models.RequestParam{{Name: "X-Retry", Value: "2"}},
+		}
+		assert.Empty(t, validator.Validate(ctx, validRequest, tModel).Anomalies)
+
+		typedRequest := &models.Request{
+			Path:        "/users/true",
+			Method:      http.MethodGet,
+			QueryParams: []*models.RequestParam{{Name: "user_id", Value: float64(42)}},
+		}
+		assert.Empty(t, validator.Validate(ctx, typedRequest, tModel).Anomalies, "typed values are still accepted")
+
+		invalidRequest := &models.Request{
+			Path:   "/users/yes",
+			Method: http.MethodGet,
+			QueryParams: []*models.RequestParam{
+				{Name: "user_id", Value: "42"},
+				{Name: "ids", Value: "1,x"},
+				{Name: "tags", Value: "a"},
+				{Name: "tags", Value: "b"},
+				{Name: "tags", Value: "c"},
+			},
+			Headers: []*models.RequestParam{{Name: "x-retry", Value: "5"}},
+			Body:    []*models.RequestParam{{Name: "count", Value: "3"}},
+		}
+
+		result := validator.Validate(ctx, invalidRequest, tModel)
+
+		type anomalyKey struct {
+			field, name string
+			code        models.AnomalyCode
+		}
+
+		var keys []anomalyKey
+		for _, anomaly := range result.Anomalies {
+			keys = append(keys, anomalyKey{anomaly.Field, anomaly.ParameterName, anomaly.Code})
+		}
+
+		assert.Equal(t, []anomalyKey{
+			{"path_params", "active", models.CodeTypeMismatch},
+			{"query_params", "ids[1]", models.CodeTypeMismatch},
+			{"query_params", "tags", models.CodeConstraintViolation},
+			{"headers", "X-Retry", models.CodeConstraintViolation},
+			{"body", "count", models.CodeTypeMismatch},
+		}, keys)
+		assert.Equal(t, json.Number("5"), result.Anomalies[3].Actual)
+	})
+
+	t.Run("uncoerced query params are strictly typed", func(t *testing.T) {
+		tModel := &models.APIModel{
+			Path:        tTestPath,
+			Method:      http.MethodGet,
+			QueryParams: []*models.Parameter{{Name: "user_id", Types: []models.ParamType{models.TypeInt}}},
+		}
+
+		result := NewRequestValidator().Validate(context.Background(), &models.Request{
+			Path: tTestPath, Method: http.MethodGet, QueryParams: []*models.RequestParam{{Name: "user_id", Value: "42"}},
+		}, tModel)
+
+		require.Len(t, result.Anomalies, 1)
+		assert.Equal(t, models.CodeTypeMismatch, result.Anomalies[0].Code)
+	})
+
+	t.Run("null values and exact JSON numbers", func(t *testing.T) {
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.Parameter{
+				{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+				{Name: "price", Types: []models.ParamType{models.TypeFloat}, Minimum: ptr(0.0)},
+				{Name: "parent_id", Types: []models.ParamType{models.TypeInt}, Nullable: true},
+				{Name: "note", Types: []models.ParamType{models.TypeString}},
+			},
+		}
+
+		var tRequest models.Request
+
+		require.NoError(t, json.Unmarshal([]byte(`{"path": "/test", "method": "POST", "body": [
+			{"name": "id", "value": 18446744073709551615},
+			{"name": "price", "value": 9.99},
+			{"name": "parent_id", "value": null},
+			{"name": "note", "value": null}
+		]}`), &tRequest))
+		assert.Equal(t, json.Number("18446744073709551615"), tRequest.Body[0].Value, "64-bit IDs keep their precision")
+
+		result := NewRequestValidator().Validate(context.Background(), &tRequest, tModel)
+
+		require.Len(t, result.Anomalies, 1)
+		assert.Equal(t, "note", result.Anomalies[0].ParameterName)
+		assert.Equal(t, models.CodeTypeMismatch, result.Anomalies[0].Code)
+		assert.Equal(t, "null", result.Anomalies[0].Actual)
+	})
+
+	t.Run("nested body", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.Parameter{
+				{
+					Name:     "address",
+					Types:    []models.ParamType{models.TypeObject},
+					Required: true,
+					Properties: []*models.Parameter{
+						{Name: "city", Types: []models.ParamType{models.TypeString}, Required: true},
+						{Name: "zip", Types: []models.ParamType{models.TypeInt}, Required: true},
+					},
+				},
+				{
+					Name:     "items",
+					Types:    []models.ParamType{models.TypeList},
+					Required: true,
+					Items: &models.Parameter{
+						Types: []models.ParamType{models.TypeObject},
+						Properties: []*models.Parameter{
+							{Name: "sku", Types: []models.ParamType{models.TypeString}, Required: true},
+						},
+					},
+				},
+			},
+		}
+
+		tRequest := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.RequestParam{
+				{Name: "address", Value: map[string]any{"zip": "12345"}},
+				{Name: "items", Value: []any{
+					map[string]any{"sku": "A-1"},
+					map[string]any{"sku": float64(2)},
+					"not an object",
+				}},
+			},
+		}
+
+		expectedAnomalousFields := []*models.FieldAnomaly{
+			{
+				Field:         "body",
+				ParameterName: "address.city",
+				Code:          models.CodeMissingRequired,
+				Risk:          25,
+				Severity:      models.SeverityCritical,
+				Reason:        "required parameter \"address.city\" is missing",
+				Expected:      []models.ParamType{models.TypeString},
+			},
+			{
+				Field:         "body",
+				ParameterName: "address.zip",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [Int] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeInt},
+				Actual:        "string",
+			},
+			{
+				Field:         "body",
+				ParameterName: "items[1].sku",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [String] types, but got the type float64",
+				Expected:      []models.ParamType{models.TypeString},
+				Actual:        "number",
+			},
+			{
+				Field:         "body",
+				ParameterName: "items[2]",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [Object] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeObject},
+				Actual:        "string",
+			},
+		}
+
+		result := validator.Validate(ctx, tRequest, tModel)
+		assert.Equal(t, expectedAnomalousFields, result.Anomalies)
+	})
+
+	t.Run("constraints are checked after the type", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+		minimum := 1.0
+
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.Parameter{
+				{Name: "quantity", Types: []models.ParamType{models.TypeInt}, Required: true, Minimum: &minimum},
+				{Name: "status", Types: []models.ParamType{models.TypeString}, Enum: []any{"active", "inactive"}},
+			},
+		}
+
+		tRequest := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.RequestParam{
+				{Name: "quantity", Value: float64(-5000)},
+				{Name: "status", Value: float64(1)},
+			},
+		}
+
+		expectedAnomalousFields := []*models.FieldAnomaly{
+			{
+				Field:         "body",
+				ParameterName: "quantity",
+				Code:          models.CodeConstraintViolation,
+				Risk:          30,
+				Severity:      models.SeverityCritical,
+				Reason:        "constraint \"minimum\" violated: value -5000 is less than 1",
+				Constraint:    "minimum",
+				Expected:      1.0,
+				Actual:        -5000.0,
+			},
+			{
+				Field:         "body",
+				ParameterName: "status",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [String] types, but got the type float64",
+				Expected:      []models.ParamType{models.TypeString},
+				Actual:        "number",
+			},
+		}
+
+		result := validator.Validate(ctx, tRequest, tModel)
+		assert.Equal(t, expectedAnomalousFields, result.Anomalies)
+	})
+
+	t.Run("unexpected parameters", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tRequest := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			QueryParams: []*models.RequestParam{
+				{Name: "id", Value: float64(1)},
+				{Name: "is_admin", Value: true},
+			},
+			Headers: []*models.RequestParam{
+				{Name: "user-agent", Value: "curl/8.0"},
+				{Name: "X-Debug", Value: "1"},
+			},
+			Body: []*models.RequestParam{
+				{Name: "address", Value: map[string]any{"city": "Paris", "injected": "x"}},
+			},
+		}
+
+		newModel := func(policy models.UnexpectedParamsPolicy) *models.APIModel {
+			return &models.APIModel{
+				Path:             tTestPath,
+				Method:           http.MethodPost,
+				UnexpectedParams: policy,
+				QueryParams: []*models.Parameter{
+					{Name: "id", Types: []models.ParamType{models.TypeInt}, Required: true},
+				},
+				Body: []*models.Parameter{
+					{
+						Name:  "address",
+						Types: []models.ParamType{models.TypeObject},
+						Properties: []*models.Parameter{
+							{Name: "city", Types: []models.ParamType{models.TypeString}},
+						},
+					},
+				},
+			}
+		}
+
+		expectedUnexpected := func(severity models.Severity, risk float64) []*models.FieldAnomaly {
+			return []*models.FieldAnomaly{
+				{
+					Field:         "query_params",
+					ParameterName: "is_admin",
+					Code:          models.CodeUnexpectedParam,
+					Risk:          risk,
+					Severity:      severity,
+					Reason:        "unexpected parameter \"is_admin\" is not declared in the model",
+				},
+				{
+					Field:         "headers",
+					ParameterName: "X-Debug",
+					Code:          models.CodeUnexpectedParam,
+					Risk:          risk,
+					Severity:      severity,
+					Reason:        "unexpected parameter \"X-Debug\" is not declared in the model",
+				},
+				{
+					Field:         "body",
+					ParameterName: "address.injected",
+					Code:          models.CodeUnexpectedParam,
+					Risk:          risk,
+					Severity:      severity,
+					Reason:        "unexpected parameter \"address.injected\" is not declared in the model",
+				},
+			}
+		}
+
+		result := validator.Validate(ctx, tRequest, newModel(models.UnexpectedParamsAllow))
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Anomalies)
+		assert.Empty(t, result.Warnings)
+
+		// report is the default policy
+		result = validator.Validate(ctx, tRequest, newModel(""))
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Anomalies)
+		assert.Equal(t, expectedUnexpected(models.SeverityWarning, 0), result.Warnings)
+		assert.Equal(t, models.SeverityWarning, result.HighestSeverity)
+
+		result = validator.Validate(ctx, tRequest, newModel(models.UnexpectedParamsReject))
+		assert.False(t, result.Valid)
+		assert.Equal(t, expectedUnexpected(models.SeverityCritical, 50), result.Anomalies)
+		assert.Empty(t, result.Warnings)
+	})
+	t.Run("severity is inherited and reported at its highest", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body: []*models.Parameter{
+				{
+					Name: "address", Types: []models.ParamType{models.TypeObject}, Severity: models.SeverityInfo,
+					Properties: []*models.Parameter{
+						{Name: "zip", Types: []models.ParamType{models.TypeInt}},
+						{Name: "city", Types: []models.ParamType{models.TypeString}, Required: true,
+							Severity: models.SeverityWarning},
+					},
+				},
+			},
+		}
+
+		tRequest := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body:   []*models.RequestParam{{Name: "address", Value: map[string]any{"zip": "x"}}},
+		}
+
+		result := validator.Validate(ctx, tRequest, tModel)
+		assert.Len(t, result.Anomalies, 2)
+		assert.Equal(t, models.SeverityInfo, result.Anomalies[0].Severity)
+		assert.Equal(t, models.SeverityWarning, result.Anomalies[1].Severity)
+		assert.Equal(t, models.SeverityWarning, result.HighestSeverity)
+
+		valid := validator.Validate(ctx, &models.Request{Path: tTestPath, Method: http.MethodPost}, tModel)
+		assert.Empty(t, valid.HighestSeverity)
+	})
+
+	t.Run("header names are case-insensitive", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:             tTestPath,
+			Method:           http.MethodGet,
+			UnexpectedParams: models.UnexpectedParamsReject,
+			Headers: []*models.Parameter{
+				{Name: "X-Request-Id", Types: []models.ParamType{models.TypeUUID}, Required: true},
+			},
+		}
+
+		tRequest := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodGet,
+			Headers: []*models.RequestParam{
+				{Name: "x-request-id", Value: "550e8400-e29b-41d4-a716-446655440000"},
+				{Name: "x-extra", Value: "a"},
+				{Name: "X-EXTRA", Value: "b"},
+			},
+		}
+
+		result := validator.Validate(ctx, tRequest, tModel)
+
+		require.Len(t, result.Anomalies, 1)
+		assert.Equal(t, models.CodeUnexpectedParam, result.Anomalies[0].Code)
+		assert.Equal(t, "x-extra", result.Anomalies[0].ParameterName)
+	})
+
+	t.Run("repeated parameters", func(t *testing.T) {
+		ctx := context.Background()
+		validator := NewRequestValidator()
+
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodGet,
+			QueryParams: []*models.Parameter{
+				{Name: "id", Types: []models.ParamType{models.TypeInt}},
+				{Name: "tag", Types: []models.ParamType{models.TypeString}, Repeated: true},
+				{Name: "page", Types: []models.ParamType{models.TypeInt}, Repeated: true},
+			},
+		}
+
+		valid := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodGet,
+			QueryParams: []*models.RequestParam{
+				{Name: "tag", Value: "a"}, {Name: "id", Value: float64(1)}, {Name: "tag", Value: "b"},
+			},
+		}
+		assert.Empty(t, validator.Validate(ctx, valid, tModel).Anomalies)
+
+		invalid := &models.Request{
+			Path:   tTestPath,
+			Method: http.MethodGet,
+			QueryParams: []*models.RequestParam{
+				{Name: "id", Value: float64(1)},
+				{Name: "id", Value: "1 OR 1=1"},
+				{Name: "page", Value: float64(1)},
+				{Name: "page", Value: "two"},
+			},
+		}
+
+		expectedAnomalousFields := []*models.FieldAnomaly{
+			{
+				Field:         "query_params",
+				ParameterName: "id",
+				Code:          models.CodeDuplicateParam,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "parameter \"id\" is sent 2 times but may only be sent once",
+				Expected:      1,
+				Actual:        2,
+			},
+			{
+				Field:         "query_params",
+				ParameterName: "id[1]",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [Int] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeInt},
+				Actual:        "string",
+			},
+			{
+				Field:         "query_params",
+				ParameterName: "page[1]",
+				Code:          models.CodeTypeMismatch,
+				Risk:          40,
+				Severity:      models.SeverityCritical,
+				Reason:        "type mismatch: expected one of [Int] types, but got the type string",
+				Expected:      []models.ParamType{models.TypeInt},
+				Actual:        "string",
+			},
+		}
+
+		result := validator.Validate(ctx, invalid, tModel)
+		assert.False(t, result.Valid)
+		assert.Equal(t, expectedAnomalousFields, result.Anomalies)
 	})
 }
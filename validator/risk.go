@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+)
+
+const cMaxRiskScore = 100
+
+// defaultCodeWeights is the risk of a single anomaly of each code, before the section and parameter weights.
+// Undeclared parameters and forged tokens weigh the most, as probing for hidden fields and tampering
+// with credentials are typical signs of an attack.
+var defaultCodeWeights = map[models.AnomalyCode]float64{
+	models.CodeMissingRequired:     25,
+	models.CodeTypeMismatch:        40,
+	models.CodeConstraintViolation: 30,
+	models.CodeUnexpectedParam:     50,
+	models.CodeDuplicateParam:      40,
+	models.CodeInvalidToken:        40,
+	models.CodeDisallowedAlgorithm: 50,
+	models.CodeTokenExpired:        30,
+	models.CodeTokenNotYetValid:    30,
+	models.CodeMissingClaim:        30,
+	models.CodeInvalidSignature:    50,
+}
+
+// riskScorer turns anomalies into a 0-100 risk score. The risk of an anomaly is the weight of its code
+// multiplied by the weight of its section and the risk weight of its parameter, and the score of a request
+// is the sum of the risks of its anomalies, capped at 100. A request is valid while its score does not
+// exceed the threshold.
+type riskScorer struct {
+	codeWeights    map[models.AnomalyCode]float64
+	sectionWeights map[string]float64
+	threshold      float64
+}
+
+// defaultRiskScorer weighs every section equally and invalidates any request with a risky anomaly
+func defaultRiskScorer() *riskScorer {
+	return &riskScorer{
+		codeWeights:    defaultCodeWeights,
+		sectionWeights: map[string]float64{},
+	}
+}
+
+// newRiskScorer overrides the default weights with the RISK_CODE_WEIGHTS and RISK_SECTION_WEIGHTS configuration
+func newRiskScorer(cfg *config.InitConfig) (*riskScorer, error) {
+	scorer := defaultRiskScorer()
+	scorer.threshold = cfg.RiskThreshold
+
+	if len(cfg.RiskCodeWeights) > 0 {
+		scorer.codeWeights = make(map[models.AnomalyCode]float64, len(defaultCodeWeights))
+		for code, weight := range defaultCodeWeights {
+			scorer.codeWeights[code] = weight
+		}
+	}
+
+	for code, weight := range cfg.RiskCodeWeights {
+		if _, exists := defaultCodeWeights[models.AnomalyCode(code)]; !exists {
+			return nil, fmt.Errorf("unknown anomaly code %q in risk code weights", code)
+		}
+
+		if weight < 0 {
+			return nil, fmt.Errorf("negative risk weight for anomaly code %s", code)
+		}
+
+		scorer.codeWeights[models.AnomalyCode(code)] = weight
+	}
+
+	for section, weight := range cfg.RiskSectionWeights {
+		switch section {
+		case cFieldPathParams, cFieldQueryParams, cFieldHeaders, cFieldCookies, cFieldBody:
+		default:
+			return nil, fmt.Errorf("unknown section %q in risk section weights", section)
+		}
+
+		if weight < 0 {
+			return nil, fmt.Errorf("negative risk weight for section %s", section)
+		}
+
+		scorer.sectionWeights[section] = weight
+	}
+
+	return scorer, nil
+}
+
+// risk returns the risk of an anomaly of a section, given the risk weight of its parameter
+func (rs *riskScorer) risk(code models.AnomalyCode, section string, paramWeight float64) float64 {
+	sectionWeight, exists := rs.sectionWeights[section]
+	if !exists {
+		sectionWeight = 1
+	}
+
+	return roundRisk(rs.codeWeights[code] * sectionWeight * paramWeight)
+}
+
+// score sets the risk score of a result and decides whether it is valid
+func (rs *riskScorer) score(result *models.ValidationResult) {
+	var total float64
+	for _, anomaly := range result.Anomalies {
+		total += anomaly.Risk
+	}
+
+	result.RiskScore = roundRisk(math.Min(total, cMaxRiskScore))
+	result.Valid = result.RiskScore <= rs.threshold
+}
+
+// roundRisk keeps one decimal, so that scores are stable to compare and display
+func roundRisk(risk float64) float64 {
+	return math.Round(risk*10) / 10
+}
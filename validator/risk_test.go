@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"anomaly_detector/config"
+	"anomaly_detector/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskScore(t *testing.T) {
+	tModel := &models.APIModel{
+		Path:   tTestPath,
+		Method: http.MethodPost,
+		Headers: []*models.Parameter{
+			{Name: "X-Trace", Types: []models.ParamType{models.TypeString}, Required: true},
+		},
+		Body: []*models.Parameter{
+			{Name: "id", Types: []models.ParamType{models.TypeInt}},
+			{
+				Name: "address", Types: []models.ParamType{models.TypeObject}, RiskWeight: ptr(0.5),
+				Properties: []*models.Parameter{
+					{Name: "zip", Types: []models.ParamType{models.TypeInt}},
+				},
+			},
+		},
+	}
+
+	tRequest := &models.Request{
+		Path:   tTestPath,
+		Method: http.MethodPost,
+		Body: []*models.RequestParam{
+			{Name: "id", Value: "x"},
+			{Name: "address", Value: map[string]any{"zip": "x"}},
+		},
+	}
+
+	t.Run("sums the weighted risk of the anomalies", func(t *testing.T) {
+		result := NewRequestValidator().Validate(context.Background(), tRequest, tModel)
+
+		require.Len(t, result.Anomalies, 3)
+		assert.Equal(t, []float64{25, 40, 20}, []float64{
+			result.Anomalies[0].Risk, result.Anomalies[1].Risk, result.Anomalies[2].Risk,
+		})
+		assert.Equal(t, 85.0, result.RiskScore)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("applies the configured weights and threshold", func(t *testing.T) {
+		validator, err := NewConfiguredRequestValidator(&config.InitConfig{
+			RiskCodeWeights:    map[string]float64{"TYPE_MISMATCH": 10},
+			RiskSectionWeights: map[string]float64{"headers": 0},
+			RiskThreshold:      15,
+		}, nil)
+		require.NoError(t, err)
+
+		result := validator.Validate(context.Background(), tRequest, tModel)
+		assert.Equal(t, 15.0, result.RiskScore)
+		assert.True(t, result.Valid)
+		assert.Len(t, result.Anomalies, 3)
+	})
+
+	t.Run("caps the score at 100", func(t *testing.T) {
+		validator, err := NewConfiguredRequestValidator(&config.InitConfig{
+			RiskCodeWeights: map[string]float64{"TYPE_MISMATCH": 90},
+		}, nil)
+		require.NoError(t, err)
+
+		result := validator.Validate(context.Background(), tRequest, tModel)
+		assert.Equal(t, 100.0, result.RiskScore)
+	})
+
+	t.Run("a request without anomalies scores 0", func(t *testing.T) {
+		tValid := &models.Request{
+			Path:    tTestPath,
+			Method:  http.MethodPost,
+			Headers: []*models.RequestParam{{Name: "X-Trace", Value: "abc"}},
+		}
+
+		result := NewRequestValidator().Validate(context.Background(), tValid, tModel)
+		assert.Zero(t, result.RiskScore)
+		assert.True(t, result.Valid)
+	})
+}
+
+func TestNewRiskScorer(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.InitConfig
+	}{
+		{"unknown code", &config.InitConfig{RiskCodeWeights: map[string]float64{"SQL_INJECTION": 10}}},
+		{"negative code weight", &config.InitConfig{RiskCodeWeights: map[string]float64{"TYPE_MISMATCH": -1}}},
+		{"unknown section", &config.InitConfig{RiskSectionWeights: map[string]float64{"trailers": 2}}},
+		{"negative section weight", &config.InitConfig{RiskSectionWeights: map[string]float64{"body": -1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newRiskScorer(test.cfg)
+			assert.Error(t, err)
+		})
+	}
+
+	scorer, err := newRiskScorer(&config.InitConfig{RiskCodeWeights: map[string]float64{"TYPE_MISMATCH": 5}})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, scorer.codeWeights[models.CodeTypeMismatch])
+	assert.Equal(t, 40.0, defaultCodeWeights[models.CodeTypeMismatch], "configuration must not alter the defaults")
+	assert.Equal(t, 25.0, scorer.codeWeights[models.CodeMissingRequired])
+}
@@ -1,37 +1,112 @@
 package validator
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/netip"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 
+	"anomaly_detector/dateformat"
 	"anomaly_detector/models"
+	"anomaly_detector/typeregistry"
+)
+
+// Patterns of the string types that have no standard OpenAPI format, shared with the OpenAPI export
+const (
+	// DatePattern matches the default Date format: dd-mm-yyyy. The validator also checks that the date exists.
+	DatePattern = `^(0[1-9]|[12][0-9]|3[01])-(0[1-9]|1[0-2])-\d{4}$`
+	// AuthTokenPattern matches the Auth-Token format: Bearer <token>, with the token charset of RFC 6750,
+	// which includes JWTs
+	AuthTokenPattern = `^Bearer [A-Za-z0-9\-._~+/]+=*$`
+	// PhonePattern matches E.164 phone numbers: a + followed by up to 15 digits, without a leading zero
+	PhonePattern = `^\+[1-9][0-9]{6,14}$`
+	// JWTPattern matches the structure of a compact JWT, the validator also decodes its header and claims
+	JWTPattern = `^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`
+)
+
+const (
+	cMaxHostnameLength = 253
+	cMaxLabelLength    = 63
 )
 
 var (
-	// Date format: dd-mm-yyyy
-	dateRegex = regexp.MustCompile(`^(0[1-9]|[12][0-9]|3[01])-(0[1-9]|1[0-2])-\d{4}$`)
 	// Email format: simplified RFC 5321
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 	// UUID format: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
-	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
-	// Auth-Token format: Bearer <token>
-	authTokenRegex = regexp.MustCompile(`^Bearer [a-zA-Z0-9]+$`)
+	uuidRegex      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	authTokenRegex = regexp.MustCompile(AuthTokenPattern)
+	phoneRegex     = regexp.MustCompile(PhonePattern)
 )
 
+// inferableTypes are the types a value is matched against by MatchingTypes, the most specific first.
+// Hostname and Base64 are left out, as most plain words are valid hostnames and many are valid base64.
+var inferableTypes = []models.ParamType{
+	models.TypeJWT,
+	models.TypeTimestamp,
+	models.TypeDate,
+	models.TypeEmail,
+	models.TypeUUID,
+	models.TypeAuthToken,
+	models.TypeIP,
+	models.TypeCIDR,
+	models.TypePhone,
+	models.TypeURL,
+	models.TypeInt,
+	models.TypeNumber,
+	models.TypeNull,
+	models.TypeBoolean,
+	models.TypeList,
+	models.TypeObject,
+	models.TypeString,
+}
+
+// InferableTypes returns the types MatchingTypes can report, the most specific first
+func InferableTypes() []models.ParamType {
+	return append([]models.ParamType{}, inferableTypes...)
+}
+
+// MatchingTypes returns every type a body, query or header value is valid for, the most specific first
+func MatchingTypes(value any) []models.ParamType {
+	var matching []models.ParamType
+
+	for _, typeName := range inferableTypes {
+		if validateType(value, typeName) {
+			matching = append(matching, typeName)
+		}
+	}
+
+	return matching
+}
+
 func validateType(value any, typeName models.ParamType) bool {
 	switch v := value.(type) {
+	case nil:
+		return typeName == models.TypeNull
+
 	case string:
 		return validateStringType(v, typeName)
 
+	case json.Number:
+		// Request values are decoded with UseNumber, keeping integers beyond 2^53 exact
+		if typeName == models.TypeInt {
+			return isIntegral(v)
+		}
+
+		return isNumberType(typeName)
+
 	case float64:
-		// JSON unmarshaling converts numbers to float64
-		if typeName != models.TypeInt {
-			return false
+		if typeName == models.TypeInt {
+			return v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64
 		}
-		// Ensure it's actually an integer
-		return v == float64(int(v))
+
+		return isNumberType(typeName)
 
 	case int, int32, int64:
-		return typeName == models.TypeInt
+		return typeName == models.TypeInt || isNumberType(typeName)
 
 	case bool:
 		return typeName == models.TypeBoolean
@@ -39,18 +114,99 @@ func validateType(value any, typeName models.ParamType) bool {
 	case []any, []map[string]any:
 		return typeName == models.TypeList
 
+	case map[string]any:
+		return typeName == models.TypeObject
+
 	default:
 		return false
 	}
 }
 
+// matches reports whether a value is of a type. Built-in types are matched with validateType, while custom
+// types are looked up among the Go types and the types defined for the tenant.
+func (sv *sectionValidation) matches(value any, typeName models.ParamType) bool {
+	if typeregistry.IsBuiltin(typeName) {
+		return validateType(value, typeName)
+	}
+
+	if paramType, exists := typeregistry.Lookup(typeName); exists {
+		return paramType.Match(value)
+	}
+
+	definition, exists := sv.definedTypes[typeName]
+	if !exists {
+		return false
+	}
+
+	if definition.Pattern != "" {
+		str, ok := value.(string)
+		if !ok || !sv.rv.matchesPattern(str, definition.Pattern) {
+			return false
+		}
+	}
+
+	if len(definition.Enum) > 0 && !enumContains(definition.Enum, value) {
+		return false
+	}
+
+	// The store rejects compositions that lead back to the type, so the recursion ends
+	for _, composed := range definition.AnyOf {
+		if sv.matches(value, composed) {
+			return true
+		}
+	}
+
+	return len(definition.AnyOf) == 0
+}
+
+// isNumberType reports whether a type accepts any number, integral or not
+func isNumberType(typeName models.ParamType) bool {
+	return typeName == models.TypeNumber || typeName == models.TypeFloat
+}
+
+// isIntegral reports whether a JSON number is a 64-bit integer, signed or unsigned. Integral values written
+// with a fraction or an exponent, such as 1.0 or 1e3, count as long as they fit in an int64.
+func isIntegral(number json.Number) bool {
+	if _, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+		return true
+	}
+
+	if _, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		return true
+	}
+
+	v, err := number.Float64()
+
+	return err == nil && v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64
+}
+
+// listItems returns the elements of a List value as a generic slice
+func listItems(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+
+		return items
+
+	default:
+		return nil
+	}
+}
+
 func validateStringType(value string, typeName models.ParamType) bool {
 	switch typeName {
 	case models.TypeString:
 		return true
 
-	case models.TypeDate:
-		return dateRegex.MatchString(value)
+	case models.TypeDate, models.TypeTimestamp:
+		_, ok := dateformat.Parse(value, defaultFormats[typeName])
+		return ok
 
 	case models.TypeEmail:
 		return emailRegex.MatchString(value)
@@ -62,7 +218,63 @@ func validateStringType(value string, typeName models.ParamType) bool {
 	case models.TypeAuthToken:
 		return authTokenRegex.MatchString(value)
 
+	case models.TypeIP:
+		_, err := netip.ParseAddr(value)
+		return err == nil
+
+	case models.TypeCIDR:
+		_, err := netip.ParsePrefix(value)
+		return err == nil
+
+	case models.TypeURL:
+		return isAbsoluteURL(value)
+
+	case models.TypeHostname:
+		return isHostname(value)
+
+	case models.TypePhone:
+		return phoneRegex.MatchString(value)
+
+	case models.TypeBase64:
+		_, err := base64.StdEncoding.Strict().DecodeString(value)
+		return value != "" && err == nil
+
+	case models.TypeJWT:
+		_, err := parseJWT(value)
+		return err == nil
+
 	default:
 		return false
 	}
 }
+
+// isAbsoluteURL accepts URLs with a scheme and a host, such as callback URLs
+func isAbsoluteURL(value string) bool {
+	parsed, err := url.Parse(value)
+
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+// isHostname checks a DNS hostname as of RFC 1123: dot separated labels of letters, digits and
+// inner dashes, at most 63 characters each and 253 in total. A trailing dot is allowed.
+func isHostname(value string) bool {
+	value = strings.TrimSuffix(value, ".")
+	if value == "" || len(value) > cMaxHostnameLength {
+		return false
+	}
+
+	for label := range strings.SplitSeq(value, ".") {
+		if label == "" || len(label) > cMaxLabelLength || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, char := range label {
+			isAlphanumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+			if !isAlphanumeric && char != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
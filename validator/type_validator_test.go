@@ -1,13 +1,37 @@
 package validator
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 
+	"anomaly_detector/config"
 	"anomaly_detector/models"
+	"anomaly_detector/store"
+	"anomaly_detector/typeregistry"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+const (
+	tEvenType models.ParamType = "ValidatorTestEven"
+
+	// tJWTHeader is {"alg":"HS256","typ":"JWT"} and tJWTClaims {"sub":"1234567890"}
+	tJWTHeader    = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
+	tJWTClaims    = "eyJzdWIiOiIxMjM0NTY3ODkwIn0"
+	tJWTSignature = "SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+)
+
+func init() {
+	typeregistry.Register(tEvenType, typeregistry.TypeFunc(func(value any) bool {
+		number, ok := value.(float64)
+		return ok && int(number)%2 == 0
+	}))
+}
+
 type typeTestCase struct {
 	name       string
 	inputValue any
@@ -32,6 +56,29 @@ func TestValidateType(t *testing.T) {
 		{name: "invalid float", inputValue: float64(123.5), isValid: false},
 		{name: "valid go int", inputValue: 123, isValid: true},
 		{name: "invalid type", inputValue: "123", isValid: false},
+		{name: "valid 64-bit json number", inputValue: json.Number("9223372036854775807"), isValid: true},
+		{name: "valid unsigned 64-bit json number", inputValue: json.Number("18446744073709551615"), isValid: true},
+		{name: "valid integral json number", inputValue: json.Number("1.0e3"), isValid: true},
+		{name: "invalid decimal json number", inputValue: json.Number("9.99"), isValid: false},
+		{name: "invalid json number beyond 64 bits", inputValue: json.Number("18446744073709551616"), isValid: false},
+		{name: "invalid float beyond 64 bits", inputValue: float64(1e19), isValid: false},
+	})
+
+	for _, typeName := range []models.ParamType{models.TypeNumber, models.TypeFloat} {
+		runTypeTests(t, typeName, []typeTestCase{
+			{name: "valid decimal json number", inputValue: json.Number("9.99"), isValid: true},
+			{name: "valid integral json number", inputValue: json.Number("42"), isValid: true},
+			{name: "valid float", inputValue: 1.5, isValid: true},
+			{name: "valid go int", inputValue: 3, isValid: true},
+			{name: "invalid numeric string", inputValue: "9.99", isValid: false},
+			{name: "invalid null", inputValue: nil, isValid: false},
+		})
+	}
+
+	runTypeTests(t, models.TypeNull, []typeTestCase{
+		{name: "valid null", inputValue: nil, isValid: true},
+		{name: "invalid empty string", inputValue: "", isValid: false},
+		{name: "invalid zero", inputValue: json.Number("0"), isValid: false},
 	})
 
 	runTypeTests(t, models.TypeString, []typeTestCase{
@@ -52,12 +99,21 @@ func TestValidateType(t *testing.T) {
 		{name: "invalid type", inputValue: "not a list", isValid: false},
 	})
 
+	runTypeTests(t, models.TypeObject, []typeTestCase{
+		{name: "valid empty object", inputValue: map[string]any{}, isValid: true},
+		{name: "valid object with fields", inputValue: map[string]any{"city": "Paris"}, isValid: true},
+		{name: "invalid list", inputValue: []any{}, isValid: false},
+		{name: "invalid type", inputValue: "not an object", isValid: false},
+	})
+
 	runTypeTests(t, models.TypeDate, []typeTestCase{
 		{name: "valid date", inputValue: "12-01-2022", isValid: true},
 		{name: "valid date", inputValue: "31-12-2023", isValid: true},
 		{name: "invalid day", inputValue: "67-12-2023", isValid: false},
 		{name: "invalid month", inputValue: "14-45-2023", isValid: false},
 		{name: "invalid format", inputValue: "2022-01-12", isValid: false},
+		{name: "invalid calendar date", inputValue: "31-02-2024", isValid: false},
+		{name: "valid leap day", inputValue: "29-02-2024", isValid: true},
 		{name: "invalid type", inputValue: 123, isValid: false},
 	})
 
@@ -81,7 +137,97 @@ func TestValidateType(t *testing.T) {
 		{name: "valid long token", inputValue: "Bearer ebb3cbbe938c4776bd22a4ec2ea8b2ca", isValid: true},
 		{name: "invalid no Bearer", inputValue: "abc123", isValid: false},
 		{name: "invalid lowercase bearer", inputValue: "bearer abc123", isValid: false},
-		{name: "invalid special chars", inputValue: "Bearer abc-123", isValid: false},
+		{name: "valid token charset", inputValue: "Bearer abc-123._~+/==", isValid: true},
+		{name: "valid JWT", inputValue: "Bearer " + tJWTHeader + "." + tJWTClaims + "." + tJWTSignature, isValid: true},
+		{name: "invalid special chars", inputValue: "Bearer abc$123", isValid: false},
+		{name: "invalid padding inside", inputValue: "Bearer ab=c", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeIP, []typeTestCase{
+		{name: "valid ipv4", inputValue: "192.168.1.10", isValid: true},
+		{name: "valid ipv6", inputValue: "2001:db8::1", isValid: true},
+		{name: "valid ipv4 mapped ipv6", inputValue: "::ffff:10.0.0.1", isValid: true},
+		{name: "invalid octet", inputValue: "256.1.1.1", isValid: false},
+		{name: "invalid leading zero", inputValue: "010.0.0.1", isValid: false},
+		{name: "invalid prefix", inputValue: "10.0.0.0/8", isValid: false},
+		{name: "invalid hostname", inputValue: "localhost", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeCIDR, []typeTestCase{
+		{name: "valid ipv4 prefix", inputValue: "10.0.0.0/8", isValid: true},
+		{name: "valid ipv6 prefix", inputValue: "2001:db8::/32", isValid: true},
+		{name: "valid host prefix", inputValue: "192.168.1.10/32", isValid: true},
+		{name: "invalid prefix length", inputValue: "10.0.0.0/33", isValid: false},
+		{name: "invalid missing length", inputValue: "10.0.0.0", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeURL, []typeTestCase{
+		{name: "valid https", inputValue: "https://example.com/callback?id=1", isValid: true},
+		{name: "valid with port", inputValue: "http://10.0.0.1:8080/hook", isValid: true},
+		{name: "valid other scheme", inputValue: "wss://example.com/socket", isValid: true},
+		{name: "invalid relative", inputValue: "/callback", isValid: false},
+		{name: "invalid without host", inputValue: "mailto:user@example.com", isValid: false},
+		{name: "invalid without scheme", inputValue: "example.com/callback", isValid: false},
+		{name: "invalid control character", inputValue: "https://example.com/\n", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeHostname, []typeTestCase{
+		{name: "valid single label", inputValue: "localhost", isValid: true},
+		{name: "valid fqdn", inputValue: "api.example.com", isValid: true},
+		{name: "valid trailing dot", inputValue: "api.example.com.", isValid: true},
+		{name: "valid inner dash", inputValue: "my-host1.example.com", isValid: true},
+		{name: "invalid leading dash", inputValue: "-host.example.com", isValid: false},
+		{name: "invalid trailing dash", inputValue: "host-.example.com", isValid: false},
+		{name: "invalid empty label", inputValue: "api..example.com", isValid: false},
+		{name: "invalid underscore", inputValue: "my_host.example.com", isValid: false},
+		{name: "invalid long label", inputValue: strings.Repeat("a", 64) + ".com", isValid: false},
+		{name: "invalid long name", inputValue: strings.Repeat("a.", 127) + "com", isValid: false},
+		{name: "invalid empty", inputValue: "", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypePhone, []typeTestCase{
+		{name: "valid e164", inputValue: "+972501234567", isValid: true},
+		{name: "valid short", inputValue: "+1234567", isValid: true},
+		{name: "invalid without plus", inputValue: "972501234567", isValid: false},
+		{name: "invalid leading zero", inputValue: "+0501234567", isValid: false},
+		{name: "invalid too long", inputValue: "+1234567890123456", isValid: false},
+		{name: "invalid separators", inputValue: "+1 212-555-0100", isValid: false},
+		{name: "invalid type", inputValue: float64(972501234567), isValid: false},
+	})
+
+	runTypeTests(t, models.TypeTimestamp, []typeTestCase{
+		{name: "valid utc", inputValue: "2026-03-01T12:30:00Z", isValid: true},
+		{name: "valid offset", inputValue: "2026-03-01T12:30:00+02:00", isValid: true},
+		{name: "valid fraction", inputValue: "2026-03-01T12:30:00.123456Z", isValid: true},
+		{name: "invalid without zone", inputValue: "2026-03-01T12:30:00", isValid: false},
+		{name: "invalid date only", inputValue: "2026-03-01", isValid: false},
+		{name: "invalid month", inputValue: "2026-13-01T12:30:00Z", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeBase64, []typeTestCase{
+		{name: "valid padded", inputValue: "aGVsbG8=", isValid: true},
+		{name: "valid unpadded length", inputValue: "aGVsbG8h", isValid: true},
+		{name: "invalid missing padding", inputValue: "aGVsbG8", isValid: false},
+		{name: "invalid url alphabet", inputValue: "a-_b", isValid: false},
+		{name: "invalid empty", inputValue: "", isValid: false},
+		{name: "invalid type", inputValue: 123, isValid: false},
+	})
+
+	runTypeTests(t, models.TypeJWT, []typeTestCase{
+		{name: "valid signed", inputValue: tJWTHeader + "." + tJWTClaims + "." + tJWTSignature, isValid: true},
+		{name: "valid unsecured", inputValue: tJWTHeader + "." + tJWTClaims + ".", isValid: true},
+		{name: "invalid two segments", inputValue: tJWTHeader + "." + tJWTClaims, isValid: false},
+		{name: "invalid header json", inputValue: "bm90anNvbg." + tJWTClaims + ".", isValid: false},
+		{name: "invalid header without alg", inputValue: "eyJ0eXAiOiJKV1QifQ." + tJWTClaims + ".", isValid: false},
+		{name: "invalid claims array", inputValue: tJWTHeader + ".WzFd.", isValid: false},
+		{name: "invalid padding", inputValue: tJWTHeader + "=." + tJWTClaims + ".", isValid: false},
+		{name: "invalid bearer prefix", inputValue: "Bearer " + tJWTHeader + "." + tJWTClaims + ".", isValid: false},
 		{name: "invalid type", inputValue: 123, isValid: false},
 	})
 
@@ -90,3 +236,150 @@ func TestValidateType(t *testing.T) {
 		assert.False(t, result)
 	})
 }
+
+func TestCoerceString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		typeName models.ParamType
+		coerced  any
+		ok       bool
+	}{
+		{name: "int", value: "42", typeName: models.TypeInt, coerced: json.Number("42"), ok: true},
+		{name: "negative int", value: "-42", typeName: models.TypeInt, coerced: json.Number("-42"), ok: true},
+		{name: "int with a sign", value: "+042", typeName: models.TypeInt, coerced: json.Number("42"), ok: true},
+		{
+			name: "unsigned 64-bit int", value: "18446744073709551615", typeName: models.TypeInt,
+			coerced: json.Number("18446744073709551615"), ok: true,
+		},
+		{name: "non numeric int", value: "abc", typeName: models.TypeInt, ok: false},
+		{name: "decimal int", value: "4.2", typeName: models.TypeInt, ok: false},
+		{name: "decimal number", value: "-4.2e1", typeName: models.TypeNumber, coerced: json.Number("-4.2e1"), ok: true},
+		{name: "float", value: "9.99", typeName: models.TypeFloat, coerced: json.Number("9.99"), ok: true},
+		{name: "number outside of the JSON grammar", value: "0x1p-2", typeName: models.TypeNumber, ok: false},
+		{name: "boolean", value: "true", typeName: models.TypeBoolean, coerced: true, ok: true},
+		{name: "numeric boolean", value: "0", typeName: models.TypeBoolean, coerced: false, ok: true},
+		{name: "invalid boolean", value: "yes", typeName: models.TypeBoolean, ok: false},
+		{name: "comma separated list", value: "1,a,", typeName: models.TypeList, coerced: []any{"1", "a", ""}, ok: true},
+		{name: "empty list", value: "", typeName: models.TypeList, coerced: []any{}, ok: true},
+		{name: "string types are not coerced", value: "anything", typeName: models.TypeString, ok: false},
+		{
+			name: "uuid is not coerced", value: "550e8400-e29b-41d4-a716-446655440000", typeName: models.TypeUUID,
+			ok: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			coerced, ok := coerceString(tc.value, tc.typeName)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.coerced, coerced)
+		})
+	}
+}
+
+func TestMatchingTypes(t *testing.T) {
+	assert.Equal(t, []models.ParamType{models.TypeEmail, models.TypeString}, MatchingTypes("user@example.com"))
+	assert.Equal(t, []models.ParamType{models.TypeString}, MatchingTypes("hello"))
+	assert.Equal(t, []models.ParamType{models.TypeInt, models.TypeNumber}, MatchingTypes(float64(3)))
+	assert.Equal(t, []models.ParamType{models.TypeInt, models.TypeNumber}, MatchingTypes(json.Number("9007199254740993")))
+	assert.Equal(t, []models.ParamType{models.TypeList}, MatchingTypes([]any{1}))
+	assert.Equal(t, []models.ParamType{models.TypeNumber}, MatchingTypes(float64(1.5)))
+	assert.Equal(t, []models.ParamType{models.TypeNull}, MatchingTypes(nil))
+	assert.Equal(t, []models.ParamType{models.TypeIP, models.TypeString}, MatchingTypes("10.0.0.1"))
+	assert.Equal(t, []models.ParamType{models.TypeTimestamp, models.TypeString}, MatchingTypes("2026-03-01T12:30:00Z"))
+
+	// Hostname and Base64 would explain almost any word
+	assert.Equal(t, []models.ParamType{models.TypeString}, MatchingTypes("abcd"))
+}
+
+func TestCustomTypes(t *testing.T) {
+	ctx := context.Background()
+	tStore := store.NewModelStore()
+
+	for _, definition := range []*models.TypeDefinition{
+		{Name: "Mobile", Pattern: `^\+[0-9]{8,15}$`},
+		{Name: "ISO-Country", Enum: []any{"IL", "US"}},
+		{Name: "Contact", AnyOf: []models.ParamType{"Mobile", models.TypeEmail}},
+		{Name: "Slot", AnyOf: []models.ParamType{models.TypeInt, tEvenType}, Enum: []any{2, 4, 7}},
+	} {
+		_, err := tStore.DefineType(ctx, definition)
+		require.NoError(t, err)
+	}
+
+	tValidator, err := NewConfiguredRequestValidator(&config.InitConfig{}, tStore)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		typeName models.ParamType
+		value    any
+		isValid  bool
+	}{
+		{name: "pattern match", typeName: "Mobile", value: "+972501234567", isValid: true},
+		{name: "pattern mismatch", typeName: "Mobile", value: "0501234567", isValid: false},
+		{name: "pattern on a non string", typeName: "Mobile", value: float64(972501234567), isValid: false},
+		{name: "enum match", typeName: "ISO-Country", value: "IL", isValid: true},
+		{name: "enum mismatch", typeName: "ISO-Country", value: "FR", isValid: false},
+		{name: "composition of a defined type", typeName: "Contact", value: "+972501234567", isValid: true},
+		{name: "composition of a built-in type", typeName: "Contact", value: "user@example.com", isValid: true},
+		{name: "composition mismatch", typeName: "Contact", value: "user", isValid: false},
+		{name: "Go type", typeName: tEvenType, value: float64(4), isValid: true},
+		{name: "Go type mismatch", typeName: tEvenType, value: float64(3), isValid: false},
+		{name: "every rule must hold", typeName: "Slot", value: float64(7), isValid: true},
+		{name: "enum rule fails", typeName: "Slot", value: float64(6), isValid: false},
+		{name: "unknown type", typeName: "IPv6", value: "::1", isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tModel := &models.APIModel{
+				Path:   tTestPath,
+				Method: http.MethodPost,
+				Body:   []*models.Parameter{{Name: "value", Types: []models.ParamType{tc.typeName}}},
+			}
+			tRequest := &models.Request{
+				Path:   tTestPath,
+				Method: http.MethodPost,
+				Body:   []*models.RequestParam{{Name: "value", Value: tc.value}},
+			}
+
+			result := tValidator.Validate(ctx, tRequest, tModel)
+			assert.Equal(t, tc.isValid, result.Valid, result.Anomalies)
+		})
+	}
+
+	t.Run("path segments are matched as strings", func(t *testing.T) {
+		tModel := &models.APIModel{
+			Path:       "/countries/{code}",
+			Method:     http.MethodGet,
+			PathParams: []*models.Parameter{{Name: "code", Types: []models.ParamType{"ISO-Country"}, Required: true}},
+		}
+
+		result := tValidator.Validate(ctx, &models.Request{Path: "/countries/US", Method: http.MethodGet}, tModel)
+		assert.True(t, result.Valid)
+
+		result = tValidator.Validate(ctx, &models.Request{Path: "/countries/FR", Method: http.MethodGet}, tModel)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("without a type provider only global types are known", func(t *testing.T) {
+		tModel := &models.APIModel{
+			Path:   tTestPath,
+			Method: http.MethodPost,
+			Body:   []*models.Parameter{{Name: "value", Types: []models.ParamType{"ISO-Country", tEvenType}}},
+		}
+
+		validator := NewRequestValidator()
+
+		result := validator.Validate(ctx, &models.Request{
+			Path: tTestPath, Method: http.MethodPost, Body: []*models.RequestParam{{Name: "value", Value: "IL"}},
+		}, tModel)
+		assert.False(t, result.Valid)
+
+		result = validator.Validate(ctx, &models.Request{
+			Path: tTestPath, Method: http.MethodPost, Body: []*models.RequestParam{{Name: "value", Value: float64(2)}},
+		}, tModel)
+		assert.True(t, result.Valid)
+	})
+}
@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// standardHeaders are sent by browsers, HTTP clients and proxies on almost every request.
+// They are never reported as unexpected, otherwise every model would have to declare them.
+var standardHeaders = map[string]struct{}{
+	"accept":                    {},
+	"accept-charset":            {},
+	"accept-encoding":           {},
+	"accept-language":           {},
+	"cache-control":             {},
+	"connection":                {},
+	"content-encoding":          {},
+	"content-length":            {},
+	"content-type":              {},
+	"cookie":                    {},
+	"dnt":                       {},
+	"forwarded":                 {},
+	"host":                      {},
+	"if-match":                  {},
+	"if-modified-since":         {},
+	"if-none-match":             {},
+	"keep-alive":                {},
+	"origin":                    {},
+	"pragma":                    {},
+	"referer":                   {},
+	"sec-ch-ua":                 {},
+	"sec-ch-ua-mobile":          {},
+	"sec-ch-ua-platform":        {},
+	"sec-fetch-dest":            {},
+	"sec-fetch-mode":            {},
+	"sec-fetch-site":            {},
+	"sec-fetch-user":            {},
+	"te":                        {},
+	"traceparent":               {},
+	"tracestate":                {},
+	"upgrade-insecure-requests": {},
+	"user-agent":                {},
+	"via":                       {},
+	"x-forwarded-for":           {},
+	"x-forwarded-host":          {},
+	"x-forwarded-proto":         {},
+	"x-real-ip":                 {},
+	"x-request-id":              {},
+}
+
+// IsStandardHeader reports whether a header name is on the default allow-list. Header names are case-insensitive.
+func IsStandardHeader(name string) bool {
+	_, exists := standardHeaders[strings.ToLower(name)]
+	return exists
+}
+
+// sortedKeys returns the keys of an object in a stable order
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,17 +1,22 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"anomaly_detector/api"
+	"anomaly_detector/config"
 	"anomaly_detector/models"
 	"anomaly_detector/store"
+	"anomaly_detector/tenant"
 )
 
 type IValidateHandler interface {
 	api.IHandler
+	HandleBatch(w http.ResponseWriter, r *http.Request)
+	HandleCapture(w http.ResponseWriter, r *http.Request)
 }
 
 type validateHandler struct {
@@ -19,11 +24,16 @@ type validateHandler struct {
 	validator IRequestValidator
 }
 
-func NewValidateHandler(store store.IModelStore) IValidateHandler {
+func NewValidateHandler(cfg *config.InitConfig, store store.IModelStore) (IValidateHandler, error) {
+	validator, err := NewConfiguredRequestValidator(cfg, store)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator configuration: %w", err)
+	}
+
 	return &validateHandler{
 		store:     store,
-		validator: NewRequestValidator(),
-	}
+		validator: validator,
+	}, nil
 }
 
 func (h *validateHandler) Handle(w http.ResponseWriter, r *http.Request) {
@@ -35,18 +45,24 @@ func (h *validateHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	model, err := h.store.Get(ctx, req.Path, req.Method)
+	result, err := h.validate(ctx, &req)
 	if err != nil {
-		api.RespondError(w, http.StatusNotFound, fmt.Sprintf("no model found for endpoint %s %s", req.Method, req.Path))
+		api.RespondError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	result := h.validator.Validate(ctx, &req, model)
+	api.RespondJSON(w, http.StatusOK, result)
+}
 
-	validationResult := models.ValidationResult{
-		Valid:     len(result) == 0,
-		Anomalies: result,
+// validate resolves the model of a request in the tenant of ctx and validates the request against it
+func (h *validateHandler) validate(ctx context.Context, req *models.Request) (*models.ValidationResult, error) {
+	model, err := h.store.Match(ctx, req.Path, req.Method)
+	if err != nil {
+		return nil, fmt.Errorf("no model found for endpoint %s %s", req.Method, req.Path)
 	}
 
-	api.RespondJSON(w, http.StatusOK, validationResult)
+	result := h.validator.Validate(ctx, req, model)
+	result.SetTenant(tenant.FromContext(ctx))
+
+	return result, nil
 }
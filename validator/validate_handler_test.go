@@ -10,6 +10,7 @@ import (
 
 	"anomaly_detector/models"
 	"anomaly_detector/store"
+	"anomaly_detector/tenant"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -61,12 +62,12 @@ func TestValidateRequests(t *testing.T) {
 		tRecorder := httptest.NewRecorder()
 
 		tStoreMock.EXPECT().
-			Get(ctx, tUsersInfoPath, http.MethodGet).
+			Match(ctx, tUsersInfoPath, http.MethodGet).
 			Return(tModel, nil).Once()
 
 		tValidatorMock.EXPECT().
 			Validate(ctx, &request, tModel).
-			Return(nil).Once()
+			Return(&models.ValidationResult{Valid: true}).Once()
 
 		tHandler.Handle(tRecorder, httpRequest)
 
@@ -83,6 +84,35 @@ func TestValidateRequests(t *testing.T) {
 		assert.Equal(t, result, expectedValidationResult)
 	})
 
+	t.Run("anomalies carry the tenant of the request", func(t *testing.T) {
+		ctx := tenant.WithTenant(context.Background(), "acme")
+		request := models.Request{Path: tUsersInfoPath, Method: http.MethodGet}
+
+		body, _ := json.Marshal(request)
+		httpRequest := httptest.NewRequest(http.MethodPost, tValidatePath, bytes.NewReader(body)).WithContext(ctx)
+		tRecorder := httptest.NewRecorder()
+
+		tStoreMock.EXPECT().
+			Match(ctx, tUsersInfoPath, http.MethodGet).
+			Return(tModel, nil).Once()
+
+		tValidatorMock.EXPECT().
+			Validate(ctx, &request, tModel).
+			Return(&models.ValidationResult{
+				Anomalies: []*models.FieldAnomaly{{Field: "headers", ParameterName: "Authorization"}},
+				Warnings:  []*models.FieldAnomaly{{Field: "query_params", ParameterName: "debug"}},
+			}).Once()
+
+		tHandler.Handle(tRecorder, httpRequest)
+
+		var result models.ValidationResult
+
+		err := json.NewDecoder(tRecorder.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", result.Anomalies[0].Tenant)
+		assert.Equal(t, "acme", result.Warnings[0].Tenant)
+	})
+
 	t.Run("error when model not found", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -96,7 +126,7 @@ func TestValidateRequests(t *testing.T) {
 		tRecorder := httptest.NewRecorder()
 
 		tStoreMock.EXPECT().
-			Get(ctx, "/nonexistent", http.MethodGet).
+			Match(ctx, "/nonexistent", http.MethodGet).
 			Return(nil, assert.AnError).Once()
 
 		tHandler.Handle(tRecorder, httpRequest)